@@ -58,3 +58,10 @@ Create the name of the service account to use
 {{- default "default" .Values.serviceAccount.name }}
 {{- end }}
 {{- end }}
+
+{{/*
+Namespace of the Gateway, which defaults to the Envoy Gateway install namespace
+*/}}
+{{- define "ingress-gateway-api.gatewayNamespace" -}}
+{{- default "envoy-gateway-system" .Values.gateway.namespace }}
+{{- end }}
@@ -4,16 +4,20 @@ import (
 	"os"
 
 	egv1alpha1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+	gatewayxv1alpha1 "sigs.k8s.io/gateway-api/apisx/v1alpha1"
 
 	"github.com/werdnum/ingress-gateway-api/internal/config"
 	"github.com/werdnum/ingress-gateway-api/internal/controller"
@@ -29,7 +33,9 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(networkingv1.AddToScheme(scheme))
 	utilruntime.Must(gatewayv1.Install(scheme))
+	utilruntime.Must(gatewayv1alpha2.Install(scheme))
 	utilruntime.Must(gatewayv1beta1.Install(scheme))
+	utilruntime.Must(gatewayxv1alpha1.Install(scheme))
 	utilruntime.Must(egv1alpha1.AddToScheme(scheme))
 }
 
@@ -44,12 +50,19 @@ func main() {
 	}
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	if err := cfg.Validate(); err != nil {
+		setupLog.Error(err, "invalid configuration")
+		os.Exit(1)
+	}
+
 	setupLog.Info("Starting ingress-gateway-api controller",
 		"gatewayName", cfg.GatewayName,
 		"gatewayNamespace", cfg.GatewayNamespace,
 		"ingressClass", cfg.IngressClass,
 	)
 
+	ctx := ctrl.SetupSignalHandler()
+
 	// Create manager
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme: scheme,
@@ -59,15 +72,40 @@ func main() {
 		HealthProbeBindAddress: cfg.HealthProbeAddr,
 		LeaderElection:         cfg.LeaderElect,
 		LeaderElectionID:       "ingress-gateway-api.io",
+		// Only the metadata of Secrets and ConfigMaps is watched. Read the few the
+		// controller needs directly rather than caching every one in the cluster.
+		// cert-manager Certificates, the only unstructured objects, are watched and
+		// read from the cache.
+		Client: client.Options{
+			Cache: &client.CacheOptions{
+				DisableFor:   []client.Object{&corev1.Secret{}, &corev1.ConfigMap{}},
+				Unstructured: true,
+			},
+		},
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to create manager")
 		os.Exit(1)
 	}
 
-	// Create converter with service port resolver
-	resolver := converter.NewServicePortResolver(mgr.GetClient())
-	conv := converter.NewWithResolver(cfg, resolver)
+	// Global rate limit annotations need global rate limiting enabled in Envoy Gateway
+	cfg.GlobalRateLimit, err = controller.CheckGlobalRateLimit(ctx, mgr.GetAPIReader(), cfg.EnvoyGatewayConfig)
+	switch {
+	case err != nil && cfg.GlobalRateLimit:
+		setupLog.Error(err, "global rate limits cannot be enforced")
+	case err != nil:
+		setupLog.Error(err, "unable to check for global rate limiting, global-rate-limit annotations are ignored")
+	case !cfg.GlobalRateLimit:
+		setupLog.Info("Global rate limiting is not enabled in Envoy Gateway, global-rate-limit annotations are ignored")
+	}
+
+	// Create converter with service port, Gateway listener, ConfigMap and Ingress resolvers
+	conv := converter.NewWithResolvers(cfg, converter.Resolvers{
+		Ports:      converter.NewServicePortResolver(mgr.GetClient()),
+		Listeners:  converter.NewListenerResolver(mgr.GetClient()),
+		ConfigMaps: converter.NewConfigMapResolver(mgr.GetClient()),
+		Ingresses:  converter.NewIngressResolver(mgr.GetClient()),
+	})
 
 	// Setup controller
 	if err := (&controller.IngressReconciler{
@@ -75,6 +113,7 @@ func main() {
 		Scheme:    mgr.GetScheme(),
 		Config:    cfg,
 		Converter: conv,
+		Recorder:  mgr.GetEventRecorder("ingress-gateway-api"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Ingress")
 		os.Exit(1)
@@ -91,7 +130,7 @@ func main() {
 	}
 
 	setupLog.Info("Starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	if err := mgr.Start(ctx); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
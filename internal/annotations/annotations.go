@@ -16,6 +16,34 @@ const (
 	// Load balancer annotations
 	UpstreamHashBy = Prefix + "upstream-hash-by"
 
+	// Session affinity annotations. Only cookie affinity exists; affinity-mode is balanced,
+	// the default, which moves some sessions when endpoints change, or persistent.
+	Affinity              = Prefix + "affinity"
+	AffinityMode          = Prefix + "affinity-mode"
+	SessionCookieName     = Prefix + "session-cookie-name"
+	SessionCookiePath     = Prefix + "session-cookie-path"
+	SessionCookieExpires  = Prefix + "session-cookie-expires"
+	SessionCookieMaxAge   = Prefix + "session-cookie-max-age"
+	SessionCookieSameSite = Prefix + "session-cookie-samesite"
+	SessionCookieSecure   = Prefix + "session-cookie-secure"
+	SessionCookieDomain   = Prefix + "session-cookie-domain"
+
+	// Rate limit annotations. Limits apply per client IP, except to the limit-whitelist
+	// CIDRs; limit-burst-multiplier sets the burst as a multiple of the rate.
+	LimitRPS             = Prefix + "limit-rps"
+	LimitRPM             = Prefix + "limit-rpm"
+	LimitConnections     = Prefix + "limit-connections"
+	LimitBurstMultiplier = Prefix + "limit-burst-multiplier"
+	LimitWhitelist       = Prefix + "limit-whitelist"
+
+	// Global rate limit annotations, counted by the Envoy Gateway rate limit service for
+	// all proxies together. global-rate-limit-key is an nginx expression, $remote_addr by
+	// default, and requests from global-rate-limit-ignored-cidrs are not counted.
+	GlobalRateLimit             = Prefix + "global-rate-limit"
+	GlobalRateLimitWindow       = Prefix + "global-rate-limit-window"
+	GlobalRateLimitKey          = Prefix + "global-rate-limit-key"
+	GlobalRateLimitIgnoredCIDRs = Prefix + "global-rate-limit-ignored-cidrs"
+
 	// CORS annotations
 	CORSEnabled          = Prefix + "enable-cors"
 	CORSAllowOrigin      = Prefix + "cors-allow-origin"
@@ -34,9 +62,89 @@ const (
 	RewriteTarget = Prefix + "rewrite-target"
 	AppRoot       = Prefix + "app-root"
 
+	// Redirect annotations
+	PermanentRedirect     = Prefix + "permanent-redirect"
+	PermanentRedirectCode = Prefix + "permanent-redirect-code"
+	TemporalRedirect      = Prefix + "temporal-redirect"
+	FromToWWWRedirect     = Prefix + "from-to-www-redirect"
+
 	// Path handling annotations
 	UseRegex = Prefix + "use-regex"
 
+	// Upstream request header annotations. proxy-set-headers names a ConfigMap whose
+	// entries are header names and values.
+	XForwardedPrefix = Prefix + "x-forwarded-prefix"
+	UpstreamVhost    = Prefix + "upstream-vhost"
+	ProxySetHeaders  = Prefix + "proxy-set-headers"
+
+	// Canary annotations. A canary Ingress overlays the paths of the primary Ingress for
+	// the same host; by-header takes precedence over by-cookie, and both over weight.
+	Canary                = Prefix + "canary"
+	CanaryWeight          = Prefix + "canary-weight"
+	CanaryWeightTotal     = Prefix + "canary-weight-total"
+	CanaryByHeader        = Prefix + "canary-by-header"
+	CanaryByHeaderValue   = Prefix + "canary-by-header-value"
+	CanaryByHeaderPattern = Prefix + "canary-by-header-pattern"
+	CanaryByCookie        = Prefix + "canary-by-cookie"
+
+	// Progressive canary annotations, which ingress-nginx does not have. canary-steps
+	// lists weight:duration steps such as "10:5m,50:10m,100", with weights out of
+	// canary-weight-total; the controller moves the canary through them and records the
+	// current step, from 1, in canary-step. canary-pause holds the current step and
+	// canary-abort sends every request back to the primary and restarts the rollout.
+	CanarySteps       = "ingress-gateway-api.io/canary-steps"
+	CanaryPause       = "ingress-gateway-api.io/canary-pause"
+	CanaryAbort       = "ingress-gateway-api.io/canary-abort"
+	CanaryStep        = "ingress-gateway-api.io/canary-step"
+	CanaryStepStarted = "ingress-gateway-api.io/canary-step-started"
+
 	// Backend protocol annotation
 	BackendProtocol = Prefix + "backend-protocol"
+
+	// HTTPS redirect annotations
+	SSLRedirect      = Prefix + "ssl-redirect"
+	ForceSSLRedirect = Prefix + "force-ssl-redirect"
+
+	// TLS passthrough annotation
+	SSLPassthrough = Prefix + "ssl-passthrough"
+
+	// Client certificate authentication annotations
+	AuthTLSSecret                    = Prefix + "auth-tls-secret"
+	AuthTLSVerifyClient              = Prefix + "auth-tls-verify-client"
+	AuthTLSVerifyDepth               = Prefix + "auth-tls-verify-depth"
+	AuthTLSPassCertificateToUpstream = Prefix + "auth-tls-pass-certificate-to-upstream"
+
+	// Listener TLS annotations. ingress-nginx only reads ssl-protocols and ssl-ecdh-curve
+	// from its ConfigMap; they are accepted per Ingress here as well.
+	SSLCiphers   = Prefix + "ssl-ciphers"
+	SSLProtocols = Prefix + "ssl-protocols"
+	SSLECDHCurve = Prefix + "ssl-ecdh-curve"
+
+	// Upstream TLS annotations
+	ProxySSLSecret     = Prefix + "proxy-ssl-secret"
+	ProxySSLVerify     = Prefix + "proxy-ssl-verify"
+	ProxySSLName       = Prefix + "proxy-ssl-name"
+	ProxySSLServerName = Prefix + "proxy-ssl-server-name"
+
+	// ProxySSLCAConfigMap names a ConfigMap in the Ingress namespace whose ca.crt key holds
+	// the CA certificates used to verify backends. ingress-nginx has no equivalent; it is
+	// an alternative to proxy-ssl-secret for CA bundles distributed as ConfigMaps.
+	ProxySSLCAConfigMap = "ingress-gateway-api.io/proxy-ssl-ca-configmap"
+)
+
+// cert-manager annotation keys, as read by cert-manager's ingress-shim.
+const (
+	// CertManagerPrefix is the common prefix for cert-manager annotations.
+	CertManagerPrefix = "cert-manager.io/"
+
+	// Issuer annotations
+	CertManagerIssuer        = CertManagerPrefix + "issuer"
+	CertManagerClusterIssuer = CertManagerPrefix + "cluster-issuer"
+	CertManagerIssuerKind    = CertManagerPrefix + "issuer-kind"
+	CertManagerIssuerGroup   = CertManagerPrefix + "issuer-group"
+
+	// Certificate annotations
+	CertManagerCommonName  = CertManagerPrefix + "common-name"
+	CertManagerDuration    = CertManagerPrefix + "duration"
+	CertManagerRenewBefore = CertManagerPrefix + "renew-before"
 )
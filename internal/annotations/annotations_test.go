@@ -2,6 +2,7 @@ package annotations
 
 import (
 	"testing"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/resource"
 )
@@ -394,6 +395,16 @@ func TestHasHTTPRouteFilters(t *testing.T) {
 			annots: map[string]string{AppRoot: "/app"},
 			want:   true,
 		},
+		{
+			name:   "has permanent-redirect",
+			annots: map[string]string{PermanentRedirect: "https://example.com"},
+			want:   true,
+		},
+		{
+			name:   "has temporal-redirect",
+			annots: map[string]string{TemporalRedirect: "https://example.com"},
+			want:   true,
+		},
 		{
 			name:   "no filters",
 			annots: map[string]string{},
@@ -410,3 +421,323 @@ func TestHasHTTPRouteFilters(t *testing.T) {
 		})
 	}
 }
+
+func TestHasRequestHeaders(t *testing.T) {
+	tests := []struct {
+		name   string
+		annots map[string]string
+		want   bool
+	}{
+		{
+			name:   "has x-forwarded-prefix",
+			annots: map[string]string{XForwardedPrefix: "/app"},
+			want:   true,
+		},
+		{
+			name:   "has upstream-vhost",
+			annots: map[string]string{UpstreamVhost: "internal.example.com"},
+			want:   true,
+		},
+		{
+			name:   "has proxy-set-headers",
+			annots: map[string]string{ProxySetHeaders: "custom-headers"},
+			want:   true,
+		},
+		{
+			name:   "no header annotations",
+			annots: map[string]string{RewriteTarget: "/"},
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			as := NewAnnotationSet(tt.annots)
+			if got := as.HasRequestHeaders(); got != tt.want {
+				t.Errorf("HasRequestHeaders() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasCookieAffinity(t *testing.T) {
+	tests := []struct {
+		name           string
+		annots         map[string]string
+		wantAffinity   bool
+		wantPersistent bool
+	}{
+		{
+			name:         "cookie affinity",
+			annots:       map[string]string{Affinity: "cookie"},
+			wantAffinity: true,
+		},
+		{
+			name:           "persistent cookie affinity",
+			annots:         map[string]string{Affinity: "cookie", AffinityMode: "persistent"},
+			wantAffinity:   true,
+			wantPersistent: true,
+		},
+		{
+			name:         "balanced cookie affinity",
+			annots:       map[string]string{Affinity: "cookie", AffinityMode: "balanced"},
+			wantAffinity: true,
+		},
+		{
+			name:   "other affinity",
+			annots: map[string]string{Affinity: "ip"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			as := NewAnnotationSet(tt.annots)
+			if got := as.HasCookieAffinity(); got != tt.wantAffinity {
+				t.Errorf("HasCookieAffinity() = %v, want %v", got, tt.wantAffinity)
+			}
+			if got := as.IsPersistentAffinity(); got != tt.wantPersistent {
+				t.Errorf("IsPersistentAffinity() = %v, want %v", got, tt.wantPersistent)
+			}
+		})
+	}
+}
+
+func TestHasRateLimit(t *testing.T) {
+	tests := []struct {
+		name           string
+		annots         map[string]string
+		wantRate       bool
+		wantGlobal     bool
+		wantConnection bool
+	}{
+		{
+			name:     "has limit-rps",
+			annots:   map[string]string{LimitRPS: "10"},
+			wantRate: true,
+		},
+		{
+			name:     "has limit-rpm",
+			annots:   map[string]string{LimitRPM: "600"},
+			wantRate: true,
+		},
+		{
+			name:       "has global-rate-limit",
+			annots:     map[string]string{GlobalRateLimit: "100", GlobalRateLimitWindow: "1m"},
+			wantGlobal: true,
+		},
+		{
+			name:           "has limit-connections",
+			annots:         map[string]string{LimitConnections: "5"},
+			wantConnection: true,
+		},
+		{
+			name:   "whitelist alone",
+			annots: map[string]string{LimitWhitelist: "10.0.0.0/8"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			as := NewAnnotationSet(tt.annots)
+			if got := as.HasRateLimit(); got != tt.wantRate {
+				t.Errorf("HasRateLimit() = %v, want %v", got, tt.wantRate)
+			}
+			if got := as.HasGlobalRateLimit(); got != tt.wantGlobal {
+				t.Errorf("HasGlobalRateLimit() = %v, want %v", got, tt.wantGlobal)
+			}
+			if got := as.HasConnectionLimit(); got != tt.wantConnection {
+				t.Errorf("HasConnectionLimit() = %v, want %v", got, tt.wantConnection)
+			}
+			want := tt.wantRate || tt.wantGlobal || tt.wantConnection
+			if got := as.HasBackendTrafficPolicyAnnotations(); got != want {
+				t.Errorf("HasBackendTrafficPolicyAnnotations() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestRedirectsToHTTPS(t *testing.T) {
+	tests := []struct {
+		name   string
+		annots map[string]string
+		hasTLS bool
+		want   bool
+	}{
+		{
+			name:   "tls redirects by default",
+			annots: map[string]string{},
+			hasTLS: true,
+			want:   true,
+		},
+		{
+			name:   "no tls does not redirect by default",
+			annots: map[string]string{},
+			hasTLS: false,
+			want:   false,
+		},
+		{
+			name:   "ssl-redirect false opts out",
+			annots: map[string]string{SSLRedirect: "false"},
+			hasTLS: true,
+			want:   false,
+		},
+		{
+			name:   "ssl-redirect true without tls",
+			annots: map[string]string{SSLRedirect: "true"},
+			hasTLS: false,
+			want:   false,
+		},
+		{
+			name:   "force-ssl-redirect without tls",
+			annots: map[string]string{ForceSSLRedirect: "true"},
+			hasTLS: false,
+			want:   true,
+		},
+		{
+			name:   "force-ssl-redirect overrides ssl-redirect false",
+			annots: map[string]string{SSLRedirect: "false", ForceSSLRedirect: "true"},
+			hasTLS: true,
+			want:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			as := NewAnnotationSet(tt.annots)
+			if got := as.RedirectsToHTTPS(tt.hasTLS); got != tt.want {
+				t.Errorf("RedirectsToHTTPS(%v) = %v, want %v", tt.hasTLS, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasAuthTLS(t *testing.T) {
+	tests := []struct {
+		name   string
+		annots map[string]string
+		want   bool
+	}{
+		{
+			name:   "has secret",
+			annots: map[string]string{AuthTLSSecret: "default/ca"},
+			want:   true,
+		},
+		{
+			name:   "verification off",
+			annots: map[string]string{AuthTLSSecret: "default/ca", AuthTLSVerifyClient: "off"},
+			want:   false,
+		},
+		{
+			name:   "verify client without secret",
+			annots: map[string]string{AuthTLSVerifyClient: "on"},
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			as := NewAnnotationSet(tt.annots)
+			if got := as.HasAuthTLS(); got != tt.want {
+				t.Errorf("HasAuthTLS() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifiesUpstreamTLS(t *testing.T) {
+	tests := []struct {
+		name   string
+		annots map[string]string
+		want   bool
+	}{
+		{
+			name:   "no annotation",
+			annots: map[string]string{},
+			want:   true,
+		},
+		{
+			name:   "verification on",
+			annots: map[string]string{ProxySSLVerify: "on"},
+			want:   true,
+		},
+		{
+			name:   "verification off",
+			annots: map[string]string{ProxySSLVerify: "off"},
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			as := NewAnnotationSet(tt.annots)
+			if got := as.VerifiesUpstreamTLS(); got != tt.want {
+				t.Errorf("VerifiesUpstreamTLS() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetCanarySteps(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		wantSteps []CanaryRolloutStep
+		wantErr   bool
+	}{
+		{
+			name:  "weights and durations",
+			value: "10:5m, 50:1h30m, 100",
+			wantSteps: []CanaryRolloutStep{
+				{Weight: 10, Duration: 5 * time.Minute},
+				{Weight: 50, Duration: 90 * time.Minute},
+				{Weight: 100},
+			},
+		},
+		{
+			name:      "single step",
+			value:     "20",
+			wantSteps: []CanaryRolloutStep{{Weight: 20}},
+		},
+		{
+			name:    "missing duration",
+			value:   "10,100",
+			wantErr: true,
+		},
+		{
+			name:    "duration on the last step",
+			value:   "10:5m,100:5m",
+			wantErr: true,
+		},
+		{
+			name:    "invalid weight",
+			value:   "ten:5m,100",
+			wantErr: true,
+		},
+		{
+			name:    "invalid duration",
+			value:   "10:soon,100",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			as := NewAnnotationSet(map[string]string{CanarySteps: tt.value})
+			got, ok, err := as.GetCanarySteps()
+			if !ok {
+				t.Fatal("GetCanarySteps() ok = false, want true")
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetCanarySteps() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.wantSteps) {
+				t.Fatalf("GetCanarySteps() = %v, want %v", got, tt.wantSteps)
+			}
+			for i := range got {
+				if got[i] != tt.wantSteps[i] {
+					t.Errorf("GetCanarySteps()[%d] = %v, want %v", i, got[i], tt.wantSteps[i])
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package annotations
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
@@ -170,6 +171,21 @@ func (a AnnotationSet) GetBool(key string) (bool, bool) {
 	return b, true
 }
 
+// GetInt parses an annotation value as an integer.
+func (a AnnotationSet) GetInt(key string) (int, bool) {
+	val, ok := a[key]
+	if !ok {
+		return 0, false
+	}
+
+	i, err := strconv.Atoi(strings.TrimSpace(val))
+	if err != nil {
+		return 0, false
+	}
+
+	return i, true
+}
+
 // GetStringSlice parses an annotation value as a comma-separated list.
 func (a AnnotationSet) GetStringSlice(key string) ([]string, bool) {
 	val, ok := a[key]
@@ -213,6 +229,39 @@ func (a AnnotationSet) HasLoadBalancer() bool {
 	return ok
 }
 
+// HasCookieAffinity returns true if requests with the session cookie stick to an endpoint.
+func (a AnnotationSet) HasCookieAffinity() bool {
+	return a[Affinity] == "cookie"
+}
+
+// IsPersistentAffinity returns true if affinity-mode keeps sessions on their endpoint
+// when endpoints change.
+func (a AnnotationSet) IsPersistentAffinity() bool {
+	return a[AffinityMode] == "persistent"
+}
+
+// HasRateLimit returns true if a request rate limit annotation is present.
+func (a AnnotationSet) HasRateLimit() bool {
+	return a.has(LimitRPS) || a.has(LimitRPM)
+}
+
+// HasGlobalRateLimit returns true if the global-rate-limit annotation is present.
+func (a AnnotationSet) HasGlobalRateLimit() bool {
+	return a.has(GlobalRateLimit)
+}
+
+// HasGlobalRateLimitIgnoredCIDRs returns true if requests from some CIDRs are not counted
+// by the global rate limit.
+func (a AnnotationSet) HasGlobalRateLimitIgnoredCIDRs() bool {
+	cidrs, ok := a.GetStringSlice(GlobalRateLimitIgnoredCIDRs)
+	return ok && len(cidrs) > 0
+}
+
+// HasConnectionLimit returns true if the limit-connections annotation is present.
+func (a AnnotationSet) HasConnectionLimit() bool {
+	return a.has(LimitConnections)
+}
+
 // HasCORS returns true if any CORS annotation is present.
 func (a AnnotationSet) HasCORS() bool {
 	// Check for explicit enable
@@ -257,9 +306,146 @@ func (a AnnotationSet) HasAppRoot() bool {
 	return ok
 }
 
+// UsesRegex returns true if the paths of the Ingress hosts are regular expressions.
+// ingress-nginx uses regex locations for use-regex and for any rewrite-target.
+func (a AnnotationSet) UsesRegex() bool {
+	if useRegex, ok := a.GetBool(UseRegex); ok && useRegex {
+		return true
+	}
+	return a.HasRewrite()
+}
+
+// HasRedirect returns true if permanent-redirect or temporal-redirect is set.
+func (a AnnotationSet) HasRedirect() bool {
+	return a.has(PermanentRedirect) || a.has(TemporalRedirect)
+}
+
+// HasFromToWWWRedirect returns true if the www counterpart of each host should redirect to it.
+func (a AnnotationSet) HasFromToWWWRedirect() bool {
+	redirect, ok := a.GetBool(FromToWWWRedirect)
+	return ok && redirect
+}
+
+// HasRequestHeaders returns true if any annotation modifies the upstream request headers.
+func (a AnnotationSet) HasRequestHeaders() bool {
+	return a.has(XForwardedPrefix) || a.has(UpstreamVhost) || a.has(ProxySetHeaders)
+}
+
+// IsCanary returns true if the Ingress is a canary of the primary Ingress for its paths.
+func (a AnnotationSet) IsCanary() bool {
+	canary, ok := a.GetBool(Canary)
+	return ok && canary
+}
+
+// CanaryRolloutStep is a step of a progressive canary rollout: the canary weight and how
+// long it is kept before the next step. The last step has no duration.
+type CanaryRolloutStep struct {
+	Weight   int32
+	Duration time.Duration
+}
+
+// GetCanarySteps parses the canary-steps annotation, a comma-separated list of
+// weight:duration steps ending with a weight alone.
+func (a AnnotationSet) GetCanarySteps() ([]CanaryRolloutStep, bool, error) {
+	val, ok := a[CanarySteps]
+	if !ok {
+		return nil, false, nil
+	}
+
+	parts := strings.Split(val, ",")
+	steps := make([]CanaryRolloutStep, 0, len(parts))
+	for i, part := range parts {
+		weight, duration, hasDuration := strings.Cut(strings.TrimSpace(part), ":")
+		parsed, err := strconv.ParseInt(strings.TrimSpace(weight), 10, 32)
+		if err != nil || parsed < 0 {
+			return nil, true, fmt.Errorf("step %d: invalid weight %q", i+1, weight)
+		}
+		step := CanaryRolloutStep{Weight: int32(parsed)}
+		last := i == len(parts)-1
+		switch {
+		case hasDuration && !last:
+			step.Duration, err = time.ParseDuration(strings.TrimSpace(duration))
+			if err != nil || step.Duration <= 0 {
+				return nil, true, fmt.Errorf("step %d: invalid duration %q", i+1, duration)
+			}
+		case hasDuration:
+			return nil, true, fmt.Errorf("step %d: the last step cannot have a duration", i+1)
+		case !last:
+			return nil, true, fmt.Errorf("step %d: a duration is required before the last step", i+1)
+		}
+		steps = append(steps, step)
+	}
+
+	return steps, true, nil
+}
+
+// GetCanaryStep returns the current rollout step the controller recorded, from 1.
+func (a AnnotationSet) GetCanaryStep() (int, bool) {
+	val, ok := a[CanaryStep]
+	if !ok {
+		return 0, false
+	}
+	step, err := strconv.Atoi(val)
+	if err != nil || step < 1 {
+		return 0, false
+	}
+	return step, true
+}
+
+// IsCanaryPaused returns true if canary-pause holds the rollout at its current step.
+func (a AnnotationSet) IsCanaryPaused() bool {
+	paused, ok := a.GetBool(CanaryPause)
+	return ok && paused
+}
+
+// IsCanaryAborted returns true if canary-abort sends every request to the primary.
+func (a AnnotationSet) IsCanaryAborted() bool {
+	aborted, ok := a.GetBool(CanaryAbort)
+	return ok && aborted
+}
+
+// HasAuthTLS returns true if client certificate authentication is configured.
+// Like ingress-nginx, auth-tls-secret is required and verification can be turned off.
+func (a AnnotationSet) HasAuthTLS() bool {
+	if _, ok := a[AuthTLSSecret]; !ok {
+		return false
+	}
+	return a[AuthTLSVerifyClient] != "off"
+}
+
+// HasListenerTLSAnnotations returns true if any listener TLS annotation is present.
+func (a AnnotationSet) HasListenerTLSAnnotations() bool {
+	return a.has(SSLCiphers) || a.has(SSLProtocols) || a.has(SSLECDHCurve)
+}
+
+// HasUpstreamTLSAnnotations returns true if any proxy-ssl-* annotation is present.
+func (a AnnotationSet) HasUpstreamTLSAnnotations() bool {
+	return a.has(ProxySSLSecret) || a.has(ProxySSLVerify) || a.has(ProxySSLName) ||
+		a.has(ProxySSLServerName) || a.has(ProxySSLCAConfigMap)
+}
+
+// VerifiesUpstreamTLS returns true if backend certificates should be verified.
+// Unlike ingress-nginx, where proxy-ssl-verify defaults to off, verification is
+// only skipped when the annotation is explicitly "off".
+func (a AnnotationSet) VerifiesUpstreamTLS() bool {
+	return a[ProxySSLVerify] != "off"
+}
+
+// HasSSLPassthrough returns true if TLS connections should be passed through to the backend.
+func (a AnnotationSet) HasSSLPassthrough() bool {
+	passthrough, ok := a.GetBool(SSLPassthrough)
+	return ok && passthrough
+}
+
+// HasCertManagerIssuer returns true if cert-manager should issue the TLS certificates.
+func (a AnnotationSet) HasCertManagerIssuer() bool {
+	return a.has(CertManagerIssuer) || a.has(CertManagerClusterIssuer)
+}
+
 // HasBackendTrafficPolicyAnnotations returns true if any BackendTrafficPolicy annotation is present.
 func (a AnnotationSet) HasBackendTrafficPolicyAnnotations() bool {
-	return a.HasTimeout() || a.HasLoadBalancer() || a.has(ProxyBodySize)
+	return a.HasTimeout() || a.HasLoadBalancer() || a.has(ProxyBodySize) ||
+		a.HasRateLimit() || a.HasGlobalRateLimit() || a.HasConnectionLimit()
 }
 
 // HasClientTrafficPolicyAnnotations returns true if any ClientTrafficPolicy annotation is present.
@@ -274,7 +460,7 @@ func (a AnnotationSet) HasSecurityPolicyAnnotations() bool {
 
 // HasHTTPRouteFilters returns true if any HTTPRoute filter annotation is present.
 func (a AnnotationSet) HasHTTPRouteFilters() bool {
-	return a.HasRewrite() || a.HasAppRoot()
+	return a.HasRewrite() || a.HasAppRoot() || a.HasRedirect()
 }
 
 // HasBackendTLSPolicy returns true if backend-protocol annotation is set to HTTPS.
@@ -285,6 +471,22 @@ func (a AnnotationSet) HasBackendTLSPolicy() bool {
 	return false
 }
 
+// RedirectsToHTTPS returns true if plain HTTP requests should be redirected to HTTPS.
+// Like ingress-nginx, hosts with TLS redirect unless ssl-redirect is "false", and
+// force-ssl-redirect redirects even without TLS.
+func (a AnnotationSet) RedirectsToHTTPS(hasTLS bool) bool {
+	if force, ok := a.GetBool(ForceSSLRedirect); ok && force {
+		return true
+	}
+	if !hasTLS {
+		return false
+	}
+	if redirect, ok := a.GetBool(SSLRedirect); ok {
+		return redirect
+	}
+	return true
+}
+
 func (a AnnotationSet) has(key string) bool {
 	_, ok := a[key]
 	return ok
@@ -2,9 +2,52 @@ package config
 
 import (
 	"flag"
+	"fmt"
 	"os"
+	"slices"
+	"strings"
 )
 
+// TLS Secret modes control how the shared Gateway gets access to Secrets in Ingress namespaces.
+const (
+	// TLSSecretModeReferenceGrant creates ReferenceGrants allowing the Gateway to use the Secrets.
+	TLSSecretModeReferenceGrant = "ReferenceGrant"
+
+	// TLSSecretModeCopy copies the Secrets into the Gateway namespace and keeps them in sync.
+	TLSSecretModeCopy = "Copy"
+)
+
+// Listener modes control where the listeners generated for Ingress TLS are placed.
+const (
+	// ListenerModeGateway adds the listeners to the shared Gateway.
+	ListenerModeGateway = "Gateway"
+
+	// ListenerModeListenerSet creates one XListenerSet per namespace attached to the shared
+	// Gateway. The Gateway must allow ListenerSets through spec.allowedListeners.
+	ListenerModeListenerSet = "ListenerSet"
+)
+
+// Session affinity modes control how cookie affinity annotations are converted.
+const (
+	// SessionAffinityAuto follows affinity-mode: HTTPRoute session persistence for
+	// persistent, and a consistent hash on the cookie for balanced.
+	SessionAffinityAuto = "Auto"
+
+	// SessionAffinitySessionPersistence always uses HTTPRoute session persistence, which
+	// keeps sessions on their endpoint. It needs the experimental Gateway API CRDs.
+	SessionAffinitySessionPersistence = "SessionPersistence"
+
+	// SessionAffinityConsistentHash always uses a BackendTrafficPolicy consistent hash on
+	// the cookie, which moves some sessions when endpoints change.
+	SessionAffinityConsistentHash = "ConsistentHash"
+)
+
+// DefaultClusterDomain is the DNS domain of Services in most clusters.
+const DefaultClusterDomain = "cluster.local"
+
+// DefaultEnvoyGatewayConfig is the configuration ConfigMap of a default Envoy Gateway install.
+const DefaultEnvoyGatewayConfig = "envoy-gateway-system/envoy-gateway-config"
+
 // Config holds the controller configuration.
 type Config struct {
 	// GatewayName is the name of the shared Gateway resource from Envoy Gateway.
@@ -16,6 +59,42 @@ type Config struct {
 	// IngressClass filters which Ingresses to process. Empty means all.
 	IngressClass string
 
+	// DefaultTLSSecret is the namespace/name of the Secret used for Ingress TLS entries
+	// that do not specify a secretName. Empty means those hosts are left to the
+	// Gateway's existing HTTPS listeners.
+	DefaultTLSSecret string
+
+	// TLSSecretMode is how Secrets in Ingress namespaces are made available to the
+	// Gateway: TLSSecretModeReferenceGrant (default) or TLSSecretModeCopy.
+	TLSSecretMode string
+
+	// ListenerMode is where generated listeners are placed: ListenerModeGateway (default)
+	// or ListenerModeListenerSet.
+	ListenerMode string
+
+	// ClusterDomain is the cluster DNS domain used to build Service FQDNs, e.g. as the
+	// hostname backend certificates are validated against.
+	ClusterDomain string
+
+	// SSLProtocols, SSLCiphers and SSLECDHCurve are the default TLS settings of generated
+	// HTTPS listeners, in the ingress-nginx ConfigMap format. Ingress annotations override
+	// them. Empty means Envoy's defaults.
+	SSLProtocols string
+	SSLCiphers   string
+	SSLECDHCurve string
+
+	// SessionAffinity is how cookie affinity is converted: SessionAffinityAuto (default),
+	// SessionAffinitySessionPersistence or SessionAffinityConsistentHash.
+	SessionAffinity string
+
+	// EnvoyGatewayConfig is the namespace/name of the ConfigMap holding the Envoy Gateway
+	// configuration, read at startup to find out whether global rate limiting is enabled.
+	EnvoyGatewayConfig string
+
+	// GlobalRateLimit is whether Envoy Gateway has global rate limiting enabled. It is set
+	// at startup from EnvoyGatewayConfig; global-rate-limit annotations are ignored without it.
+	GlobalRateLimit bool
+
 	// MetricsAddr is the address the metrics endpoint binds to.
 	MetricsAddr string
 
@@ -36,6 +115,25 @@ func NewConfig() *Config {
 		"Namespace of the shared Gateway resource")
 	flag.StringVar(&cfg.IngressClass, "ingress-class", getEnvOrDefault("INGRESS_CLASS", ""),
 		"Filter Ingresses by class (empty = process all)")
+	flag.StringVar(&cfg.DefaultTLSSecret, "default-tls-secret", getEnvOrDefault("DEFAULT_TLS_SECRET", ""),
+		"namespace/name of the Secret used for Ingress TLS entries without a secretName")
+	flag.StringVar(&cfg.TLSSecretMode, "tls-secret-mode", getEnvOrDefault("TLS_SECRET_MODE", TLSSecretModeReferenceGrant),
+		"How the Gateway accesses Secrets in Ingress namespaces: ReferenceGrant or Copy")
+	flag.StringVar(&cfg.ListenerMode, "listener-mode", getEnvOrDefault("LISTENER_MODE", ListenerModeGateway),
+		"Where generated listeners are placed: Gateway or ListenerSet")
+	flag.StringVar(&cfg.ClusterDomain, "cluster-domain", getEnvOrDefault("CLUSTER_DOMAIN", DefaultClusterDomain),
+		"Cluster DNS domain used to build Service FQDNs")
+	flag.StringVar(&cfg.SSLProtocols, "ssl-protocols", getEnvOrDefault("SSL_PROTOCOLS", ""),
+		"Default TLS protocols of generated HTTPS listeners, e.g. \"TLSv1.2 TLSv1.3\"")
+	flag.StringVar(&cfg.SSLCiphers, "ssl-ciphers", getEnvOrDefault("SSL_CIPHERS", ""),
+		"Default colon-separated cipher suites of generated HTTPS listeners")
+	flag.StringVar(&cfg.SSLECDHCurve, "ssl-ecdh-curve", getEnvOrDefault("SSL_ECDH_CURVE", ""),
+		"Default colon-separated ECDH curves of generated HTTPS listeners")
+	flag.StringVar(&cfg.SessionAffinity, "session-affinity", getEnvOrDefault("SESSION_AFFINITY", SessionAffinityAuto),
+		"How cookie affinity is converted: Auto, SessionPersistence or ConsistentHash")
+	flag.StringVar(&cfg.EnvoyGatewayConfig, "envoy-gateway-config",
+		getEnvOrDefault("ENVOY_GATEWAY_CONFIG", DefaultEnvoyGatewayConfig),
+		"namespace/name of the Envoy Gateway configuration ConfigMap")
 	flag.StringVar(&cfg.MetricsAddr, "metrics-addr", ":8080",
 		"The address the metrics endpoint binds to")
 	flag.StringVar(&cfg.HealthProbeAddr, "health-probe-addr", ":8081",
@@ -51,6 +149,25 @@ func (c *Config) Parse() {
 	flag.Parse()
 }
 
+// Validate returns an error if a flag has a value that is not one of its modes.
+func (c *Config) Validate() error {
+	for _, option := range []struct {
+		name  string
+		value string
+		modes []string
+	}{
+		{"tls-secret-mode", c.TLSSecretMode, []string{TLSSecretModeReferenceGrant, TLSSecretModeCopy}},
+		{"listener-mode", c.ListenerMode, []string{ListenerModeGateway, ListenerModeListenerSet}},
+		{"session-affinity", c.SessionAffinity, []string{
+			SessionAffinityAuto, SessionAffinitySessionPersistence, SessionAffinityConsistentHash}},
+	} {
+		if !slices.Contains(option.modes, option.value) {
+			return fmt.Errorf("invalid --%s %q, expected one of %s", option.name, option.value, strings.Join(option.modes, ", "))
+		}
+	}
+	return nil
+}
+
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
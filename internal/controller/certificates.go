@@ -0,0 +1,217 @@
+package controller
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/werdnum/ingress-gateway-api/internal/annotations"
+)
+
+// CertificateProblemsAnnotation lists the problems found with the TLS Secrets of an
+// Ingress, one per line. It is removed once all certificates are usable.
+const CertificateProblemsAnnotation = "ingress-gateway-api.io/certificate-problems"
+
+// ReasonCertificatesValid is the reason of the Event recorded when the certificate
+// problems of an Ingress are resolved.
+const ReasonCertificatesValid = "TLSCertificatesValid"
+
+// Event reasons for TLS Secret problems.
+const (
+	ReasonSecretMissing          = "TLSSecretMissing"
+	ReasonSecretNotFound         = "TLSSecretNotFound"
+	ReasonInvalidSecretType      = "TLSSecretInvalidType"
+	ReasonInvalidCertificate     = "TLSCertificateInvalid"
+	ReasonCertificateExpired     = "TLSCertificateExpired"
+	ReasonCertificateNotYetValid = "TLSCertificateNotYetValid"
+	ReasonHostNotCovered         = "TLSCertificateHostMismatch"
+)
+
+// certificateExpiry exports the expiry time of the certificate serving each TLS host.
+var certificateExpiry = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "ingress_gateway_api_certificate_expiry_timestamp_seconds",
+	Help: "Expiry time of the TLS certificate serving an Ingress host, in seconds since the epoch.",
+}, []string{"namespace", "ingress", "host", "secret"})
+
+func init() {
+	metrics.Registry.MustRegister(certificateExpiry)
+}
+
+// certificateProblem is a reason the Gateway cannot use a TLS Secret of an Ingress.
+type certificateProblem struct {
+	reason  string
+	message string
+}
+
+// checkCertificates validates the Secret behind each TLS listener generated for the
+// Ingress, so that problems show up on the Ingress instead of only as an invalid
+// Gateway listener. TLS hosts without a secretName are reported too when there is no
+// default certificate, as they get no listener at all. It records the certificate expiry
+// of each host and returns the earliest future expiry, or the zero time if there is none.
+func (r *IngressReconciler) checkCertificates(
+	ctx context.Context,
+	ingress *networkingv1.Ingress,
+	listeners []gatewayv1.Listener,
+	now time.Time,
+) ([]certificateProblem, time.Time, error) {
+	certificateExpiry.DeletePartialMatch(prometheus.Labels{"namespace": ingress.Namespace, "ingress": ingress.Name})
+
+	var problems []certificateProblem
+	var nextExpiry time.Time
+	if r.Config.DefaultTLSSecret == "" && !annotations.NewAnnotationSet(ingress.Annotations).HasSSLPassthrough() {
+		for _, tls := range ingress.Spec.TLS {
+			if tls.SecretName != "" {
+				continue
+			}
+			for _, host := range tls.Hosts {
+				problems = append(problems, certificateProblem{ReasonSecretMissing,
+					fmt.Sprintf("TLS for host %s has no secretName and no default certificate is configured", host)})
+			}
+		}
+	}
+	for _, listener := range listeners {
+		if listener.TLS == nil || len(listener.TLS.CertificateRefs) == 0 || listener.Hostname == nil {
+			continue
+		}
+		host := string(*listener.Hostname)
+		ref := listener.TLS.CertificateRefs[0]
+		key := types.NamespacedName{Namespace: ingress.Namespace, Name: string(ref.Name)}
+		if ref.Namespace != nil {
+			key.Namespace = string(*ref.Namespace)
+		}
+
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, key, secret); err != nil {
+			if apierrors.IsNotFound(err) {
+				problems = append(problems, certificateProblem{ReasonSecretNotFound,
+					fmt.Sprintf("TLS Secret %s for host %s not found", key, host)})
+				continue
+			}
+			return nil, time.Time{}, err
+		}
+		if secret.Type != corev1.SecretTypeTLS {
+			problems = append(problems, certificateProblem{ReasonInvalidSecretType,
+				fmt.Sprintf("TLS Secret %s for host %s has type %s, expected %s", key, host, secret.Type, corev1.SecretTypeTLS)})
+			continue
+		}
+
+		cert, err := parseCertificate(secret.Data[corev1.TLSCertKey])
+		if err != nil {
+			problems = append(problems, certificateProblem{ReasonInvalidCertificate,
+				fmt.Sprintf("TLS Secret %s for host %s: %v", key, host, err)})
+			continue
+		}
+		certificateExpiry.WithLabelValues(ingress.Namespace, ingress.Name, host, key.String()).
+			Set(float64(cert.NotAfter.Unix()))
+
+		switch {
+		case now.After(cert.NotAfter):
+			problems = append(problems, certificateProblem{ReasonCertificateExpired,
+				fmt.Sprintf("certificate in TLS Secret %s for host %s expired at %s",
+					key, host, cert.NotAfter.UTC().Format(time.RFC3339))})
+		case now.Before(cert.NotBefore):
+			problems = append(problems, certificateProblem{ReasonCertificateNotYetValid,
+				fmt.Sprintf("certificate in TLS Secret %s for host %s is not valid before %s",
+					key, host, cert.NotBefore.UTC().Format(time.RFC3339))})
+		case nextExpiry.IsZero() || cert.NotAfter.Before(nextExpiry):
+			nextExpiry = cert.NotAfter
+		}
+
+		if !certificateCoversHost(cert, host) {
+			problems = append(problems, certificateProblem{ReasonHostNotCovered,
+				fmt.Sprintf("certificate in TLS Secret %s does not cover host %s", key, host)})
+		}
+	}
+
+	return problems, nextExpiry, nil
+}
+
+// reportCertificateProblems records the current certificate problems in the
+// CertificateProblemsAnnotation of the Ingress. Warning events are only emitted for
+// problems that are new, and a Normal event once all problems are resolved.
+func (r *IngressReconciler) reportCertificateProblems(
+	ctx context.Context,
+	ingress *networkingv1.Ingress,
+	problems []certificateProblem,
+) error {
+	logger := log.FromContext(ctx)
+
+	messages := make([]string, 0, len(problems))
+	current, annotated := ingress.Annotations[CertificateProblemsAnnotation]
+	reported := strings.Split(current, "\n")
+	for _, problem := range problems {
+		messages = append(messages, problem.message)
+		if annotated && slices.Contains(reported, problem.message) {
+			continue
+		}
+		logger.Info("TLS certificate problem", "reason", problem.reason, "message", problem.message)
+		if r.Recorder != nil {
+			r.Recorder.Eventf(ingress, nil, corev1.EventTypeWarning, problem.reason, "ValidateTLSSecret", "%s", problem.message)
+		}
+	}
+
+	value := strings.Join(messages, "\n")
+	if current == value && annotated == (len(messages) > 0) {
+		return nil
+	}
+
+	patch := client.MergeFrom(ingress.DeepCopy())
+	if len(messages) == 0 {
+		delete(ingress.Annotations, CertificateProblemsAnnotation)
+		logger.Info("TLS certificate problems resolved")
+		if r.Recorder != nil {
+			r.Recorder.Eventf(ingress, nil, corev1.EventTypeNormal, ReasonCertificatesValid, "ValidateTLSSecret",
+				"all TLS Secrets are usable")
+		}
+	} else {
+		if ingress.Annotations == nil {
+			ingress.Annotations = make(map[string]string)
+		}
+		ingress.Annotations[CertificateProblemsAnnotation] = value
+	}
+	return r.Patch(ctx, ingress, patch)
+}
+
+// parseCertificate returns the leaf certificate of a PEM certificate chain.
+func parseCertificate(data []byte) (*x509.Certificate, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("%s contains no PEM certificate", corev1.TLSCertKey)
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid certificate in %s: %w", corev1.TLSCertKey, err)
+		}
+		return cert, nil
+	}
+}
+
+// certificateCoversHost reports whether a certificate is valid for a listener hostname.
+// A wildcard hostname is only covered by the same wildcard name in the certificate.
+func certificateCoversHost(cert *x509.Certificate, host string) bool {
+	if strings.HasPrefix(host, "*.") {
+		return slices.ContainsFunc(cert.DNSNames, func(name string) bool {
+			return strings.EqualFold(name, host)
+		})
+	}
+	return cert.VerifyHostname(host) == nil
+}
@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/werdnum/ingress-gateway-api/internal/converter"
+)
+
+// reconcileCertificates creates and updates the cert-manager Certificates of an Ingress
+// and deletes the ones it no longer needs. Nothing is done when cert-manager is not
+// installed. Certificates the controller did not create, e.g. by ingress-shim while
+// cert-manager still watches the Ingress, are left alone. Certificates are read from
+// the cache of the owned Certificates.
+func (r *IngressReconciler) reconcileCertificates(
+	ctx context.Context,
+	ingress *networkingv1.Ingress,
+	certificates []*unstructured.Unstructured,
+) error {
+	logger := log.FromContext(ctx)
+	sourceRef := fmt.Sprintf("%s/%s", ingress.Namespace, ingress.Name)
+
+	if r.noCertificates {
+		if len(certificates) > 0 {
+			logger.Info("cert-manager is not installed, not creating Certificates")
+		}
+		return nil
+	}
+
+	expected := make(map[string]struct{}, len(certificates))
+	for _, certificate := range certificates {
+		expected[certificate.GetName()] = struct{}{}
+		if err := r.reconcileCertificate(ctx, ingress, certificate); err != nil {
+			if meta.IsNoMatchError(err) {
+				logger.Info("cert-manager is not installed, not creating Certificates")
+				return nil
+			}
+			return err
+		}
+	}
+
+	// Delete Certificates from this Ingress that are no longer needed
+	var list unstructured.UnstructuredList
+	list.SetGroupVersionKind(converter.CertificateGVK.GroupVersion().WithKind(converter.CertificateGVK.Kind + "List"))
+	if err := r.List(ctx, &list, client.InNamespace(ingress.Namespace)); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil
+		}
+		return err
+	}
+	for _, certificate := range list.Items {
+		if certificate.GetAnnotations()[SourceAnnotation] != sourceRef {
+			continue
+		}
+		if _, ok := expected[certificate.GetName()]; ok {
+			continue
+		}
+		if err := r.Delete(ctx, &certificate); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		logger.Info("Deleted stale Certificate", "name", certificate.GetName())
+	}
+
+	return nil
+}
+
+// reconcileCertificate creates or updates a single cert-manager Certificate.
+func (r *IngressReconciler) reconcileCertificate(
+	ctx context.Context,
+	ingress *networkingv1.Ingress,
+	certificate *unstructured.Unstructured,
+) error {
+	logger := log.FromContext(ctx)
+
+	// Set owner reference
+	converter.SetPolicyOwnerReference(certificate, ingress)
+
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(converter.CertificateGVK)
+	err := r.Get(ctx, client.ObjectKeyFromObject(certificate), existing)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			if err := r.Create(ctx, certificate); err != nil {
+				if apierrors.IsInvalid(err) || apierrors.IsBadRequest(err) {
+					logger.Error(err, "Invalid Certificate, will retry with longer delay", "name", certificate.GetName())
+					return newPermanentError(err)
+				}
+				return err
+			}
+			logger.Info("Created Certificate", "name", certificate.GetName())
+			return nil
+		}
+		return err
+	}
+
+	if existing.GetAnnotations()[SourceAnnotation] != certificate.GetAnnotations()[SourceAnnotation] {
+		logger.Info("Certificate exists and is not managed by this Ingress, not updating",
+			"name", certificate.GetName())
+		return nil
+	}
+
+	if equality.Semantic.DeepEqual(existing.Object["spec"], certificate.Object["spec"]) &&
+		equality.Semantic.DeepEqual(existing.GetLabels(), certificate.GetLabels()) {
+		return nil
+	}
+
+	existing.Object["spec"] = certificate.Object["spec"]
+	existing.SetLabels(certificate.GetLabels())
+	existing.SetOwnerReferences(certificate.GetOwnerReferences())
+	if err := r.Update(ctx, existing); err != nil {
+		if apierrors.IsInvalid(err) || apierrors.IsBadRequest(err) {
+			logger.Error(err, "Invalid Certificate update, will retry with longer delay", "name", certificate.GetName())
+			return newPermanentError(err)
+		}
+		return err
+	}
+	logger.Info("Updated Certificate", "name", certificate.GetName())
+	return nil
+}
@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"context"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/werdnum/ingress-gateway-api/internal/annotations"
+	"github.com/werdnum/ingress-gateway-api/internal/converter"
+)
+
+// findIngressesForConfigMap returns the Ingresses whose proxy-set-headers annotation
+// names the ConfigMap, so that header changes reach their HTTPRoutes.
+func (r *IngressReconciler) findIngressesForConfigMap(ctx context.Context, obj client.Object) []reconcile.Request {
+	var ingresses networkingv1.IngressList
+	if err := r.List(ctx, &ingresses, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list Ingresses for ConfigMap",
+			"configMap", client.ObjectKeyFromObject(obj).String())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, ingress := range ingresses.Items {
+		ref, ok := ingress.Annotations[annotations.ProxySetHeaders]
+		if !ok {
+			continue
+		}
+		namespace, name := converter.ProxySetHeadersConfigMap(ingress.Namespace, ref)
+		if namespace == obj.GetNamespace() && name == obj.GetName() {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name},
+			})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// findIngressesSharingHosts returns the other Ingresses with rules for the hosts of an
+// Ingress. Annotations such as use-regex apply to every Ingress of a host, and canary
+// Ingresses are merged into the routes of their primary Ingress, so a change to one
+// Ingress can change the routes of the others. The www counterparts of the hosts count
+// too, as from-to-www-redirect only redirects counterparts no Ingress serves.
+func (r *IngressReconciler) findIngressesSharingHosts(ctx context.Context, obj client.Object) []reconcile.Request {
+	changed, ok := obj.(*networkingv1.Ingress)
+	if !ok || !r.shouldProcess(changed) {
+		return nil
+	}
+	hosts := make(map[string]struct{}, len(changed.Spec.Rules))
+	for _, rule := range changed.Spec.Rules {
+		hosts[rule.Host] = struct{}{}
+		if rule.Host != "" {
+			hosts["www."+rule.Host] = struct{}{}
+			hosts[strings.TrimPrefix(rule.Host, "www.")] = struct{}{}
+		}
+	}
+
+	var ingresses networkingv1.IngressList
+	if err := r.List(ctx, &ingresses); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list Ingresses sharing hosts",
+			"ingress", client.ObjectKeyFromObject(obj).String())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range ingresses.Items {
+		ingress := &ingresses.Items[i]
+		if ingress.Namespace == changed.Namespace && ingress.Name == changed.Name || !r.shouldProcess(ingress) {
+			continue
+		}
+		sharesHost := slices.ContainsFunc(ingress.Spec.Rules, func(rule networkingv1.IngressRule) bool {
+			_, ok := hosts[rule.Host]
+			return ok
+		})
+		if sharesHost {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name},
+			})
+		}
+	}
+	return requests
+}
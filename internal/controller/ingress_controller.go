@@ -7,15 +7,27 @@ import (
 	"time"
 
 	egv1alpha1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
 	"github.com/werdnum/ingress-gateway-api/internal/config"
@@ -75,21 +87,44 @@ type IngressReconciler struct {
 	Scheme    *runtime.Scheme
 	Config    *config.Config
 	Converter *converter.Converter
+	Recorder  events.EventRecorder
+
+	// noTLSRoutes is set when the experimental TLSRoute CRD is not installed, so
+	// ssl-passthrough Ingresses get no TLSRoutes.
+	noTLSRoutes bool
+
+	// noCertificates is set when the cert-manager Certificate CRD is not installed, so
+	// issuer annotations get no Certificates.
+	noCertificates bool
 }
 
+// ReasonTLSRouteUnavailable is the reason of the Event recorded when an ssl-passthrough
+// Ingress needs a TLSRoute and the TLSRoute CRD is not installed.
+const ReasonTLSRouteUnavailable = "TLSRouteUnavailable"
+
 // +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;update;patch
 // +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses/status,verbs=update;patch
 // +kubebuilder:rbac:groups=networking.k8s.io,resources=ingressclasses,verbs=get;list;watch
-// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gateways,verbs=get;list;watch
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gateways,verbs=get;list;watch;update;patch
 // +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=tlsroutes,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=referencegrants,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=gateway.networking.x-k8s.io,resources=xlistenersets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=gateway.envoyproxy.io,resources=backendtrafficpolicies,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=gateway.envoyproxy.io,resources=clienttrafficpolicies,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=gateway.envoyproxy.io,resources=securitypolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=gateway.envoyproxy.io,resources=backends,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=gateway.envoyproxy.io,resources=httproutefilters,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=backendtlspolicies,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups="",resources=services;secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=services;secrets;configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch;create;update;patch;delete
 
+// Writing Secrets, for the copies made with --tls-secret-mode=Copy, has no marker: a marker
+// can only name a fixed namespace, and the copies are made in --gateway-namespace. A Role in
+// that namespace grants it, see config/rbac/role.yaml and the Helm chart.
+
 // Reconcile handles Ingress reconciliation.
 func (r *IngressReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
@@ -138,6 +173,16 @@ func (r *IngressReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 
 	// Convert Ingress to HTTPRoutes and policies
 	result := r.Converter.ConvertIngressFull(ctx, &ingress)
+	for _, warning := range result.Warnings {
+		logger.Info("Conversion warning", "warning", warning)
+	}
+
+	// Create or update HTTPRouteFilters before the HTTPRoutes that reference them
+	for _, filter := range result.HTTPRouteFilters {
+		if err := r.reconcileHTTPRouteFilter(ctx, &ingress, filter); err != nil {
+			return handleReconcileError(err)
+		}
+	}
 
 	// Create or update HTTPRoutes
 	for _, httpRoute := range result.HTTPRoutes {
@@ -146,8 +191,24 @@ func (r *IngressReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		}
 	}
 
+	// Create or update TLSRoutes
+	if r.noTLSRoutes && len(result.TLSRoutes) > 0 {
+		logger.Info("TLSRoute CRD is not installed, skipping TLSRoutes", "tlsRoutes", len(result.TLSRoutes))
+		if r.Recorder != nil {
+			r.Recorder.Eventf(&ingress, nil, corev1.EventTypeWarning, ReasonTLSRouteUnavailable, "ReconcileTLSRoute",
+				"ssl-passthrough needs the TLSRoute CRD of the Gateway API experimental channel, which is not installed")
+		}
+		result.TLSRoutes = nil
+	}
+	for _, tlsRoute := range result.TLSRoutes {
+		if err := r.reconcileTLSRoute(ctx, &ingress, tlsRoute); err != nil {
+			return handleReconcileError(err)
+		}
+	}
+
 	// Create ReferenceGrant if needed for cross-namespace backend references
-	if err := r.reconcileReferenceGrants(ctx, &ingress, result.HTTPRoutes); err != nil {
+	sourceRef := fmt.Sprintf("%s/%s", ingress.Namespace, ingress.Name)
+	if err := r.reconcileReferenceGrants(ctx, sourceRef, backendReferenceGrants(&ingress, result.HTTPRoutes)); err != nil {
 		return handleReconcileError(err)
 	}
 
@@ -179,11 +240,38 @@ func (r *IngressReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		}
 	}
 
+	// Reconcile Backends
+	for _, backend := range result.Backends {
+		if err := r.reconcileBackend(ctx, &ingress, backend); err != nil {
+			return handleReconcileError(err)
+		}
+	}
+
+	// Reconcile cert-manager Certificates
+	if err := r.reconcileCertificates(ctx, &ingress, result.Certificates); err != nil {
+		return handleReconcileError(err)
+	}
+
 	// Clean up stale resources that are no longer needed
 	if err := r.cleanupStaleResources(ctx, &ingress, result); err != nil {
 		return handleReconcileError(err)
 	}
 
+	// Check the TLS Secrets before Envoy Gateway rejects the listeners
+	problems, nextExpiry, err := r.checkCertificates(ctx, &ingress, result.Listeners, time.Now())
+	if err != nil {
+		return handleReconcileError(err)
+	}
+	if err := r.reportCertificateProblems(ctx, &ingress, problems); err != nil {
+		logger.Error(err, "failed to report TLS certificate problems")
+	}
+
+	// Move progressive canaries to their next step
+	rolloutDelay, err := r.advanceCanaryRollout(ctx, &ingress, time.Now())
+	if err != nil {
+		return handleReconcileError(err)
+	}
+
 	// Update Ingress status with Gateway address
 	if err := r.updateIngressStatus(ctx, &ingress); err != nil {
 		logger.Error(err, "failed to update Ingress status")
@@ -192,11 +280,26 @@ func (r *IngressReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 
 	logger.Info("Successfully reconciled Ingress",
 		"httpRoutes", len(result.HTTPRoutes),
+		"tlsRoutes", len(result.TLSRoutes),
 		"backendTrafficPolicies", len(result.BackendTrafficPolicies),
 		"securityPolicies", len(result.SecurityPolicies),
 		"backendTLSPolicies", len(result.BackendTLSPolicies),
-		"hasClientTrafficPolicy", result.ClientTrafficPolicy != nil)
-	return ctrl.Result{}, nil
+		"backends", len(result.Backends),
+		"httpRouteFilters", len(result.HTTPRouteFilters),
+		"certificates", len(result.Certificates),
+		"hasClientTrafficPolicy", result.ClientTrafficPolicy != nil,
+		"listeners", len(result.Listeners),
+		"certificateProblems", len(problems))
+
+	// Check again when the next certificate expires or the canary step ends
+	requeueAfter := rolloutDelay
+	if !nextExpiry.IsZero() {
+		untilExpiry := time.Until(nextExpiry) + time.Second
+		if requeueAfter == 0 || untilExpiry < requeueAfter {
+			requeueAfter = untilExpiry
+		}
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
 }
 
 // shouldProcess checks if the Ingress should be processed based on the ingress class filter.
@@ -209,14 +312,7 @@ func (r *IngressReconciler) shouldProcess(ingress *networkingv1.Ingress) bool {
 
 // getIngressClass returns the ingress class of the Ingress.
 func (r *IngressReconciler) getIngressClass(ingress *networkingv1.Ingress) string {
-	if ingress.Spec.IngressClassName != nil {
-		return *ingress.Spec.IngressClassName
-	}
-	// Check deprecated annotation
-	if class, ok := ingress.Annotations["kubernetes.io/ingress.class"]; ok {
-		return class
-	}
-	return ""
+	return converter.IngressClassName(ingress)
 }
 
 // handleDeletion handles Ingress deletion by cleaning up owned resources.
@@ -229,11 +325,29 @@ func (r *IngressReconciler) handleDeletion(ctx context.Context, ingress *network
 			return ctrl.Result{}, err
 		}
 
+		// Delete owned TLSRoutes
+		if err := r.deleteOwnedTLSRoutes(ctx, ingress); err != nil {
+			return ctrl.Result{}, err
+		}
+
 		// Delete owned policies
 		if err := r.deleteOwnedPolicies(ctx, ingress); err != nil {
 			return ctrl.Result{}, err
 		}
 
+		// Delete cert-manager Certificates
+		if err := r.reconcileCertificates(ctx, ingress, nil); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		// Delete ReferenceGrants created for backend references
+		sourceRef := fmt.Sprintf("%s/%s", ingress.Namespace, ingress.Name)
+		if err := r.reconcileReferenceGrants(ctx, sourceRef, nil); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		certificateExpiry.DeletePartialMatch(prometheus.Labels{"namespace": ingress.Namespace, "ingress": ingress.Name})
+
 		// Remove finalizer using patch to avoid triggering admission webhooks
 		patch := client.MergeFrom(ingress.DeepCopy())
 		controllerutil.RemoveFinalizer(ingress, FinalizerName)
@@ -262,6 +376,11 @@ func (r *IngressReconciler) cleanupStaleResources(ctx context.Context, ingress *
 		expectedHTTPRoutes[route.Name] = struct{}{}
 	}
 
+	expectedTLSRoutes := make(map[string]struct{})
+	for _, route := range result.TLSRoutes {
+		expectedTLSRoutes[route.Name] = struct{}{}
+	}
+
 	expectedBTPs := make(map[string]struct{})
 	for _, btp := range result.BackendTrafficPolicies {
 		expectedBTPs[btp.Name] = struct{}{}
@@ -278,6 +397,16 @@ func (r *IngressReconciler) cleanupStaleResources(ctx context.Context, ingress *
 		expectedBTLSs[fmt.Sprintf("%s/%s", btls.Namespace, btls.Name)] = struct{}{}
 	}
 
+	expectedBackends := make(map[string]struct{})
+	for _, backend := range result.Backends {
+		expectedBackends[backend.Name] = struct{}{}
+	}
+
+	expectedFilters := make(map[string]struct{})
+	for _, filter := range result.HTTPRouteFilters {
+		expectedFilters[filter.Name] = struct{}{}
+	}
+
 	// Clean up stale HTTPRoutes
 	var httpRoutes gatewayv1.HTTPRouteList
 	if err := r.List(ctx, &httpRoutes, client.InNamespace(ingress.Namespace)); err != nil {
@@ -294,6 +423,24 @@ func (r *IngressReconciler) cleanupStaleResources(ctx context.Context, ingress *
 		}
 	}
 
+	// Clean up stale TLSRoutes
+	var tlsRoutes gatewayv1alpha2.TLSRouteList
+	if !r.noTLSRoutes {
+		if err := r.List(ctx, &tlsRoutes, client.InNamespace(ingress.Namespace)); err != nil {
+			return err
+		}
+	}
+	for _, route := range tlsRoutes.Items {
+		if route.Annotations[SourceAnnotation] == sourceRef {
+			if _, expected := expectedTLSRoutes[route.Name]; !expected {
+				if err := r.Delete(ctx, &route); err != nil && !apierrors.IsNotFound(err) {
+					return err
+				}
+				logger.Info("Deleted stale TLSRoute", "name", route.Name)
+			}
+		}
+	}
+
 	// Clean up stale BackendTrafficPolicies
 	var btpList egv1alpha1.BackendTrafficPolicyList
 	if err := r.List(ctx, &btpList, client.InNamespace(ingress.Namespace)); err != nil {
@@ -359,6 +506,38 @@ func (r *IngressReconciler) cleanupStaleResources(ctx context.Context, ingress *
 		}
 	}
 
+	// Clean up stale Backends
+	var backendList egv1alpha1.BackendList
+	if err := r.List(ctx, &backendList, client.InNamespace(ingress.Namespace)); err != nil {
+		return err
+	}
+	for _, backend := range backendList.Items {
+		if backend.Annotations[SourceAnnotation] == sourceRef {
+			if _, expected := expectedBackends[backend.Name]; !expected {
+				if err := r.Delete(ctx, &backend); err != nil && !apierrors.IsNotFound(err) {
+					return err
+				}
+				logger.Info("Deleted stale Backend", "name", backend.Name)
+			}
+		}
+	}
+
+	// Clean up stale HTTPRouteFilters
+	var filterList egv1alpha1.HTTPRouteFilterList
+	if err := r.List(ctx, &filterList, client.InNamespace(ingress.Namespace)); err != nil {
+		return err
+	}
+	for _, filter := range filterList.Items {
+		if filter.Annotations[SourceAnnotation] == sourceRef {
+			if _, expected := expectedFilters[filter.Name]; !expected {
+				if err := r.Delete(ctx, &filter); err != nil && !apierrors.IsNotFound(err) {
+					return err
+				}
+				logger.Info("Deleted stale HTTPRouteFilter", "name", filter.Name)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -384,6 +563,32 @@ func (r *IngressReconciler) deleteOwnedHTTPRoutes(ctx context.Context, ingress *
 	return nil
 }
 
+// deleteOwnedTLSRoutes deletes all TLSRoutes owned by the Ingress.
+func (r *IngressReconciler) deleteOwnedTLSRoutes(ctx context.Context, ingress *networkingv1.Ingress) error {
+	logger := log.FromContext(ctx)
+
+	if r.noTLSRoutes {
+		return nil
+	}
+
+	var tlsRoutes gatewayv1alpha2.TLSRouteList
+	if err := r.List(ctx, &tlsRoutes, client.InNamespace(ingress.Namespace)); err != nil {
+		return err
+	}
+
+	sourceRef := fmt.Sprintf("%s/%s", ingress.Namespace, ingress.Name)
+	for _, route := range tlsRoutes.Items {
+		if route.Annotations[SourceAnnotation] == sourceRef {
+			if err := r.Delete(ctx, &route); err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+			logger.Info("Deleted TLSRoute", "name", route.Name)
+		}
+	}
+
+	return nil
+}
+
 // deleteOwnedPolicies deletes all policy resources owned by the Ingress.
 func (r *IngressReconciler) deleteOwnedPolicies(ctx context.Context, ingress *networkingv1.Ingress) error {
 	logger := log.FromContext(ctx)
@@ -446,6 +651,34 @@ func (r *IngressReconciler) deleteOwnedPolicies(ctx context.Context, ingress *ne
 		}
 	}
 
+	// Delete Backends
+	var backendList egv1alpha1.BackendList
+	if err := r.List(ctx, &backendList, client.InNamespace(ingress.Namespace)); err != nil {
+		return err
+	}
+	for _, backend := range backendList.Items {
+		if backend.Annotations[SourceAnnotation] == sourceRef {
+			if err := r.Delete(ctx, &backend); err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+			logger.Info("Deleted Backend", "name", backend.Name)
+		}
+	}
+
+	// Delete HTTPRouteFilters
+	var filterList egv1alpha1.HTTPRouteFilterList
+	if err := r.List(ctx, &filterList, client.InNamespace(ingress.Namespace)); err != nil {
+		return err
+	}
+	for _, filter := range filterList.Items {
+		if filter.Annotations[SourceAnnotation] == sourceRef {
+			if err := r.Delete(ctx, &filter); err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+			logger.Info("Deleted HTTPRouteFilter", "name", filter.Name)
+		}
+	}
+
 	return nil
 }
 
@@ -495,6 +728,51 @@ func (r *IngressReconciler) reconcileHTTPRoute(ctx context.Context, ingress *net
 	return nil
 }
 
+// reconcileTLSRoute creates or updates a TLSRoute.
+func (r *IngressReconciler) reconcileTLSRoute(ctx context.Context, ingress *networkingv1.Ingress, tlsRoute *gatewayv1alpha2.TLSRoute) error {
+	logger := log.FromContext(ctx)
+
+	// Set namespace to match Ingress
+	tlsRoute.Namespace = ingress.Namespace
+
+	// Set owner reference
+	converter.SetPolicyOwnerReference(tlsRoute, ingress)
+
+	// Check if TLSRoute exists
+	existing := &gatewayv1alpha2.TLSRoute{}
+	err := r.Get(ctx, client.ObjectKeyFromObject(tlsRoute), existing)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			if err := r.Create(ctx, tlsRoute); err != nil {
+				if apierrors.IsInvalid(err) || apierrors.IsBadRequest(err) {
+					logger.Error(err, "Invalid TLSRoute, will retry with longer delay", "name", tlsRoute.Name)
+					return newPermanentError(err)
+				}
+				return err
+			}
+			logger.Info("Created TLSRoute", "name", tlsRoute.Name)
+			return nil
+		}
+		return err
+	}
+
+	// Update existing TLSRoute
+	existing.Spec = tlsRoute.Spec
+	existing.Annotations = tlsRoute.Annotations
+	existing.Labels = tlsRoute.Labels
+	existing.OwnerReferences = tlsRoute.OwnerReferences
+
+	if err := r.Update(ctx, existing); err != nil {
+		if apierrors.IsInvalid(err) || apierrors.IsBadRequest(err) {
+			logger.Error(err, "Invalid TLSRoute update, will retry with longer delay", "name", tlsRoute.Name)
+			return newPermanentError(err)
+		}
+		return err
+	}
+	logger.Info("Updated TLSRoute", "name", tlsRoute.Name)
+	return nil
+}
+
 // reconcileBackendTrafficPolicy creates or updates a BackendTrafficPolicy.
 func (r *IngressReconciler) reconcileBackendTrafficPolicy(ctx context.Context, ingress *networkingv1.Ingress, policy *egv1alpha1.BackendTrafficPolicy) error {
 	logger := log.FromContext(ctx)
@@ -672,10 +950,95 @@ func (r *IngressReconciler) reconcileBackendTLSPolicy(ctx context.Context, ingre
 	return nil
 }
 
-// reconcileReferenceGrants creates ReferenceGrants for cross-namespace backend references.
-func (r *IngressReconciler) reconcileReferenceGrants(ctx context.Context, ingress *networkingv1.Ingress, httpRoutes []*gatewayv1.HTTPRoute) error {
+// reconcileBackend creates or updates an Envoy Gateway Backend.
+func (r *IngressReconciler) reconcileBackend(ctx context.Context, ingress *networkingv1.Ingress, backend *egv1alpha1.Backend) error {
 	logger := log.FromContext(ctx)
 
+	// Set namespace to match Ingress
+	backend.Namespace = ingress.Namespace
+
+	// Set owner reference
+	converter.SetPolicyOwnerReference(backend, ingress)
+
+	// Check if Backend exists
+	existing := &egv1alpha1.Backend{}
+	err := r.Get(ctx, client.ObjectKeyFromObject(backend), existing)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			if err := r.Create(ctx, backend); err != nil {
+				if apierrors.IsInvalid(err) || apierrors.IsBadRequest(err) {
+					logger.Error(err, "Invalid Backend, will retry with longer delay", "name", backend.Name)
+					return newPermanentError(err)
+				}
+				return err
+			}
+			logger.Info("Created Backend", "name", backend.Name)
+			return nil
+		}
+		return err
+	}
+
+	// Update existing Backend
+	existing.Spec = backend.Spec
+	existing.Annotations = backend.Annotations
+	existing.Labels = backend.Labels
+	existing.OwnerReferences = backend.OwnerReferences
+
+	if err := r.Update(ctx, existing); err != nil {
+		if apierrors.IsInvalid(err) || apierrors.IsBadRequest(err) {
+			logger.Error(err, "Invalid Backend update, will retry with longer delay", "name", backend.Name)
+			return newPermanentError(err)
+		}
+		return err
+	}
+	logger.Info("Updated Backend", "name", backend.Name)
+	return nil
+}
+
+// reconcileHTTPRouteFilter creates or updates an HTTPRouteFilter.
+func (r *IngressReconciler) reconcileHTTPRouteFilter(ctx context.Context, ingress *networkingv1.Ingress, filter *egv1alpha1.HTTPRouteFilter) error {
+	logger := log.FromContext(ctx)
+
+	// Set owner reference
+	converter.SetPolicyOwnerReference(filter, ingress)
+
+	// Check if HTTPRouteFilter exists
+	existing := &egv1alpha1.HTTPRouteFilter{}
+	err := r.Get(ctx, client.ObjectKeyFromObject(filter), existing)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			if err := r.Create(ctx, filter); err != nil {
+				if apierrors.IsInvalid(err) || apierrors.IsBadRequest(err) {
+					logger.Error(err, "Invalid HTTPRouteFilter, will retry with longer delay", "name", filter.Name)
+					return newPermanentError(err)
+				}
+				return err
+			}
+			logger.Info("Created HTTPRouteFilter", "name", filter.Name)
+			return nil
+		}
+		return err
+	}
+
+	// Update existing HTTPRouteFilter
+	existing.Spec = filter.Spec
+	existing.Annotations = filter.Annotations
+	existing.Labels = filter.Labels
+	existing.OwnerReferences = filter.OwnerReferences
+
+	if err := r.Update(ctx, existing); err != nil {
+		if apierrors.IsInvalid(err) || apierrors.IsBadRequest(err) {
+			logger.Error(err, "Invalid HTTPRouteFilter update, will retry with longer delay", "name", filter.Name)
+			return newPermanentError(err)
+		}
+		return err
+	}
+	logger.Info("Updated HTTPRouteFilter", "name", filter.Name)
+	return nil
+}
+
+// backendReferenceGrants builds the ReferenceGrants needed for cross-namespace backend references.
+func backendReferenceGrants(ingress *networkingv1.Ingress, httpRoutes []*gatewayv1.HTTPRoute) []*gatewayv1beta1.ReferenceGrant {
 	// Collect unique backend namespaces that differ from the HTTPRoute namespace
 	backendNamespaces := make(map[string]struct{})
 	for _, route := range httpRoutes {
@@ -688,9 +1051,10 @@ func (r *IngressReconciler) reconcileReferenceGrants(ctx context.Context, ingres
 		}
 	}
 
-	// Create ReferenceGrant in each backend namespace
+	// One ReferenceGrant in each backend namespace
+	grants := make([]*gatewayv1beta1.ReferenceGrant, 0, len(backendNamespaces))
 	for ns := range backendNamespaces {
-		grant := &gatewayv1beta1.ReferenceGrant{
+		grants = append(grants, &gatewayv1beta1.ReferenceGrant{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      fmt.Sprintf("ingress-%s-%s", ingress.Namespace, ingress.Name),
 				Namespace: ns,
@@ -713,7 +1077,20 @@ func (r *IngressReconciler) reconcileReferenceGrants(ctx context.Context, ingres
 					},
 				},
 			},
-		}
+		})
+	}
+
+	return grants
+}
+
+// reconcileReferenceGrants creates and updates the desired ReferenceGrants, and deletes
+// any other ReferenceGrant in the cluster whose source annotation matches sourceRef.
+func (r *IngressReconciler) reconcileReferenceGrants(ctx context.Context, sourceRef string, desired []*gatewayv1beta1.ReferenceGrant) error {
+	logger := log.FromContext(ctx)
+
+	expected := make(map[string]struct{}, len(desired))
+	for _, grant := range desired {
+		expected[fmt.Sprintf("%s/%s", grant.Namespace, grant.Name)] = struct{}{}
 
 		existing := &gatewayv1beta1.ReferenceGrant{}
 		err := r.Get(ctx, client.ObjectKeyFromObject(grant), existing)
@@ -722,17 +1099,44 @@ func (r *IngressReconciler) reconcileReferenceGrants(ctx context.Context, ingres
 				if err := r.Create(ctx, grant); err != nil {
 					return err
 				}
-				logger.Info("Created ReferenceGrant", "namespace", ns, "name", grant.Name)
+				logger.Info("Created ReferenceGrant", "namespace", grant.Namespace, "name", grant.Name)
 				continue
 			}
 			return err
 		}
 
 		// Update if needed
+		if equality.Semantic.DeepEqual(existing.Spec, grant.Spec) &&
+			existing.Annotations[SourceAnnotation] == sourceRef {
+			continue
+		}
 		existing.Spec = grant.Spec
+		if existing.Annotations == nil {
+			existing.Annotations = make(map[string]string)
+		}
+		existing.Annotations[SourceAnnotation] = sourceRef
 		if err := r.Update(ctx, existing); err != nil {
 			return err
 		}
+		logger.Info("Updated ReferenceGrant", "namespace", grant.Namespace, "name", grant.Name)
+	}
+
+	// Delete grants from this source that are no longer needed
+	var grants gatewayv1beta1.ReferenceGrantList
+	if err := r.List(ctx, &grants); err != nil {
+		return err
+	}
+	for _, grant := range grants.Items {
+		if grant.Annotations[SourceAnnotation] != sourceRef {
+			continue
+		}
+		if _, ok := expected[fmt.Sprintf("%s/%s", grant.Namespace, grant.Name)]; ok {
+			continue
+		}
+		if err := r.Delete(ctx, &grant); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		logger.Info("Deleted stale ReferenceGrant", "namespace", grant.Namespace, "name", grant.Name)
 	}
 
 	return nil
@@ -799,14 +1203,65 @@ func ingressStatusEqual(a, b []networkingv1.IngressLoadBalancerIngress) bool {
 	return true
 }
 
-// SetupWithManager sets up the controller with the Manager.
+// SetupWithManager sets up the controller with the Manager. TLSRoutes are only watched
+// when their CRD, part of the Gateway API experimental channel, is installed, and
+// cert-manager Certificates only when cert-manager is.
+// The listeners of the shared Gateway are kept up to date by a second controller, as
+// they depend on every Ingress rather than one. Secrets and ConfigMaps are only watched
+// by their metadata, so that their data is not cached for the whole cluster; the
+// manager reads them from the API server instead.
 func (r *IngressReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	tlsRouteKind := schema.GroupKind{Group: gatewayv1alpha2.GroupName, Kind: "TLSRoute"}
+	if _, err := mgr.GetRESTMapper().RESTMapping(tlsRouteKind, gatewayv1alpha2.GroupVersion.Version); err != nil {
+		if !meta.IsNoMatchError(err) {
+			return fmt.Errorf("looking up the TLSRoute CRD: %w", err)
+		}
+		mgr.GetLogger().Info("TLSRoute CRD is not installed, ssl-passthrough Ingresses get no TLSRoutes")
+		r.noTLSRoutes = true
+	}
+	certificateKind := converter.CertificateGVK.GroupKind()
+	if _, err := mgr.GetRESTMapper().RESTMapping(certificateKind, converter.CertificateGVK.Version); err != nil {
+		if !meta.IsNoMatchError(err) {
+			return fmt.Errorf("looking up the cert-manager Certificate CRD: %w", err)
+		}
+		mgr.GetLogger().Info("cert-manager Certificate CRD is not installed, issuer annotations get no Certificates")
+		r.noCertificates = true
+	}
+
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&networkingv1.Ingress{}).
-		Owns(&gatewayv1.HTTPRoute{}).
+		Owns(&gatewayv1.HTTPRoute{})
+	if !r.noTLSRoutes {
+		b = b.Owns(&gatewayv1alpha2.TLSRoute{})
+	}
+	if !r.noCertificates {
+		certificate := &unstructured.Unstructured{}
+		certificate.SetGroupVersionKind(converter.CertificateGVK)
+		b = b.Owns(certificate)
+	}
+	if err := b.
 		Owns(&egv1alpha1.BackendTrafficPolicy{}).
 		Owns(&egv1alpha1.ClientTrafficPolicy{}).
 		Owns(&egv1alpha1.SecurityPolicy{}).
 		Owns(&gatewayv1.BackendTLSPolicy{}).
-		Complete(r)
+		Owns(&egv1alpha1.Backend{}).
+		Owns(&egv1alpha1.HTTPRouteFilter{}).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.findIngressesForSecret), builder.OnlyMetadata).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.findIngressesForConfigMap), builder.OnlyMetadata).
+		Watches(&networkingv1.Ingress{}, handler.EnqueueRequestsFromMapFunc(r.findIngressesSharingHosts),
+			builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}))).
+		Watches(&gatewayv1.Gateway{}, r.gatewayListenersHandler(),
+			builder.WithPredicates(predicate.NewPredicateFuncs(r.isSharedGateway), predicate.GenerationChangedPredicate{})).
+		Complete(r); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("gateway-listeners").
+		For(&gatewayv1.Gateway{}, builder.WithPredicates(predicate.NewPredicateFuncs(r.isSharedGateway),
+			predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}))).
+		Watches(&networkingv1.Ingress{}, handler.EnqueueRequestsFromMapFunc(r.sharedGatewayRequest),
+			builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}))).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.findGatewayForSecret), builder.OnlyMetadata).
+		Complete(reconcile.Func(r.reconcileListeners))
 }
@@ -2,19 +2,43 @@ package controller
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	egv1alpha1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+	gatewayxv1alpha1 "sigs.k8s.io/gateway-api/apisx/v1alpha1"
 
+	"github.com/werdnum/ingress-gateway-api/internal/annotations"
 	"github.com/werdnum/ingress-gateway-api/internal/config"
 	"github.com/werdnum/ingress-gateway-api/internal/converter"
 )
@@ -24,7 +48,9 @@ func setupScheme() *runtime.Scheme {
 	_ = clientgoscheme.AddToScheme(scheme)
 	_ = networkingv1.AddToScheme(scheme)
 	_ = gatewayv1.Install(scheme)
+	_ = gatewayv1alpha2.Install(scheme)
 	_ = gatewayv1beta1.Install(scheme)
+	_ = gatewayxv1alpha1.Install(scheme)
 	_ = egv1alpha1.AddToScheme(scheme)
 	return scheme
 }
@@ -542,6 +568,1841 @@ func TestIngressReconciler_Reconcile_CleansUpStaleSecurityPolicy(t *testing.T) {
 	}
 }
 
+// testGateway returns the shared Gateway with a plain HTTP listener.
+func testGateway() *gatewayv1.Gateway {
+	return &gatewayv1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-gateway",
+			Namespace: "envoy-gateway",
+		},
+		Spec: gatewayv1.GatewaySpec{
+			GatewayClassName: "eg",
+			Listeners: []gatewayv1.Listener{
+				{
+					Name:     "http",
+					Port:     80,
+					Protocol: gatewayv1.HTTPProtocolType,
+				},
+			},
+		},
+	}
+}
+
+// tlsIngress returns an Ingress for example.com that terminates TLS with the given Secret.
+func tlsIngress(secretName string) *networkingv1.Ingress {
+	return &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-ingress",
+			Namespace:  "default",
+			UID:        types.UID("test-uid"),
+			Finalizers: []string{FinalizerName},
+		},
+		Spec: networkingv1.IngressSpec{
+			TLS: []networkingv1.IngressTLS{
+				{
+					Hosts:      []string{"example.com"},
+					SecretName: secretName,
+				},
+			},
+			Rules: []networkingv1.IngressRule{
+				{
+					Host: "example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{
+								{
+									Path:     "/",
+									PathType: ptr(networkingv1.PathTypePrefix),
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{
+											Name: "web-service",
+											Port: networkingv1.ServiceBackendPort{
+												Number: 80,
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestIngressReconciler_Reconcile_CopiesTLSSecrets(t *testing.T) {
+	scheme := setupScheme()
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example-tls",
+			Namespace: "default",
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			"tls.crt": []byte("cert-v1"),
+			"tls.key": []byte("key-v1"),
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(testGateway(), tlsIngress("example-tls"), secret).
+		Build()
+
+	cfg := &config.Config{
+		GatewayName:      "test-gateway",
+		GatewayNamespace: "envoy-gateway",
+		TLSSecretMode:    config.TLSSecretModeCopy,
+	}
+
+	r := &IngressReconciler{
+		Client:    fakeClient,
+		Scheme:    scheme,
+		Config:    cfg,
+		Converter: converter.New(cfg),
+	}
+
+	ctx := context.Background()
+	req := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "test-ingress",
+			Namespace: "default",
+		},
+	}
+	copyKey := types.NamespacedName{Name: "default.example-tls", Namespace: "envoy-gateway"}
+
+	// First reconcile - should copy the Secret and reference the copy
+	if err := reconcileWithListeners(ctx, r, req); err != nil {
+		t.Fatalf("unexpected error on first reconcile: %v", err)
+	}
+
+	copied := &corev1.Secret{}
+	if err := fakeClient.Get(ctx, copyKey, copied); err != nil {
+		t.Fatalf("expected Secret copy: %v", err)
+	}
+	if string(copied.Data["tls.crt"]) != "cert-v1" {
+		t.Errorf("expected copied certificate, got %q", copied.Data["tls.crt"])
+	}
+
+	gateway := &gatewayv1.Gateway{}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Name: "test-gateway", Namespace: "envoy-gateway"}, gateway); err != nil {
+		t.Fatalf("failed to get gateway: %v", err)
+	}
+	ref := gateway.Spec.Listeners[1].TLS.CertificateRefs[0]
+	if ref.Name != "default.example-tls" || ref.Namespace == nil || *ref.Namespace != "envoy-gateway" {
+		t.Errorf("expected listener to reference the copy, got %+v", ref)
+	}
+
+	var grants gatewayv1beta1.ReferenceGrantList
+	if err := fakeClient.List(ctx, &grants); err != nil {
+		t.Fatalf("failed to list ReferenceGrants: %v", err)
+	}
+	if len(grants.Items) != 0 {
+		t.Errorf("expected no ReferenceGrants in copy mode, got %d", len(grants.Items))
+	}
+
+	// Rotate the source Secret
+	secret.Data["tls.crt"] = []byte("cert-v2")
+	if err := fakeClient.Update(ctx, secret); err != nil {
+		t.Fatalf("failed to update secret: %v", err)
+	}
+	requests := r.findIngressesForSecret(ctx, &metav1.PartialObjectMetadata{ObjectMeta: secret.ObjectMeta})
+	if len(requests) != 1 || requests[0].NamespacedName != req.NamespacedName {
+		t.Fatalf("expected Secret change to enqueue the Ingress, got %v", requests)
+	}
+	if err := reconcileWithListeners(ctx, r, req); err != nil {
+		t.Fatalf("unexpected error on second reconcile: %v", err)
+	}
+	if err := fakeClient.Get(ctx, copyKey, copied); err != nil {
+		t.Fatalf("expected Secret copy: %v", err)
+	}
+	if string(copied.Data["tls.crt"]) != "cert-v2" {
+		t.Errorf("expected rotated certificate, got %q", copied.Data["tls.crt"])
+	}
+
+	// Deleting the Ingress removes the copy
+	ingress := &networkingv1.Ingress{}
+	if err := fakeClient.Get(ctx, req.NamespacedName, ingress); err != nil {
+		t.Fatalf("failed to get ingress: %v", err)
+	}
+	if err := fakeClient.Delete(ctx, ingress); err != nil {
+		t.Fatalf("failed to delete ingress: %v", err)
+	}
+	if err := reconcileWithListeners(ctx, r, req); err != nil {
+		t.Fatalf("unexpected error on deletion reconcile: %v", err)
+	}
+	if err := fakeClient.Get(ctx, copyKey, copied); !apierrors.IsNotFound(err) {
+		t.Errorf("expected Secret copy to be deleted, got %v", err)
+	}
+}
+
 func ptr[T any](v T) *T {
 	return &v
 }
+
+// reconcileWithListeners reconciles an Ingress and then the listeners of the shared
+// Gateway, as the two controllers do in turn.
+func reconcileWithListeners(ctx context.Context, r *IngressReconciler, req ctrl.Request) error {
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		return err
+	}
+	_, err := r.reconcileListeners(ctx, ctrl.Request{})
+	return err
+}
+
+func TestIngressReconciler_Reconcile_ManagesGatewayListeners(t *testing.T) {
+	scheme := setupScheme()
+
+	gateway := testGateway()
+	ingress := tlsIngress("example-tls")
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(gateway, ingress).
+		Build()
+
+	cfg := &config.Config{
+		GatewayName:      "test-gateway",
+		GatewayNamespace: "envoy-gateway",
+	}
+
+	r := &IngressReconciler{
+		Client:    fakeClient,
+		Scheme:    scheme,
+		Config:    cfg,
+		Converter: converter.New(cfg),
+	}
+
+	ctx := context.Background()
+	req := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "test-ingress",
+			Namespace: "default",
+		},
+	}
+	gatewayKey := types.NamespacedName{Name: "test-gateway", Namespace: "envoy-gateway"}
+
+	// First reconcile - should add the HTTPS listener
+	if err := reconcileWithListeners(ctx, r, req); err != nil {
+		t.Fatalf("unexpected error on first reconcile: %v", err)
+	}
+
+	if err := fakeClient.Get(ctx, gatewayKey, gateway); err != nil {
+		t.Fatalf("failed to get gateway: %v", err)
+	}
+	if len(gateway.Spec.Listeners) != 2 {
+		t.Fatalf("expected 2 listeners, got %d", len(gateway.Spec.Listeners))
+	}
+	https := gateway.Spec.Listeners[1]
+	if https.Name != "https-example-com" {
+		t.Errorf("expected listener https-example-com, got %s", https.Name)
+	}
+	if https.TLS == nil || len(https.TLS.CertificateRefs) != 1 || https.TLS.CertificateRefs[0].Name != "example-tls" {
+		t.Errorf("expected certificate ref example-tls, got %+v", https.TLS)
+	}
+	if gateway.Annotations[ManagedListenersAnnotation] != "https-example-com" {
+		t.Errorf("expected managed listeners annotation, got %q", gateway.Annotations[ManagedListenersAnnotation])
+	}
+
+	// Verify the Gateway may reference the Secret
+	var grants gatewayv1beta1.ReferenceGrantList
+	if err := fakeClient.List(ctx, &grants); err != nil {
+		t.Fatalf("failed to list ReferenceGrants: %v", err)
+	}
+	if len(grants.Items) != 1 {
+		t.Fatalf("expected 1 ReferenceGrant, got %d", len(grants.Items))
+	}
+	grant := grants.Items[0]
+	if grant.Namespace != "default" {
+		t.Errorf("expected ReferenceGrant in default, got %s", grant.Namespace)
+	}
+	if len(grant.Spec.From) != 1 || grant.Spec.From[0].Kind != "Gateway" || grant.Spec.From[0].Namespace != "envoy-gateway" {
+		t.Errorf("expected grant from Gateway in envoy-gateway, got %+v", grant.Spec.From)
+	}
+	if len(grant.Spec.To) != 1 || grant.Spec.To[0].Kind != "Secret" ||
+		grant.Spec.To[0].Name == nil || *grant.Spec.To[0].Name != "example-tls" {
+		t.Errorf("expected grant to Secret example-tls, got %+v", grant.Spec.To)
+	}
+
+	// Remove TLS from the Ingress
+	if err := fakeClient.Get(ctx, req.NamespacedName, ingress); err != nil {
+		t.Fatalf("failed to get ingress: %v", err)
+	}
+	ingress.Spec.TLS = nil
+	if err := fakeClient.Update(ctx, ingress); err != nil {
+		t.Fatalf("failed to update ingress: %v", err)
+	}
+
+	// Second reconcile - should remove the HTTPS listener but keep the HTTP one
+	if err := reconcileWithListeners(ctx, r, req); err != nil {
+		t.Fatalf("unexpected error on second reconcile: %v", err)
+	}
+
+	if err := fakeClient.Get(ctx, gatewayKey, gateway); err != nil {
+		t.Fatalf("failed to get gateway: %v", err)
+	}
+	if len(gateway.Spec.Listeners) != 1 || gateway.Spec.Listeners[0].Name != "http" {
+		t.Errorf("expected only the http listener, got %+v", gateway.Spec.Listeners)
+	}
+	if _, ok := gateway.Annotations[ManagedListenersAnnotation]; ok {
+		t.Error("expected managed listeners annotation to be removed")
+	}
+
+	// Verify the Secret ReferenceGrant was garbage-collected
+	if err := fakeClient.List(ctx, &grants); err != nil {
+		t.Fatalf("failed to list ReferenceGrants: %v", err)
+	}
+	if len(grants.Items) != 0 {
+		t.Errorf("expected 0 ReferenceGrants after cleanup, got %d", len(grants.Items))
+	}
+}
+
+func TestIngressReconciler_ReconcileListeners_LimitsListeners(t *testing.T) {
+	scheme := setupScheme()
+
+	ingress := tlsIngress("example-tls")
+	ingress.Spec.TLS[0].Hosts = nil
+	rule := ingress.Spec.Rules[0]
+	ingress.Spec.Rules = nil
+	for i := range 70 {
+		rule.Host = fmt.Sprintf("host-%02d.example.com", i)
+		ingress.Spec.Rules = append(ingress.Spec.Rules, rule)
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(testGateway(), ingress).
+		Build()
+
+	cfg := &config.Config{
+		GatewayName:      "test-gateway",
+		GatewayNamespace: "envoy-gateway",
+	}
+
+	recorder := events.NewFakeRecorder(10)
+	r := &IngressReconciler{
+		Client:    fakeClient,
+		Scheme:    scheme,
+		Config:    cfg,
+		Converter: converter.New(cfg),
+		Recorder:  recorder,
+	}
+
+	ctx := context.Background()
+	if _, err := r.reconcileListeners(ctx, ctrl.Request{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	gateway := &gatewayv1.Gateway{}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Name: "test-gateway", Namespace: "envoy-gateway"}, gateway); err != nil {
+		t.Fatalf("failed to get gateway: %v", err)
+	}
+	if len(gateway.Spec.Listeners) != maxListeners {
+		t.Fatalf("expected %d listeners, got %d", maxListeners, len(gateway.Spec.Listeners))
+	}
+	if gateway.Spec.Listeners[0].Name != "http" {
+		t.Errorf("expected the http listener to be kept, got %s", gateway.Spec.Listeners[0].Name)
+	}
+	if last := gateway.Spec.Listeners[maxListeners-1].Name; last != "https-host-62-example-com" {
+		t.Errorf("expected the listeners last in name order to be skipped, last listener is %s", last)
+	}
+
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, ReasonTooManyListeners) || !strings.Contains(event, "https-host-69-example-com") {
+			t.Errorf("expected a TooManyListeners event naming the skipped listeners, got %q", event)
+		}
+	default:
+		t.Error("expected a TooManyListeners event")
+	}
+}
+
+func TestIngressReconciler_GatewayListenersHandler(t *testing.T) {
+	scheme := setupScheme()
+
+	other := tlsIngress("other-tls")
+	other.Name = "other-ingress"
+	other.Spec.Rules[0].Host = "other.org"
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(tlsIngress("example-tls"), other).
+		Build()
+
+	cfg := &config.Config{
+		GatewayName:      "test-gateway",
+		GatewayNamespace: "envoy-gateway",
+	}
+	r := &IngressReconciler{
+		Client:    fakeClient,
+		Scheme:    scheme,
+		Config:    cfg,
+		Converter: converter.New(cfg),
+	}
+
+	before := testGateway()
+	after := testGateway()
+	after.Spec.Listeners = append(after.Spec.Listeners, gatewayv1.Listener{
+		Name:     "https-example-com",
+		Hostname: ptr(gatewayv1.Hostname("www.example.com")),
+		Port:     443,
+		Protocol: gatewayv1.HTTPSProtocolType,
+	})
+
+	tests := []struct {
+		name      string
+		listeners []gatewayv1.Listener
+		want      []string
+	}{
+		{
+			name:      "unchanged listeners requeue nothing",
+			listeners: changedListeners(before.Spec.Listeners, before.Spec.Listeners),
+		},
+		{
+			name:      "new listener requeues the Ingresses it accepts, including www counterparts",
+			listeners: changedListeners(before.Spec.Listeners, after.Spec.Listeners),
+			want:      []string{"default/test-ingress"},
+		},
+		{
+			name:      "removed listener without hostname requeues every Ingress",
+			listeners: changedListeners(before.Spec.Listeners, nil),
+			want:      []string{"default/other-ingress", "default/test-ingress"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, request := range r.findIngressesForListeners(context.Background(), tt.listeners) {
+				got = append(got, request.String())
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expected requests %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestIngressReconciler_Reconcile_ManagesClientCertificateAuth(t *testing.T) {
+	scheme := setupScheme()
+
+	gateway := testGateway()
+	ingress := tlsIngress("example-tls")
+	ingress.Annotations = map[string]string{
+		annotations.AuthTLSSecret:                    "default/client-ca",
+		annotations.AuthTLSPassCertificateToUpstream: "true",
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(gateway, ingress).
+		Build()
+
+	cfg := &config.Config{
+		GatewayName:      "test-gateway",
+		GatewayNamespace: "envoy-gateway",
+	}
+
+	r := &IngressReconciler{
+		Client:    fakeClient,
+		Scheme:    scheme,
+		Config:    cfg,
+		Converter: converter.New(cfg),
+	}
+
+	ctx := context.Background()
+	req := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "test-ingress",
+			Namespace: "default",
+		},
+	}
+	policyKey := types.NamespacedName{Name: "test-gateway-https-example-com", Namespace: "envoy-gateway"}
+
+	// First reconcile - should create the listener policy and allow it to use the CA Secret
+	if err := reconcileWithListeners(ctx, r, req); err != nil {
+		t.Fatalf("unexpected error on first reconcile: %v", err)
+	}
+
+	policy := &egv1alpha1.ClientTrafficPolicy{}
+	if err := fakeClient.Get(ctx, policyKey, policy); err != nil {
+		t.Fatalf("expected listener ClientTrafficPolicy: %v", err)
+	}
+	if len(policy.Spec.TargetRefs) != 1 || policy.Spec.TargetRefs[0].SectionName == nil ||
+		*policy.Spec.TargetRefs[0].SectionName != "https-example-com" {
+		t.Errorf("expected policy to target listener https-example-com, got %+v", policy.Spec.TargetRefs)
+	}
+	if policy.Spec.TLS == nil || policy.Spec.TLS.ClientValidation == nil ||
+		len(policy.Spec.TLS.ClientValidation.CACertificateRefs) != 1 ||
+		policy.Spec.TLS.ClientValidation.CACertificateRefs[0].Name != "client-ca" {
+		t.Errorf("expected client validation with CA client-ca, got %+v", policy.Spec.TLS)
+	}
+	if policy.Spec.Headers == nil || policy.Spec.Headers.XForwardedClientCert == nil {
+		t.Error("expected XFCC forwarding")
+	}
+
+	var grants gatewayv1beta1.ReferenceGrantList
+	if err := fakeClient.List(ctx, &grants); err != nil {
+		t.Fatalf("failed to list ReferenceGrants: %v", err)
+	}
+	var policyGrant *gatewayv1beta1.ReferenceGrant
+	for i := range grants.Items {
+		if grants.Items[i].Spec.From[0].Kind == "ClientTrafficPolicy" {
+			policyGrant = &grants.Items[i]
+		}
+	}
+	if policyGrant == nil {
+		t.Fatalf("expected a ReferenceGrant from ClientTrafficPolicy, got %+v", grants.Items)
+	}
+	if len(policyGrant.Spec.To) != 1 || *policyGrant.Spec.To[0].Name != "client-ca" {
+		t.Errorf("expected grant to Secret client-ca, got %+v", policyGrant.Spec.To)
+	}
+
+	// Remove the annotations
+	if err := fakeClient.Get(ctx, req.NamespacedName, ingress); err != nil {
+		t.Fatalf("failed to get ingress: %v", err)
+	}
+	ingress.Annotations = nil
+	if err := fakeClient.Update(ctx, ingress); err != nil {
+		t.Fatalf("failed to update ingress: %v", err)
+	}
+
+	// Second reconcile - should delete the policy and its grant
+	if err := reconcileWithListeners(ctx, r, req); err != nil {
+		t.Fatalf("unexpected error on second reconcile: %v", err)
+	}
+
+	if err := fakeClient.Get(ctx, policyKey, policy); !apierrors.IsNotFound(err) {
+		t.Errorf("expected listener ClientTrafficPolicy to be deleted, got %v", err)
+	}
+	if err := fakeClient.List(ctx, &grants); err != nil {
+		t.Fatalf("failed to list ReferenceGrants: %v", err)
+	}
+	for _, grant := range grants.Items {
+		if grant.Spec.From[0].Kind == "ClientTrafficPolicy" {
+			t.Errorf("expected ClientTrafficPolicy grant to be deleted, got %s", grant.Name)
+		}
+	}
+}
+
+func TestIngressReconciler_Reconcile_SSLPassthrough(t *testing.T) {
+	scheme := setupScheme()
+
+	gateway := testGateway()
+	ingress := tlsIngress("")
+	ingress.Spec.TLS = nil
+	ingress.Annotations = map[string]string{annotations.SSLPassthrough: "true"}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(gateway, ingress).
+		Build()
+
+	cfg := &config.Config{
+		GatewayName:      "test-gateway",
+		GatewayNamespace: "envoy-gateway",
+	}
+
+	r := &IngressReconciler{
+		Client:    fakeClient,
+		Scheme:    scheme,
+		Config:    cfg,
+		Converter: converter.New(cfg),
+	}
+
+	ctx := context.Background()
+	req := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "test-ingress",
+			Namespace: "default",
+		},
+	}
+
+	// First reconcile - should create a TLSRoute and a passthrough listener
+	if err := reconcileWithListeners(ctx, r, req); err != nil {
+		t.Fatalf("unexpected error on first reconcile: %v", err)
+	}
+
+	var tlsRoutes gatewayv1alpha2.TLSRouteList
+	if err := fakeClient.List(ctx, &tlsRoutes); err != nil {
+		t.Fatalf("failed to list TLSRoutes: %v", err)
+	}
+	if len(tlsRoutes.Items) != 1 {
+		t.Fatalf("expected 1 TLSRoute, got %d", len(tlsRoutes.Items))
+	}
+	if len(tlsRoutes.Items[0].OwnerReferences) != 1 || tlsRoutes.Items[0].OwnerReferences[0].Name != "test-ingress" {
+		t.Errorf("expected TLSRoute to be owned by the Ingress, got %+v", tlsRoutes.Items[0].OwnerReferences)
+	}
+
+	var httpRoutes gatewayv1.HTTPRouteList
+	if err := fakeClient.List(ctx, &httpRoutes); err != nil {
+		t.Fatalf("failed to list HTTPRoutes: %v", err)
+	}
+	if len(httpRoutes.Items) != 0 {
+		t.Errorf("expected no HTTPRoutes, got %d", len(httpRoutes.Items))
+	}
+
+	gatewayKey := types.NamespacedName{Name: "test-gateway", Namespace: "envoy-gateway"}
+	if err := fakeClient.Get(ctx, gatewayKey, gateway); err != nil {
+		t.Fatalf("failed to get gateway: %v", err)
+	}
+	if len(gateway.Spec.Listeners) != 2 || gateway.Spec.Listeners[1].Protocol != gatewayv1.TLSProtocolType {
+		t.Errorf("expected a TLS listener on the Gateway, got %+v", gateway.Spec.Listeners)
+	}
+
+	// Turn passthrough off
+	if err := fakeClient.Get(ctx, req.NamespacedName, ingress); err != nil {
+		t.Fatalf("failed to get ingress: %v", err)
+	}
+	ingress.Annotations = nil
+	if err := fakeClient.Update(ctx, ingress); err != nil {
+		t.Fatalf("failed to update ingress: %v", err)
+	}
+
+	// Second reconcile - should replace the TLSRoute with an HTTPRoute
+	if err := reconcileWithListeners(ctx, r, req); err != nil {
+		t.Fatalf("unexpected error on second reconcile: %v", err)
+	}
+
+	if err := fakeClient.List(ctx, &tlsRoutes); err != nil {
+		t.Fatalf("failed to list TLSRoutes: %v", err)
+	}
+	if len(tlsRoutes.Items) != 0 {
+		t.Errorf("expected 0 TLSRoutes after cleanup, got %d", len(tlsRoutes.Items))
+	}
+	if err := fakeClient.List(ctx, &httpRoutes); err != nil {
+		t.Fatalf("failed to list HTTPRoutes: %v", err)
+	}
+	if len(httpRoutes.Items) != 1 {
+		t.Errorf("expected 1 HTTPRoute, got %d", len(httpRoutes.Items))
+	}
+}
+
+func TestIngressReconciler_Reconcile_SSLPassthroughWithoutTLSRouteCRD(t *testing.T) {
+	scheme := setupScheme()
+
+	gateway := testGateway()
+	ingress := tlsIngress("")
+	ingress.Spec.TLS = nil
+	ingress.Annotations = map[string]string{annotations.SSLPassthrough: "true"}
+
+	// Without the CRD, the API server has no TLSRoute resource
+	noMatch := &meta.NoKindMatchError{GroupKind: schema.GroupKind{Group: gatewayv1alpha2.GroupName, Kind: "TLSRoute"}}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(gateway, ingress).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if _, ok := obj.(*gatewayv1alpha2.TLSRoute); ok {
+					return noMatch
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+			List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				if _, ok := list.(*gatewayv1alpha2.TLSRouteList); ok {
+					return noMatch
+				}
+				return c.List(ctx, list, opts...)
+			},
+		}).
+		Build()
+
+	cfg := &config.Config{
+		GatewayName:      "test-gateway",
+		GatewayNamespace: "envoy-gateway",
+	}
+	recorder := events.NewFakeRecorder(10)
+	r := &IngressReconciler{
+		Client:      fakeClient,
+		Scheme:      scheme,
+		Config:      cfg,
+		Converter:   converter.New(cfg),
+		Recorder:    recorder,
+		noTLSRoutes: true,
+	}
+
+	ctx := context.Background()
+	req := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "test-ingress",
+			Namespace: "default",
+		},
+	}
+
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, ReasonTLSRouteUnavailable) {
+			t.Errorf("expected a %s event, got %q", ReasonTLSRouteUnavailable, event)
+		}
+	default:
+		t.Errorf("expected a %s event", ReasonTLSRouteUnavailable)
+	}
+
+	// Deleting the Ingress does not need TLSRoutes either
+	if err := fakeClient.Delete(ctx, ingress); err != nil {
+		t.Fatalf("failed to delete ingress: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("unexpected error on deletion: %v", err)
+	}
+	if err := fakeClient.Get(ctx, req.NamespacedName, &networkingv1.Ingress{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the Ingress to be gone, got %v", err)
+	}
+}
+
+func TestIngressReconciler_Reconcile_ProxySSLVerifyOff(t *testing.T) {
+	scheme := setupScheme()
+
+	ingress := tlsIngress("")
+	ingress.Spec.TLS = nil
+	ingress.Annotations = map[string]string{
+		annotations.BackendProtocol: "HTTPS",
+		annotations.ProxySSLVerify:  "off",
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(ingress).
+		Build()
+
+	cfg := &config.Config{
+		GatewayName:      "test-gateway",
+		GatewayNamespace: "envoy-gateway",
+	}
+
+	r := &IngressReconciler{
+		Client:    fakeClient,
+		Scheme:    scheme,
+		Config:    cfg,
+		Converter: converter.New(cfg),
+	}
+
+	ctx := context.Background()
+	req := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "test-ingress",
+			Namespace: "default",
+		},
+	}
+
+	// First reconcile - should create a Backend instead of a BackendTLSPolicy
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("unexpected error on first reconcile: %v", err)
+	}
+
+	var backends egv1alpha1.BackendList
+	if err := fakeClient.List(ctx, &backends); err != nil {
+		t.Fatalf("failed to list Backends: %v", err)
+	}
+	if len(backends.Items) != 1 {
+		t.Fatalf("expected 1 Backend, got %d", len(backends.Items))
+	}
+	if len(backends.Items[0].OwnerReferences) != 1 || backends.Items[0].OwnerReferences[0].Name != "test-ingress" {
+		t.Errorf("expected Backend to be owned by the Ingress, got %+v", backends.Items[0].OwnerReferences)
+	}
+
+	var btlsList gatewayv1.BackendTLSPolicyList
+	if err := fakeClient.List(ctx, &btlsList); err != nil {
+		t.Fatalf("failed to list BackendTLSPolicies: %v", err)
+	}
+	if len(btlsList.Items) != 0 {
+		t.Errorf("expected no BackendTLSPolicies, got %d", len(btlsList.Items))
+	}
+
+	// Turn verification back on
+	if err := fakeClient.Get(ctx, req.NamespacedName, ingress); err != nil {
+		t.Fatalf("failed to get ingress: %v", err)
+	}
+	delete(ingress.Annotations, annotations.ProxySSLVerify)
+	if err := fakeClient.Update(ctx, ingress); err != nil {
+		t.Fatalf("failed to update ingress: %v", err)
+	}
+
+	// Second reconcile - should replace the Backend with a BackendTLSPolicy
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("unexpected error on second reconcile: %v", err)
+	}
+
+	if err := fakeClient.List(ctx, &backends); err != nil {
+		t.Fatalf("failed to list Backends: %v", err)
+	}
+	if len(backends.Items) != 0 {
+		t.Errorf("expected 0 Backends after cleanup, got %d", len(backends.Items))
+	}
+	if err := fakeClient.List(ctx, &btlsList); err != nil {
+		t.Fatalf("failed to list BackendTLSPolicies: %v", err)
+	}
+	if len(btlsList.Items) != 1 {
+		t.Errorf("expected 1 BackendTLSPolicy, got %d", len(btlsList.Items))
+	}
+}
+
+func TestIngressReconciler_Reconcile_ListenerSetMode(t *testing.T) {
+	scheme := setupScheme()
+
+	gateway := testGateway()
+	ingress := tlsIngress("example-tls")
+	ingress.Annotations = map[string]string{annotations.AuthTLSSecret: "shared/client-ca"}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(gateway, ingress).
+		Build()
+
+	cfg := &config.Config{
+		GatewayName:      "test-gateway",
+		GatewayNamespace: "envoy-gateway",
+		ListenerMode:     config.ListenerModeListenerSet,
+	}
+
+	r := &IngressReconciler{
+		Client:    fakeClient,
+		Scheme:    scheme,
+		Config:    cfg,
+		Converter: converter.New(cfg),
+	}
+
+	ctx := context.Background()
+	req := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "test-ingress",
+			Namespace: "default",
+		},
+	}
+
+	// First reconcile - should create an XListenerSet instead of editing the Gateway
+	if err := reconcileWithListeners(ctx, r, req); err != nil {
+		t.Fatalf("unexpected error on first reconcile: %v", err)
+	}
+
+	var listenerSets gatewayxv1alpha1.XListenerSetList
+	if err := fakeClient.List(ctx, &listenerSets); err != nil {
+		t.Fatalf("failed to list XListenerSets: %v", err)
+	}
+	if len(listenerSets.Items) != 1 {
+		t.Fatalf("expected 1 XListenerSet, got %d", len(listenerSets.Items))
+	}
+	listenerSet := listenerSets.Items[0]
+	if listenerSet.Namespace != "default" || listenerSet.Name != "test-gateway-ingress" {
+		t.Errorf("unexpected XListenerSet %s/%s", listenerSet.Namespace, listenerSet.Name)
+	}
+	if listenerSet.Spec.ParentRef.Name != "test-gateway" || listenerSet.Spec.ParentRef.Namespace == nil ||
+		*listenerSet.Spec.ParentRef.Namespace != "envoy-gateway" {
+		t.Errorf("expected parent Gateway envoy-gateway/test-gateway, got %+v", listenerSet.Spec.ParentRef)
+	}
+	if len(listenerSet.Spec.Listeners) != 1 || listenerSet.Spec.Listeners[0].Name != "https-example-com" {
+		t.Errorf("expected the https-example-com listener, got %+v", listenerSet.Spec.Listeners)
+	}
+
+	gatewayKey := types.NamespacedName{Name: "test-gateway", Namespace: "envoy-gateway"}
+	if err := fakeClient.Get(ctx, gatewayKey, gateway); err != nil {
+		t.Fatalf("failed to get gateway: %v", err)
+	}
+	if len(gateway.Spec.Listeners) != 1 {
+		t.Errorf("expected the Gateway listeners to be unchanged, got %+v", gateway.Spec.Listeners)
+	}
+
+	// The listener policy targets the XListenerSet from its namespace
+	var policies egv1alpha1.ClientTrafficPolicyList
+	if err := fakeClient.List(ctx, &policies); err != nil {
+		t.Fatalf("failed to list ClientTrafficPolicies: %v", err)
+	}
+	if len(policies.Items) != 1 {
+		t.Fatalf("expected 1 ClientTrafficPolicy, got %d", len(policies.Items))
+	}
+	policy := policies.Items[0]
+	if policy.Namespace != "default" || policy.Name != "test-gateway-default-https-example-com" {
+		t.Errorf("unexpected ClientTrafficPolicy %s/%s", policy.Namespace, policy.Name)
+	}
+	targetRef := policy.Spec.TargetRefs[0]
+	if targetRef.Kind != "XListenerSet" || targetRef.Name != "test-gateway-ingress" ||
+		targetRef.SectionName == nil || *targetRef.SectionName != "https-example-com" {
+		t.Errorf("expected policy to target the XListenerSet https-example-com section, got %+v", targetRef)
+	}
+
+	// Certificates in the namespace need no ReferenceGrant, the client CA in another one does
+	var grants gatewayv1beta1.ReferenceGrantList
+	if err := fakeClient.List(ctx, &grants); err != nil {
+		t.Fatalf("failed to list ReferenceGrants: %v", err)
+	}
+	if len(grants.Items) != 1 {
+		t.Fatalf("expected 1 ReferenceGrant, got %d", len(grants.Items))
+	}
+	grant := grants.Items[0]
+	if grant.Namespace != "shared" || grant.Spec.From[0].Kind != "ClientTrafficPolicy" ||
+		grant.Spec.From[0].Namespace != "default" || *grant.Spec.To[0].Name != "client-ca" {
+		t.Errorf("expected a grant from ClientTrafficPolicies in default to shared/client-ca, got %+v", grant)
+	}
+
+	var httpRoutes gatewayv1.HTTPRouteList
+	if err := fakeClient.List(ctx, &httpRoutes); err != nil {
+		t.Fatalf("failed to list HTTPRoutes: %v", err)
+	}
+	for _, route := range httpRoutes.Items {
+		if strings.HasSuffix(route.Name, "-ssl-redirect") {
+			continue
+		}
+		if len(route.Spec.ParentRefs) != 1 || route.Spec.ParentRefs[0].Kind == nil ||
+			*route.Spec.ParentRefs[0].Kind != "XListenerSet" {
+			t.Errorf("expected HTTPRoute %s to attach to the XListenerSet, got %+v", route.Name, route.Spec.ParentRefs)
+		}
+	}
+
+	// Remove TLS from the Ingress
+	if err := fakeClient.Get(ctx, req.NamespacedName, ingress); err != nil {
+		t.Fatalf("failed to get ingress: %v", err)
+	}
+	ingress.Spec.TLS = nil
+	if err := fakeClient.Update(ctx, ingress); err != nil {
+		t.Fatalf("failed to update ingress: %v", err)
+	}
+
+	// Second reconcile - should delete the XListenerSet
+	if err := reconcileWithListeners(ctx, r, req); err != nil {
+		t.Fatalf("unexpected error on second reconcile: %v", err)
+	}
+
+	if err := fakeClient.List(ctx, &listenerSets); err != nil {
+		t.Fatalf("failed to list XListenerSets: %v", err)
+	}
+	if len(listenerSets.Items) != 0 {
+		t.Errorf("expected 0 XListenerSets after cleanup, got %d", len(listenerSets.Items))
+	}
+	if err := fakeClient.List(ctx, &policies); err != nil {
+		t.Fatalf("failed to list ClientTrafficPolicies: %v", err)
+	}
+	if len(policies.Items) != 0 {
+		t.Errorf("expected 0 ClientTrafficPolicies after cleanup, got %d", len(policies.Items))
+	}
+	if err := fakeClient.List(ctx, &grants); err != nil {
+		t.Fatalf("failed to list ReferenceGrants: %v", err)
+	}
+	if len(grants.Items) != 0 {
+		t.Errorf("expected 0 ReferenceGrants after cleanup, got %d", len(grants.Items))
+	}
+}
+
+// testCertificate returns a PEM self-signed certificate for the hosts, valid between
+// notBefore and notAfter.
+func testCertificate(t *testing.T, hosts []string, notBefore, notAfter time.Time) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: hosts[0]},
+		DNSNames:     hosts,
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestIngressReconciler_Reconcile_ValidatesTLSSecrets(t *testing.T) {
+	now := time.Now()
+	tlsSecret := func(secretType corev1.SecretType, cert []byte) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "example-tls", Namespace: "default"},
+			Type:       secretType,
+			Data: map[string][]byte{
+				"tls.crt": cert,
+				"tls.key": []byte("key"),
+			},
+		}
+	}
+	validCert := testCertificate(t, []string{"example.com"}, now.Add(-time.Hour), now.Add(48*time.Hour))
+
+	tests := []struct {
+		name         string
+		noSecretName bool
+		secret       *corev1.Secret
+		wantReason   string
+		wantExpiry   bool
+	}{
+		{
+			name:         "no secretName and no default certificate",
+			noSecretName: true,
+			wantReason:   ReasonSecretMissing,
+		},
+		{
+			name:       "missing Secret",
+			wantReason: ReasonSecretNotFound,
+		},
+		{
+			name:       "wrong Secret type",
+			secret:     tlsSecret(corev1.SecretTypeOpaque, validCert),
+			wantReason: ReasonInvalidSecretType,
+		},
+		{
+			name:       "not a certificate",
+			secret:     tlsSecret(corev1.SecretTypeTLS, []byte("not a certificate")),
+			wantReason: ReasonInvalidCertificate,
+		},
+		{
+			name: "expired certificate",
+			secret: tlsSecret(corev1.SecretTypeTLS,
+				testCertificate(t, []string{"example.com"}, now.Add(-48*time.Hour), now.Add(-time.Hour))),
+			wantReason: ReasonCertificateExpired,
+			wantExpiry: true,
+		},
+		{
+			name: "certificate for another host",
+			secret: tlsSecret(corev1.SecretTypeTLS,
+				testCertificate(t, []string{"other.example.com"}, now.Add(-time.Hour), now.Add(48*time.Hour))),
+			wantReason: ReasonHostNotCovered,
+			wantExpiry: true,
+		},
+		{
+			name:       "valid certificate",
+			secret:     tlsSecret(corev1.SecretTypeTLS, validCert),
+			wantExpiry: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := setupScheme()
+			secretName := "example-tls"
+			if tt.noSecretName {
+				secretName = ""
+			}
+			objects := []client.Object{testGateway(), tlsIngress(secretName)}
+			if tt.secret != nil {
+				objects = append(objects, tt.secret)
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(objects...).
+				Build()
+
+			cfg := &config.Config{
+				GatewayName:      "test-gateway",
+				GatewayNamespace: "envoy-gateway",
+			}
+			recorder := events.NewFakeRecorder(10)
+			r := &IngressReconciler{
+				Client:    fakeClient,
+				Scheme:    scheme,
+				Config:    cfg,
+				Converter: converter.New(cfg),
+				Recorder:  recorder,
+			}
+
+			ctx := context.Background()
+			req := ctrl.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      "test-ingress",
+					Namespace: "default",
+				},
+			}
+
+			result, err := r.Reconcile(ctx, req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantReason == "" {
+				if len(recorder.Events) != 0 {
+					t.Errorf("expected no events, got %q", <-recorder.Events)
+				}
+				if result.RequeueAfter <= 47*time.Hour || result.RequeueAfter > 49*time.Hour {
+					t.Errorf("expected requeue at certificate expiry, got %v", result.RequeueAfter)
+				}
+			} else {
+				select {
+				case event := <-recorder.Events:
+					if !strings.HasPrefix(event, corev1.EventTypeWarning+" "+tt.wantReason+" ") ||
+						!strings.Contains(event, "example.com") {
+						t.Errorf("expected Warning %s event for example.com, got %q", tt.wantReason, event)
+					}
+				default:
+					t.Errorf("expected a %s event", tt.wantReason)
+				}
+			}
+
+			ingress := &networkingv1.Ingress{}
+			if err := fakeClient.Get(ctx, req.NamespacedName, ingress); err != nil {
+				t.Fatalf("failed to get ingress: %v", err)
+			}
+			problems, annotated := ingress.Annotations[CertificateProblemsAnnotation]
+			if tt.wantReason == "" && annotated {
+				t.Errorf("expected no certificate problems, got %q", problems)
+			}
+			if tt.wantReason != "" && (!annotated || !strings.Contains(problems, "example.com")) {
+				t.Errorf("expected certificate problems annotation, got %q", problems)
+			}
+
+			expiry := testutil.ToFloat64(certificateExpiry.WithLabelValues(
+				"default", "test-ingress", "example.com", "default/example-tls"))
+			if tt.wantExpiry && expiry == 0 {
+				t.Errorf("expected certificate expiry metric to be set")
+			}
+			if !tt.wantExpiry && expiry != 0 {
+				t.Errorf("expected no certificate expiry metric, got %v", expiry)
+			}
+
+			// Reconciling again does not repeat the events
+			if _, err := r.Reconcile(ctx, req); err != nil {
+				t.Fatalf("unexpected error on second reconcile: %v", err)
+			}
+			if len(recorder.Events) != 0 {
+				t.Errorf("expected no repeated events, got %q", <-recorder.Events)
+			}
+
+			// Fixing the problem removes the annotation
+			if tt.wantReason != "" {
+				if err := fakeClient.Get(ctx, req.NamespacedName, ingress); err != nil {
+					t.Fatalf("failed to get ingress: %v", err)
+				}
+				ingress.Spec.TLS = nil
+				if err := fakeClient.Update(ctx, ingress); err != nil {
+					t.Fatalf("failed to update ingress: %v", err)
+				}
+				if _, err := r.Reconcile(ctx, req); err != nil {
+					t.Fatalf("unexpected error after fixing the problem: %v", err)
+				}
+				if err := fakeClient.Get(ctx, req.NamespacedName, ingress); err != nil {
+					t.Fatalf("failed to get ingress: %v", err)
+				}
+				if problems, ok := ingress.Annotations[CertificateProblemsAnnotation]; ok {
+					t.Errorf("expected certificate problems annotation to be removed, got %q", problems)
+				}
+				select {
+				case event := <-recorder.Events:
+					if !strings.HasPrefix(event, corev1.EventTypeNormal+" "+ReasonCertificatesValid+" ") {
+						t.Errorf("expected Normal %s event, got %q", ReasonCertificatesValid, event)
+					}
+				default:
+					t.Errorf("expected a %s event", ReasonCertificatesValid)
+				}
+			}
+
+			certificateExpiry.Reset()
+		})
+	}
+}
+
+func TestIngressReconciler_Reconcile_ManagesCertificates(t *testing.T) {
+	scheme := setupScheme()
+	scheme.AddKnownTypeWithName(converter.CertificateGVK, &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(converter.CertificateGVK.GroupVersion().WithKind("CertificateList"),
+		&unstructured.UnstructuredList{})
+
+	ingress := tlsIngress("example-tls")
+	ingress.Annotations = map[string]string{"cert-manager.io/cluster-issuer": "letsencrypt"}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(testGateway(), ingress).
+		Build()
+
+	cfg := &config.Config{
+		GatewayName:      "test-gateway",
+		GatewayNamespace: "envoy-gateway",
+	}
+
+	r := &IngressReconciler{
+		Client:    fakeClient,
+		Scheme:    scheme,
+		Config:    cfg,
+		Converter: converter.New(cfg),
+	}
+
+	ctx := context.Background()
+	req := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "test-ingress",
+			Namespace: "default",
+		},
+	}
+	certificateKey := types.NamespacedName{Name: "example-tls", Namespace: "default"}
+
+	// First reconcile - should create the Certificate
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("unexpected error on first reconcile: %v", err)
+	}
+
+	certificate := &unstructured.Unstructured{}
+	certificate.SetGroupVersionKind(converter.CertificateGVK)
+	if err := fakeClient.Get(ctx, certificateKey, certificate); err != nil {
+		t.Fatalf("expected Certificate: %v", err)
+	}
+	issuer, _, _ := unstructured.NestedString(certificate.Object, "spec", "issuerRef", "name")
+	if issuer != "letsencrypt" {
+		t.Errorf("expected issuer letsencrypt, got %q", issuer)
+	}
+	if owners := certificate.GetOwnerReferences(); len(owners) != 1 || owners[0].Name != "test-ingress" {
+		t.Errorf("expected Certificate to be owned by the Ingress, got %+v", owners)
+	}
+
+	// Remove the issuer annotation
+	if err := fakeClient.Get(ctx, req.NamespacedName, ingress); err != nil {
+		t.Fatalf("failed to get ingress: %v", err)
+	}
+	delete(ingress.Annotations, "cert-manager.io/cluster-issuer")
+	if err := fakeClient.Update(ctx, ingress); err != nil {
+		t.Fatalf("failed to update ingress: %v", err)
+	}
+
+	// Second reconcile - should delete the Certificate
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("unexpected error on second reconcile: %v", err)
+	}
+	if err := fakeClient.Get(ctx, certificateKey, certificate); !apierrors.IsNotFound(err) {
+		t.Errorf("expected Certificate to be deleted, got %v", err)
+	}
+}
+
+func TestIngressReconciler_Reconcile_CertificatesWithoutCRD(t *testing.T) {
+	scheme := setupScheme()
+
+	ingress := tlsIngress("example-tls")
+	ingress.Annotations = map[string]string{"cert-manager.io/cluster-issuer": "letsencrypt"}
+
+	// Certificates are neither read nor written without the CRD
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(testGateway(), ingress).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				if obj.GetObjectKind().GroupVersionKind() == converter.CertificateGVK {
+					t.Errorf("unexpected Certificate read %s", key)
+				}
+				return c.Get(ctx, key, obj, opts...)
+			},
+			List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				if list.GetObjectKind().GroupVersionKind().Group == converter.CertificateGVK.Group {
+					t.Error("unexpected Certificate list")
+				}
+				return c.List(ctx, list, opts...)
+			},
+		}).
+		Build()
+
+	cfg := &config.Config{
+		GatewayName:      "test-gateway",
+		GatewayNamespace: "envoy-gateway",
+	}
+	r := &IngressReconciler{
+		Client:         fakeClient,
+		Scheme:         scheme,
+		Config:         cfg,
+		Converter:      converter.New(cfg),
+		noCertificates: true,
+	}
+
+	ctx := context.Background()
+	req := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "test-ingress",
+			Namespace: "default",
+		},
+	}
+
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var route gatewayv1.HTTPRoute
+	if err := fakeClient.Get(ctx, types.NamespacedName{Name: "test-ingress-example-com", Namespace: "default"}, &route); err != nil {
+		t.Errorf("expected HTTPRoute: %v", err)
+	}
+
+	if err := fakeClient.Delete(ctx, ingress); err != nil {
+		t.Fatalf("failed to delete ingress: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("unexpected error on deletion: %v", err)
+	}
+}
+
+func TestIngressReconciler_Reconcile_RegexRewriteFilters(t *testing.T) {
+	scheme := setupScheme()
+
+	ingress := tlsIngress("")
+	ingress.Spec.TLS = nil
+	ingress.Annotations = map[string]string{"nginx.ingress.kubernetes.io/rewrite-target": "/$2"}
+	ingress.Spec.Rules[0].HTTP.Paths[0].Path = "/data(/|$)(.*)"
+	ingress.Spec.Rules[0].HTTP.Paths[0].PathType = ptr(networkingv1.PathTypeImplementationSpecific)
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(testGateway(), ingress).
+		Build()
+
+	cfg := &config.Config{
+		GatewayName:      "test-gateway",
+		GatewayNamespace: "envoy-gateway",
+	}
+
+	r := &IngressReconciler{
+		Client:    fakeClient,
+		Scheme:    scheme,
+		Config:    cfg,
+		Converter: converter.New(cfg),
+	}
+
+	ctx := context.Background()
+	req := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "test-ingress",
+			Namespace: "default",
+		},
+	}
+	filterKey := types.NamespacedName{Name: "test-ingress-example-com-rewrite-0", Namespace: "default"}
+
+	// First reconcile - should create the HTTPRouteFilter
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("unexpected error on first reconcile: %v", err)
+	}
+
+	var filter egv1alpha1.HTTPRouteFilter
+	if err := fakeClient.Get(ctx, filterKey, &filter); err != nil {
+		t.Fatalf("expected HTTPRouteFilter: %v", err)
+	}
+	if filter.Spec.URLRewrite == nil || filter.Spec.URLRewrite.Path == nil ||
+		filter.Spec.URLRewrite.Path.ReplaceRegexMatch == nil ||
+		filter.Spec.URLRewrite.Path.ReplaceRegexMatch.Substitution != `/\2` {
+		t.Errorf("unexpected HTTPRouteFilter spec: %+v", filter.Spec)
+	}
+	if owners := filter.OwnerReferences; len(owners) != 1 || owners[0].Name != "test-ingress" {
+		t.Errorf("expected HTTPRouteFilter to be owned by the Ingress, got %+v", owners)
+	}
+
+	var route gatewayv1.HTTPRoute
+	if err := fakeClient.Get(ctx, types.NamespacedName{Name: "test-ingress-example-com", Namespace: "default"}, &route); err != nil {
+		t.Fatalf("expected HTTPRoute: %v", err)
+	}
+	if filters := route.Spec.Rules[0].Filters; len(filters) != 1 || filters[0].ExtensionRef == nil ||
+		string(filters[0].ExtensionRef.Name) != filterKey.Name {
+		t.Errorf("expected the HTTPRoute to reference %s, got %+v", filterKey.Name, filters)
+	}
+
+	// Remove the rewrite-target annotation
+	if err := fakeClient.Get(ctx, req.NamespacedName, ingress); err != nil {
+		t.Fatalf("failed to get ingress: %v", err)
+	}
+	delete(ingress.Annotations, "nginx.ingress.kubernetes.io/rewrite-target")
+	if err := fakeClient.Update(ctx, ingress); err != nil {
+		t.Fatalf("failed to update ingress: %v", err)
+	}
+
+	// Second reconcile - should delete the HTTPRouteFilter
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("unexpected error on second reconcile: %v", err)
+	}
+	if err := fakeClient.Get(ctx, filterKey, &filter); !apierrors.IsNotFound(err) {
+		t.Errorf("expected HTTPRouteFilter to be deleted, got %v", err)
+	}
+}
+
+func TestIngressReconciler_Reconcile_ProxySetHeaders(t *testing.T) {
+	scheme := setupScheme()
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "custom-headers",
+			Namespace: "default",
+		},
+		Data: map[string]string{"X-Team": "payments"},
+	}
+	ingress := tlsIngress("")
+	ingress.Spec.TLS = nil
+	ingress.Annotations = map[string]string{"nginx.ingress.kubernetes.io/proxy-set-headers": "default/custom-headers"}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(testGateway(), ingress, configMap).
+		Build()
+
+	cfg := &config.Config{
+		GatewayName:      "test-gateway",
+		GatewayNamespace: "envoy-gateway",
+	}
+
+	r := &IngressReconciler{
+		Client: fakeClient,
+		Scheme: scheme,
+		Config: cfg,
+		Converter: converter.NewWithResolvers(cfg, converter.Resolvers{
+			ConfigMaps: converter.NewConfigMapResolver(fakeClient),
+		}),
+	}
+
+	ctx := context.Background()
+	req := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "test-ingress",
+			Namespace: "default",
+		},
+	}
+	routeKey := types.NamespacedName{Name: "test-ingress-example-com", Namespace: "default"}
+
+	headerValue := func() string {
+		t.Helper()
+		var route gatewayv1.HTTPRoute
+		if err := fakeClient.Get(ctx, routeKey, &route); err != nil {
+			t.Fatalf("expected HTTPRoute: %v", err)
+		}
+		for _, filter := range route.Spec.Rules[0].Filters {
+			if filter.RequestHeaderModifier != nil {
+				for _, header := range filter.RequestHeaderModifier.Set {
+					if header.Name == "X-Team" {
+						return header.Value
+					}
+				}
+			}
+		}
+		return ""
+	}
+
+	// First reconcile - should set the header from the ConfigMap
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("unexpected error on first reconcile: %v", err)
+	}
+	if got := headerValue(); got != "payments" {
+		t.Errorf("expected X-Team header payments, got %q", got)
+	}
+
+	// Change the ConfigMap
+	configMap.Data["X-Team"] = "billing"
+	if err := fakeClient.Update(ctx, configMap); err != nil {
+		t.Fatalf("failed to update configmap: %v", err)
+	}
+	requests := r.findIngressesForConfigMap(ctx, &metav1.PartialObjectMetadata{ObjectMeta: configMap.ObjectMeta})
+	if len(requests) != 1 || requests[0].NamespacedName != req.NamespacedName {
+		t.Fatalf("expected ConfigMap change to enqueue the Ingress, got %v", requests)
+	}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("unexpected error on second reconcile: %v", err)
+	}
+	if got := headerValue(); got != "billing" {
+		t.Errorf("expected X-Team header billing, got %q", got)
+	}
+
+	// Other ConfigMaps do not enqueue the Ingress
+	other := configMap.DeepCopy()
+	other.Name = "other-headers"
+	if requests := r.findIngressesForConfigMap(ctx, other); len(requests) != 0 {
+		t.Errorf("expected other ConfigMaps not to enqueue the Ingress, got %v", requests)
+	}
+}
+
+func TestIngressReconciler_Reconcile_HostWideRegex(t *testing.T) {
+	scheme := setupScheme()
+
+	plain := tlsIngress("")
+	plain.Spec.TLS = nil
+	regex := tlsIngress("")
+	regex.Name = "regex-ingress"
+	regex.Spec.TLS = nil
+	regex.Annotations = map[string]string{"nginx.ingress.kubernetes.io/use-regex": "true"}
+	regex.Spec.Rules[0].HTTP.Paths[0].Path = "/api/v[0-9]+"
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(testGateway(), plain, regex).
+		Build()
+
+	cfg := &config.Config{
+		GatewayName:      "test-gateway",
+		GatewayNamespace: "envoy-gateway",
+	}
+
+	r := &IngressReconciler{
+		Client: fakeClient,
+		Scheme: scheme,
+		Config: cfg,
+		Converter: converter.NewWithResolvers(cfg, converter.Resolvers{
+			Ingresses: converter.NewIngressResolver(fakeClient),
+		}),
+	}
+
+	ctx := context.Background()
+	req := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "test-ingress",
+			Namespace: "default",
+		},
+	}
+	routeKey := types.NamespacedName{Name: "test-ingress-example-com", Namespace: "default"}
+
+	// The plain Ingress shares example.com with a use-regex Ingress
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("unexpected error on first reconcile: %v", err)
+	}
+	var route gatewayv1.HTTPRoute
+	if err := fakeClient.Get(ctx, routeKey, &route); err != nil {
+		t.Fatalf("expected HTTPRoute: %v", err)
+	}
+	match := route.Spec.Rules[0].Matches[0].Path
+	if *match.Type != gatewayv1.PathMatchRegularExpression || *match.Value != "(?i)/.*" {
+		t.Errorf("expected case-insensitive regex match, got %s %s", *match.Type, *match.Value)
+	}
+
+	// Changing the use-regex Ingress re-reconciles the plain one
+	requests := r.findIngressesSharingHosts(ctx, regex)
+	if len(requests) != 1 || requests[0].NamespacedName != req.NamespacedName {
+		t.Fatalf("expected the sibling Ingress to be enqueued, got %v", requests)
+	}
+
+	if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(regex), regex); err != nil {
+		t.Fatalf("failed to get ingress: %v", err)
+	}
+	delete(regex.Annotations, "nginx.ingress.kubernetes.io/use-regex")
+	if err := fakeClient.Update(ctx, regex); err != nil {
+		t.Fatalf("failed to update ingress: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("unexpected error on second reconcile: %v", err)
+	}
+	if err := fakeClient.Get(ctx, routeKey, &route); err != nil {
+		t.Fatalf("expected HTTPRoute: %v", err)
+	}
+	match = route.Spec.Rules[0].Matches[0].Path
+	if *match.Type != gatewayv1.PathMatchPathPrefix || *match.Value != "/" {
+		t.Errorf("expected prefix match once no Ingress uses regexes, got %s %s", *match.Type, *match.Value)
+	}
+}
+
+func TestIngressReconciler_Reconcile_Canary(t *testing.T) {
+	scheme := setupScheme()
+
+	primary := tlsIngress("")
+	primary.Spec.TLS = nil
+	canary := tlsIngress("")
+	canary.Name = "canary-ingress"
+	canary.UID = types.UID("canary-uid")
+	canary.Spec.TLS = nil
+	canary.Annotations = map[string]string{
+		"nginx.ingress.kubernetes.io/canary":        "true",
+		"nginx.ingress.kubernetes.io/canary-weight": "25",
+	}
+	canary.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Name = "web-canary"
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(testGateway(), primary, canary).
+		Build()
+
+	cfg := &config.Config{
+		GatewayName:      "test-gateway",
+		GatewayNamespace: "envoy-gateway",
+	}
+
+	r := &IngressReconciler{
+		Client: fakeClient,
+		Scheme: scheme,
+		Config: cfg,
+		Converter: converter.NewWithResolvers(cfg, converter.Resolvers{
+			Ingresses: converter.NewIngressResolver(fakeClient),
+		}),
+	}
+
+	ctx := context.Background()
+	primaryReq := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(primary)}
+	canaryReq := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(canary)}
+
+	// The canary Ingress gets no HTTPRoute of its own
+	if _, err := r.Reconcile(ctx, canaryReq); err != nil {
+		t.Fatalf("unexpected error reconciling the canary: %v", err)
+	}
+	var routes gatewayv1.HTTPRouteList
+	if err := fakeClient.List(ctx, &routes); err != nil {
+		t.Fatalf("failed to list HTTPRoutes: %v", err)
+	}
+	if len(routes.Items) != 0 {
+		t.Fatalf("expected no HTTPRoutes for the canary, got %d", len(routes.Items))
+	}
+
+	// The primary HTTPRoute splits traffic with the canary backend
+	if _, err := r.Reconcile(ctx, primaryReq); err != nil {
+		t.Fatalf("unexpected error reconciling the primary: %v", err)
+	}
+	routeKey := types.NamespacedName{Name: "test-ingress-example-com", Namespace: "default"}
+	var route gatewayv1.HTTPRoute
+	if err := fakeClient.Get(ctx, routeKey, &route); err != nil {
+		t.Fatalf("expected HTTPRoute: %v", err)
+	}
+	refs := route.Spec.Rules[0].BackendRefs
+	if len(refs) != 2 || refs[0].Name != "web-service" || *refs[0].Weight != 75 ||
+		refs[1].Name != "web-canary" || *refs[1].Weight != 25 {
+		t.Fatalf("expected a 75/25 split with the canary, got %+v", refs)
+	}
+
+	// Changing the canary re-reconciles the primary
+	requests := r.findIngressesSharingHosts(ctx, canary)
+	if len(requests) != 1 || requests[0] != primaryReq {
+		t.Fatalf("expected the primary Ingress to be enqueued, got %v", requests)
+	}
+
+	if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(canary), canary); err != nil {
+		t.Fatalf("failed to get ingress: %v", err)
+	}
+	canary.Annotations["nginx.ingress.kubernetes.io/canary-weight"] = "0"
+	if err := fakeClient.Update(ctx, canary); err != nil {
+		t.Fatalf("failed to update ingress: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, primaryReq); err != nil {
+		t.Fatalf("unexpected error on second reconcile: %v", err)
+	}
+	if err := fakeClient.Get(ctx, routeKey, &route); err != nil {
+		t.Fatalf("expected HTTPRoute: %v", err)
+	}
+	if refs := route.Spec.Rules[0].BackendRefs; len(refs) != 1 || refs[0].Weight != nil {
+		t.Errorf("expected only the primary backend once the canary weight is 0, got %+v", refs)
+	}
+}
+
+func TestIngressReconciler_AdvanceCanaryRollout(t *testing.T) {
+	scheme := setupScheme()
+
+	canary := tlsIngress("")
+	canary.Name = "canary-ingress"
+	canary.Annotations = map[string]string{
+		"nginx.ingress.kubernetes.io/canary":  "true",
+		"ingress-gateway-api.io/canary-steps": "10:5m,50:10m,100",
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(canary).
+		Build()
+
+	cfg := &config.Config{
+		GatewayName:      "test-gateway",
+		GatewayNamespace: "envoy-gateway",
+	}
+	recorder := events.NewFakeRecorder(10)
+	r := &IngressReconciler{
+		Client:    fakeClient,
+		Scheme:    scheme,
+		Config:    cfg,
+		Converter: converter.New(cfg),
+		Recorder:  recorder,
+	}
+
+	ctx := context.Background()
+	start := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		name        string
+		now         time.Time
+		annotate    map[string]string // annotations changed before the step, "" deletes
+		wantStep    string
+		wantStarted string
+		wantDelay   time.Duration
+		wantReason  string
+	}{
+		{
+			name:        "starts at the first step",
+			now:         start,
+			wantStep:    "1",
+			wantStarted: "2026-10-16T12:00:00Z",
+			wantDelay:   5 * time.Minute,
+			wantReason:  ReasonCanaryStepStarted,
+		},
+		{
+			name:        "waits for the step to end",
+			now:         start.Add(2 * time.Minute),
+			wantStep:    "1",
+			wantStarted: "2026-10-16T12:00:00Z",
+			wantDelay:   3 * time.Minute,
+		},
+		{
+			name:        "moves to the next step",
+			now:         start.Add(5 * time.Minute),
+			wantStep:    "2",
+			wantStarted: "2026-10-16T12:05:00Z",
+			wantDelay:   10 * time.Minute,
+			wantReason:  ReasonCanaryStepStarted,
+		},
+		{
+			name:     "pause holds the step",
+			now:      start.Add(20 * time.Minute),
+			annotate: map[string]string{"ingress-gateway-api.io/canary-pause": "true"},
+			wantStep: "2",
+		},
+		{
+			name:        "resuming restarts the step",
+			now:         start.Add(30 * time.Minute),
+			annotate:    map[string]string{"ingress-gateway-api.io/canary-pause": ""},
+			wantStep:    "2",
+			wantStarted: "2026-10-16T12:30:00Z",
+			wantDelay:   10 * time.Minute,
+		},
+		{
+			name:        "completes at the last step",
+			now:         start.Add(40 * time.Minute),
+			wantStep:    "3",
+			wantStarted: "2026-10-16T12:40:00Z",
+			wantReason:  ReasonCanaryRolloutComplete,
+		},
+		{
+			name:       "abort clears the rollout",
+			now:        start.Add(50 * time.Minute),
+			annotate:   map[string]string{"ingress-gateway-api.io/canary-abort": "true"},
+			wantReason: ReasonCanaryRolloutAborted,
+		},
+	}
+
+	for _, step := range steps {
+		ingress := &networkingv1.Ingress{}
+		if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(canary), ingress); err != nil {
+			t.Fatalf("%s: failed to get ingress: %v", step.name, err)
+		}
+		if step.annotate != nil {
+			for key, value := range step.annotate {
+				if value == "" {
+					delete(ingress.Annotations, key)
+				} else {
+					ingress.Annotations[key] = value
+				}
+			}
+			if err := fakeClient.Update(ctx, ingress); err != nil {
+				t.Fatalf("%s: failed to update ingress: %v", step.name, err)
+			}
+		}
+
+		delay, err := r.advanceCanaryRollout(ctx, ingress, step.now)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", step.name, err)
+		}
+		if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(canary), ingress); err != nil {
+			t.Fatalf("%s: failed to get ingress: %v", step.name, err)
+		}
+
+		if got := ingress.Annotations["ingress-gateway-api.io/canary-step"]; got != step.wantStep {
+			t.Errorf("%s: expected step %q, got %q", step.name, step.wantStep, got)
+		}
+		if got := ingress.Annotations["ingress-gateway-api.io/canary-step-started"]; got != step.wantStarted {
+			t.Errorf("%s: expected step start %q, got %q", step.name, step.wantStarted, got)
+		}
+		if delay != step.wantDelay {
+			t.Errorf("%s: expected requeue after %v, got %v", step.name, step.wantDelay, delay)
+		}
+
+		select {
+		case event := <-recorder.Events:
+			if step.wantReason == "" || !strings.Contains(event, " "+step.wantReason+" ") {
+				t.Errorf("%s: unexpected event %q", step.name, event)
+			}
+		default:
+			if step.wantReason != "" {
+				t.Errorf("%s: expected a %s event", step.name, step.wantReason)
+			}
+		}
+	}
+}
+
+// startRedisStandIn answers each connection with the given reply, like a Redis answering
+// a PING, and returns its address.
+func startRedisStandIn(t *testing.T, reply string) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			buf := make([]byte, 64)
+			_, _ = conn.Read(buf)
+			_, _ = conn.Write([]byte(reply))
+			_ = conn.Close()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestCheckGlobalRateLimit(t *testing.T) {
+	envoyGatewayConfig := func(rateLimit string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "envoy-gateway-config", Namespace: "envoy-gateway-system"},
+			Data: map[string]string{
+				"envoy-gateway.yaml": "apiVersion: gateway.envoyproxy.io/v1alpha1\n" +
+					"kind: EnvoyGateway\n" +
+					"provider:\n  type: Kubernetes\n" + rateLimit,
+			},
+		}
+	}
+	redisConfig := func(url string) string {
+		return "rateLimit:\n  backend:\n    type: Redis\n    redis:\n      url: " + url + "\n"
+	}
+
+	// A closed listener gives an address nothing answers on
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	unreachable := closed.Addr().String()
+	_ = closed.Close()
+
+	tests := []struct {
+		name        string
+		configMap   *corev1.ConfigMap
+		wantEnabled bool
+		wantErr     bool
+	}{
+		{
+			name:        "enabled",
+			configMap:   envoyGatewayConfig(redisConfig(startRedisStandIn(t, "+PONG\r\n"))),
+			wantEnabled: true,
+		},
+		{
+			name:        "Redis requires authentication",
+			configMap:   envoyGatewayConfig(redisConfig(startRedisStandIn(t, "-NOAUTH Authentication required.\r\n"))),
+			wantEnabled: true,
+		},
+		{
+			name:        "Redis does not answer",
+			configMap:   envoyGatewayConfig(redisConfig(unreachable)),
+			wantEnabled: true,
+			wantErr:     true,
+		},
+		{
+			name:      "not enabled",
+			configMap: envoyGatewayConfig(""),
+		},
+		{
+			name:    "no config",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().WithScheme(setupScheme())
+			if tt.configMap != nil {
+				builder = builder.WithObjects(tt.configMap)
+			}
+
+			enabled, err := CheckGlobalRateLimit(context.Background(), builder.Build(),
+				"envoy-gateway-system/envoy-gateway-config")
+
+			if enabled != tt.wantEnabled {
+				t.Errorf("expected enabled %v, got %v", tt.wantEnabled, enabled)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,615 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	egv1alpha1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/werdnum/ingress-gateway-api/internal/config"
+	"github.com/werdnum/ingress-gateway-api/internal/converter"
+)
+
+// ManagedListenersAnnotation records which listeners on the shared Gateway were generated
+// by this controller, so they can be removed once no Ingress needs them.
+const ManagedListenersAnnotation = "ingress-gateway-api.io/managed-listeners"
+
+// ReasonTooManyListeners is the reason of the Event recorded on the shared Gateway when
+// generated listeners are skipped because the Gateway or an XListenerSet is full.
+const ReasonTooManyListeners = "TooManyListeners"
+
+// maxListeners is the most listeners a Gateway or an XListenerSet accepts.
+const maxListeners = 64
+
+// reconcileListeners is the reconcile function of the controller that keeps the
+// generated listeners of the shared Gateway up to date. Every Ingress change maps to the
+// same request, so a burst of changes is handled by a single pass over all Ingresses,
+// and a failure here never holds up the routes of an Ingress.
+func (r *IngressReconciler) reconcileListeners(ctx context.Context, _ reconcile.Request) (reconcile.Result, error) {
+	return handleReconcileError(r.reconcileGatewayListeners(ctx))
+}
+
+// reconcileGatewayListeners ensures the shared Gateway has exactly the generated listeners
+// required by all Ingresses currently processed by this controller.
+// Listeners that were not generated by the controller are left untouched.
+// In ListenerSet mode the generated listeners go to per-namespace XListenerSets instead,
+// and only listeners previously generated on the Gateway are removed from it.
+func (r *IngressReconciler) reconcileGatewayListeners(ctx context.Context) error {
+	logger := log.FromContext(ctx)
+
+	gateway := &gatewayv1.Gateway{}
+	if err := r.Get(ctx, client.ObjectKey{
+		Namespace: r.Config.GatewayNamespace,
+		Name:      r.Config.GatewayName,
+	}, gateway); err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.V(1).Info("Gateway not found, skipping listener reconciliation")
+			return nil
+		}
+		return err
+	}
+
+	desired, listenerSets, desiredPolicies, err := r.desiredListeners(ctx)
+	if err != nil {
+		return err
+	}
+	if len(listenerSets) > 0 && !allowsListenerSets(gateway) {
+		logger.Info("Gateway does not allow ListenerSets in spec.allowedListeners, generated listeners will not be accepted",
+			"name", gateway.Name)
+	}
+
+	managed := managedListenerNames(gateway)
+
+	// Keep listeners owned by someone else, and never generate a listener that would
+	// clash with one of them by name or by port and hostname.
+	var listeners []gatewayv1.Listener
+	for _, listener := range gateway.Spec.Listeners {
+		if _, ok := managed[string(listener.Name)]; !ok {
+			listeners = append(listeners, listener)
+		}
+	}
+	unmanaged := len(listeners)
+
+	// The API server rejects a Gateway or XListenerSet with more than maxListeners
+	// listeners, so skip the generated listeners that do not fit, last in name order,
+	// rather than fail to update any of them.
+	var managedNames, skipped []string
+	for _, listener := range desired {
+		if conflict := findConflictingListener(listeners[:unmanaged], listener); conflict != "" {
+			logger.Info("Generated listener conflicts with existing Gateway listener, skipping",
+				"listener", listener.Name, "existing", conflict)
+			continue
+		}
+		if len(listeners) >= maxListeners {
+			skipped = append(skipped, string(listener.Name))
+			continue
+		}
+		listeners = append(listeners, listener)
+		managedNames = append(managedNames, string(listener.Name))
+	}
+	for namespace, entries := range listenerSets {
+		if len(entries) <= maxListeners {
+			continue
+		}
+		for _, listener := range entries[maxListeners:] {
+			skipped = append(skipped, fmt.Sprintf("%s/%s", namespace, listener.Name))
+		}
+		listenerSets[namespace] = entries[:maxListeners]
+	}
+	if len(skipped) > 0 {
+		sort.Strings(skipped)
+		logger.Info("Too many listeners, skipping generated listeners",
+			"name", gateway.Name, "limit", maxListeners, "skipped", skipped)
+		if r.Recorder != nil {
+			r.Recorder.Eventf(gateway, nil, corev1.EventTypeWarning, ReasonTooManyListeners, "ReconcileListeners",
+				"A Gateway or XListenerSet accepts at most %d listeners; skipped generated listeners %s",
+				maxListeners, strings.Join(skipped, ", "))
+		}
+	}
+
+	// Only configure listeners that the controller actually manages. Listener policies are
+	// in the namespace of the Gateway or XListenerSet holding their listener.
+	sections := make(map[types.NamespacedName]struct{})
+	for _, name := range managedNames {
+		sections[types.NamespacedName{Namespace: r.Config.GatewayNamespace, Name: name}] = struct{}{}
+	}
+	for namespace, entries := range listenerSets {
+		for _, listener := range entries {
+			sections[types.NamespacedName{Namespace: namespace, Name: string(listener.Name)}] = struct{}{}
+		}
+	}
+	var policies, gatewayPolicies, listenerSetPolicies []*egv1alpha1.ClientTrafficPolicy
+	for _, policy := range desiredPolicies {
+		section := types.NamespacedName{Namespace: policy.Namespace, Name: string(*policy.Spec.TargetRefs[0].SectionName)}
+		if _, ok := sections[section]; !ok {
+			continue
+		}
+		policies = append(policies, policy)
+		if policy.Spec.TargetRefs[0].Kind == "Gateway" {
+			gatewayPolicies = append(gatewayPolicies, policy)
+		} else {
+			listenerSetPolicies = append(listenerSetPolicies, policy)
+		}
+	}
+
+	// Allow the Gateway and its listener policies to use the Secrets they reference
+	if err := r.exposeListenerSecrets(ctx, listeners[unmanaged:], gatewayPolicies); err != nil {
+		return err
+	}
+
+	if err := r.reconcileListenerPolicies(ctx, policies); err != nil {
+		return err
+	}
+
+	if err := r.reconcileListenerSets(ctx, listenerSets, listenerSetPolicies); err != nil {
+		return err
+	}
+
+	managedValue := strings.Join(managedNames, ",")
+	if equality.Semantic.DeepEqual(gateway.Spec.Listeners, listeners) &&
+		gateway.Annotations[ManagedListenersAnnotation] == managedValue {
+		return nil
+	}
+
+	gateway.Spec.Listeners = listeners
+	if gateway.Annotations == nil {
+		gateway.Annotations = make(map[string]string)
+	}
+	if managedValue == "" {
+		delete(gateway.Annotations, ManagedListenersAnnotation)
+	} else {
+		gateway.Annotations[ManagedListenersAnnotation] = managedValue
+	}
+
+	if err := r.Update(ctx, gateway); err != nil {
+		if apierrors.IsInvalid(err) || apierrors.IsBadRequest(err) {
+			logger.Error(err, "Invalid Gateway listeners, will retry with longer delay", "name", gateway.Name)
+			return newPermanentError(err)
+		}
+		return err
+	}
+	logger.Info("Updated Gateway listeners", "name", gateway.Name, "managedListeners", len(managedNames))
+	return nil
+}
+
+// desiredListeners collects the generated listeners and listener policies of every
+// processed Ingress. Ingresses sharing a TLS hostname share its listener: the first
+// Ingress in namespace/name order provides the certificate and the listener policy,
+// and route attachment is allowed from every namespace that asked for the hostname.
+// In ListenerSet mode the listeners are returned per namespace instead, and are only
+// shared between Ingresses in the same namespace. The hosts served by all Ingresses are
+// collected once for the pass rather than looked up per Ingress.
+func (r *IngressReconciler) desiredListeners(ctx context.Context) (
+	[]gatewayv1.Listener,
+	map[string][]gatewayv1.Listener,
+	[]*egv1alpha1.ClientTrafficPolicy,
+	error,
+) {
+	logger := log.FromContext(ctx)
+
+	var ingresses networkingv1.IngressList
+	if err := r.List(ctx, &ingresses); err != nil {
+		return nil, nil, nil, err
+	}
+	sort.Slice(ingresses.Items, func(i, j int) bool {
+		a, b := ingresses.Items[i], ingresses.Items[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+
+	served := r.Converter.ServedHosts(ingresses.Items)
+	useListenerSets := r.Config.ListenerMode == config.ListenerModeListenerSet
+	gatewayListeners := newListenerMerge()
+	namespaceListeners := make(map[string]*listenerMerge)
+	policiesByName := make(map[types.NamespacedName]*egv1alpha1.ClientTrafficPolicy)
+	var policyNames []types.NamespacedName
+	for i := range ingresses.Items {
+		ingress := &ingresses.Items[i]
+		if !r.shouldProcess(ingress) || !ingress.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		merge := gatewayListeners
+		if useListenerSets {
+			if namespaceListeners[ingress.Namespace] == nil {
+				namespaceListeners[ingress.Namespace] = newListenerMerge()
+			}
+			merge = namespaceListeners[ingress.Namespace]
+		}
+
+		generated := r.Converter.GenerateListeners(ctx, ingress, served)
+		for _, listener := range generated {
+			existing, ok := merge.add(listener)
+			if !ok {
+				continue
+			}
+
+			if !equality.Semantic.DeepEqual(existing.TLS, listener.TLS) {
+				logger.Info("Ingress TLS conflicts with another Ingress for the same host, using the first certificate",
+					"ingress", fmt.Sprintf("%s/%s", ingress.Namespace, ingress.Name),
+					"listener", listener.Name)
+			}
+			mergeAllowedNamespaces(existing, ingress.Namespace)
+		}
+
+		for _, policy := range r.Converter.GenerateListenerPolicies(ingress, generated) {
+			key := client.ObjectKeyFromObject(policy)
+			existing, ok := policiesByName[key]
+			if !ok {
+				policiesByName[key] = policy
+				policyNames = append(policyNames, key)
+				continue
+			}
+
+			if !equality.Semantic.DeepEqual(existing.Spec, policy.Spec) {
+				logger.Info("Ingress listener settings conflict with another Ingress for the same host, using the first",
+					"ingress", fmt.Sprintf("%s/%s", ingress.Namespace, ingress.Name),
+					"policy", policy.Name)
+			}
+		}
+	}
+
+	listenerSets := make(map[string][]gatewayv1.Listener, len(namespaceListeners))
+	for namespace, merge := range namespaceListeners {
+		if listeners := merge.sorted(); len(listeners) > 0 {
+			listenerSets[namespace] = listeners
+		}
+	}
+
+	sort.Slice(policyNames, func(i, j int) bool {
+		return policyNames[i].String() < policyNames[j].String()
+	})
+	policies := make([]*egv1alpha1.ClientTrafficPolicy, 0, len(policyNames))
+	for _, name := range policyNames {
+		policies = append(policies, policiesByName[name])
+	}
+	return gatewayListeners.sorted(), listenerSets, policies, nil
+}
+
+// listenerMerge collects generated listeners by name.
+type listenerMerge struct {
+	byName map[gatewayv1.SectionName]*gatewayv1.Listener
+	names  []gatewayv1.SectionName
+}
+
+func newListenerMerge() *listenerMerge {
+	return &listenerMerge{byName: make(map[gatewayv1.SectionName]*gatewayv1.Listener)}
+}
+
+// add adds a listener unless one with the same name exists, in which case it returns
+// the existing listener and true.
+func (m *listenerMerge) add(listener gatewayv1.Listener) (*gatewayv1.Listener, bool) {
+	if existing, ok := m.byName[listener.Name]; ok {
+		return existing, true
+	}
+	m.byName[listener.Name] = &listener
+	m.names = append(m.names, listener.Name)
+	return nil, false
+}
+
+// sorted returns the collected listeners ordered by name.
+func (m *listenerMerge) sorted() []gatewayv1.Listener {
+	names := slices.Clone(m.names)
+	slices.Sort(names)
+	listeners := make([]gatewayv1.Listener, 0, len(names))
+	for _, name := range names {
+		listeners = append(listeners, *m.byName[name])
+	}
+	return listeners
+}
+
+// reconcileListenerPolicies creates and updates the desired listener ClientTrafficPolicies
+// and deletes those that are no longer needed. They are in the Gateway namespace, or in
+// ListenerSet mode in the namespaces of the XListenerSets.
+// Policies that the controller did not create are never overwritten.
+func (r *IngressReconciler) reconcileListenerPolicies(ctx context.Context, policies []*egv1alpha1.ClientTrafficPolicy) error {
+	logger := log.FromContext(ctx)
+	sourceRef := r.gatewaySourceRef()
+
+	expected := make(map[types.NamespacedName]struct{}, len(policies))
+	for _, policy := range policies {
+		expected[client.ObjectKeyFromObject(policy)] = struct{}{}
+		policy.Annotations = map[string]string{SourceAnnotation: sourceRef}
+
+		existing := &egv1alpha1.ClientTrafficPolicy{}
+		err := r.Get(ctx, client.ObjectKeyFromObject(policy), existing)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				if err := r.Create(ctx, policy); err != nil {
+					if apierrors.IsInvalid(err) || apierrors.IsBadRequest(err) {
+						logger.Error(err, "Invalid listener ClientTrafficPolicy, will retry with longer delay", "name", policy.Name)
+						return newPermanentError(err)
+					}
+					return err
+				}
+				logger.Info("Created listener ClientTrafficPolicy", "name", policy.Name, "namespace", policy.Namespace)
+				continue
+			}
+			return err
+		}
+
+		if existing.Annotations[SourceAnnotation] != sourceRef {
+			logger.Info("Listener ClientTrafficPolicy exists and is not managed by the controller, skipping",
+				"name", policy.Name, "namespace", policy.Namespace)
+			continue
+		}
+		if equality.Semantic.DeepEqual(existing.Spec, policy.Spec) {
+			continue
+		}
+
+		existing.Spec = policy.Spec
+		if err := r.Update(ctx, existing); err != nil {
+			if apierrors.IsInvalid(err) || apierrors.IsBadRequest(err) {
+				logger.Error(err, "Invalid listener ClientTrafficPolicy update, will retry with longer delay", "name", policy.Name)
+				return newPermanentError(err)
+			}
+			return err
+		}
+		logger.Info("Updated listener ClientTrafficPolicy", "name", policy.Name, "namespace", policy.Namespace)
+	}
+
+	// Delete policies for listeners that no longer need them
+	var existingPolicies egv1alpha1.ClientTrafficPolicyList
+	if err := r.List(ctx, &existingPolicies); err != nil {
+		return err
+	}
+	for _, policy := range existingPolicies.Items {
+		if policy.Annotations[SourceAnnotation] != sourceRef {
+			continue
+		}
+		if _, ok := expected[client.ObjectKeyFromObject(&policy)]; ok {
+			continue
+		}
+		if err := r.Delete(ctx, &policy); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		logger.Info("Deleted stale listener ClientTrafficPolicy", "name", policy.Name, "namespace", policy.Namespace)
+	}
+
+	return nil
+}
+
+// mergeAllowedNamespaces adds a namespace to a generated listener's route namespace selector.
+func mergeAllowedNamespaces(listener *gatewayv1.Listener, namespace string) {
+	if listener.AllowedRoutes == nil || listener.AllowedRoutes.Namespaces == nil ||
+		listener.AllowedRoutes.Namespaces.Selector == nil {
+		return
+	}
+	selector := listener.AllowedRoutes.Namespaces.Selector
+	for i := range selector.MatchExpressions {
+		expr := &selector.MatchExpressions[i]
+		if !slices.Contains(expr.Values, namespace) {
+			expr.Values = append(expr.Values, namespace)
+			sort.Strings(expr.Values)
+		}
+	}
+}
+
+// managedListenerNames returns the names of listeners previously generated by the controller.
+func managedListenerNames(gateway *gatewayv1.Gateway) map[string]struct{} {
+	names := make(map[string]struct{})
+	value := gateway.Annotations[ManagedListenersAnnotation]
+	if value == "" {
+		return names
+	}
+	for _, name := range strings.Split(value, ",") {
+		names[name] = struct{}{}
+	}
+	return names
+}
+
+// findConflictingListener returns the name of an existing listener that clashes with
+// the candidate, either by name or by serving the same port, protocol and hostname.
+func findConflictingListener(existing []gatewayv1.Listener, candidate gatewayv1.Listener) gatewayv1.SectionName {
+	for _, listener := range existing {
+		if listener.Name == candidate.Name {
+			return listener.Name
+		}
+		if listener.Port == candidate.Port && listener.Protocol == candidate.Protocol &&
+			equality.Semantic.DeepEqual(listener.Hostname, candidate.Hostname) {
+			return listener.Name
+		}
+	}
+	return ""
+}
+
+// gatewaySourceRef is the source annotation value for resources that belong to the shared
+// Gateway as a whole rather than to a single Ingress.
+func (r *IngressReconciler) gatewaySourceRef() string {
+	return fmt.Sprintf("Gateway/%s/%s", r.Config.GatewayNamespace, r.Config.GatewayName)
+}
+
+// secretReferenceGrants builds one ReferenceGrant per namespace that allows objects of
+// the given kind in the from namespace to use exactly the referenced Secrets.
+func (r *IngressReconciler) secretReferenceGrants(
+	name string,
+	from gatewayv1beta1.ReferenceGrantFrom,
+	refs []*gatewayv1.SecretObjectReference,
+) []*gatewayv1beta1.ReferenceGrant {
+	secretsByNamespace := make(map[string]map[string]struct{})
+	for _, ref := range refs {
+		if ref.Namespace == nil || *ref.Namespace == from.Namespace {
+			continue
+		}
+		ns := string(*ref.Namespace)
+		if secretsByNamespace[ns] == nil {
+			secretsByNamespace[ns] = make(map[string]struct{})
+		}
+		secretsByNamespace[ns][string(ref.Name)] = struct{}{}
+	}
+
+	grants := make([]*gatewayv1beta1.ReferenceGrant, 0, len(secretsByNamespace))
+	for ns, secrets := range secretsByNamespace {
+		names := make([]string, 0, len(secrets))
+		for name := range secrets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		to := make([]gatewayv1beta1.ReferenceGrantTo, 0, len(names))
+		for _, name := range names {
+			secretName := gatewayv1.ObjectName(name)
+			to = append(to, gatewayv1beta1.ReferenceGrantTo{
+				Group: "",
+				Kind:  "Secret",
+				Name:  &secretName,
+			})
+		}
+
+		grants = append(grants, &gatewayv1beta1.ReferenceGrant{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: ns,
+				Annotations: map[string]string{
+					SourceAnnotation: r.gatewaySourceRef(),
+				},
+			},
+			Spec: gatewayv1beta1.ReferenceGrantSpec{
+				From: []gatewayv1beta1.ReferenceGrantFrom{from},
+				To:   to,
+			},
+		})
+	}
+
+	return grants
+}
+
+// sharedGatewayRequest maps an event to the listener reconcile request of the shared Gateway.
+func (r *IngressReconciler) sharedGatewayRequest(context.Context, client.Object) []reconcile.Request {
+	return []reconcile.Request{{
+		NamespacedName: types.NamespacedName{Namespace: r.Config.GatewayNamespace, Name: r.Config.GatewayName},
+	}}
+}
+
+// findGatewayForSecret maps a Secret used by an Ingress to the listener reconcile
+// request of the shared Gateway, so that copies of rotated certificates are refreshed.
+func (r *IngressReconciler) findGatewayForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	if len(r.findIngressesForSecret(ctx, obj)) == 0 {
+		return nil
+	}
+	return r.sharedGatewayRequest(ctx, obj)
+}
+
+// isSharedGateway reports whether an object is the shared Gateway.
+func (r *IngressReconciler) isSharedGateway(obj client.Object) bool {
+	return obj.GetNamespace() == r.Config.GatewayNamespace && obj.GetName() == r.Config.GatewayName
+}
+
+// gatewayListenersHandler requeues the Ingresses whose HTTPRoutes may attach to other
+// sections after a change to the shared Gateway: those with a host accepted by a
+// listener that was added, removed or changed.
+func (r *IngressReconciler) gatewayListenersHandler() handler.EventHandler {
+	enqueue := func(ctx context.Context, oldObj, newObj client.Object, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+		var before, after []gatewayv1.Listener
+		if gateway, ok := oldObj.(*gatewayv1.Gateway); ok {
+			before = gateway.Spec.Listeners
+		}
+		if gateway, ok := newObj.(*gatewayv1.Gateway); ok {
+			after = gateway.Spec.Listeners
+		}
+		for _, request := range r.findIngressesForListeners(ctx, changedListeners(before, after)) {
+			q.Add(request)
+		}
+	}
+	return handler.Funcs{
+		CreateFunc: func(ctx context.Context, e event.CreateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, nil, e.Object, q)
+		},
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, e.ObjectOld, e.ObjectNew, q)
+		},
+		DeleteFunc: func(ctx context.Context, e event.DeleteEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, e.Object, nil, q)
+		},
+	}
+}
+
+// changedListeners returns the listeners that only one of two listener lists has, or
+// that differ between them, matching listeners by name.
+func changedListeners(before, after []gatewayv1.Listener) []gatewayv1.Listener {
+	var changed []gatewayv1.Listener
+	for _, listener := range before {
+		if other := findListener(after, listener.Name); other == nil || !equality.Semantic.DeepEqual(*other, listener) {
+			changed = append(changed, listener)
+		}
+	}
+	for _, listener := range after {
+		if findListener(before, listener.Name) == nil {
+			changed = append(changed, listener)
+		}
+	}
+	return changed
+}
+
+// findListener returns the listener with the given name, or nil.
+func findListener(listeners []gatewayv1.Listener, name gatewayv1.SectionName) *gatewayv1.Listener {
+	for i := range listeners {
+		if listeners[i].Name == name {
+			return &listeners[i]
+		}
+	}
+	return nil
+}
+
+// findIngressesForListeners returns the processed Ingresses with a rule host, or a www
+// counterpart of one, that one of the listeners accepts.
+func (r *IngressReconciler) findIngressesForListeners(ctx context.Context, listeners []gatewayv1.Listener) []reconcile.Request {
+	if len(listeners) == 0 {
+		return nil
+	}
+
+	var ingresses networkingv1.IngressList
+	if err := r.List(ctx, &ingresses); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list Ingresses for Gateway listeners")
+		return nil
+	}
+
+	accepted := func(host string) bool {
+		return slices.ContainsFunc(listeners, func(listener gatewayv1.Listener) bool {
+			return listener.Hostname == nil || converter.HostnameMatches(string(*listener.Hostname), host)
+		})
+	}
+
+	var requests []reconcile.Request
+	for i := range ingresses.Items {
+		ingress := &ingresses.Items[i]
+		if !r.shouldProcess(ingress) {
+			continue
+		}
+		matches := slices.ContainsFunc(ingress.Spec.Rules, func(rule networkingv1.IngressRule) bool {
+			if rule.Host == "" {
+				return false
+			}
+			counterpart := "www." + rule.Host
+			if trimmed, ok := strings.CutPrefix(rule.Host, "www."); ok {
+				counterpart = trimmed
+			}
+			return accepted(rule.Host) || accepted(counterpart)
+		})
+		if matches {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name},
+			})
+		}
+	}
+	return requests
+}
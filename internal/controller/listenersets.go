@@ -0,0 +1,193 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	egv1alpha1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+	gatewayxv1alpha1 "sigs.k8s.io/gateway-api/apisx/v1alpha1"
+
+	"github.com/werdnum/ingress-gateway-api/internal/converter"
+)
+
+// reconcileListenerSets ensures each namespace with generated listeners has an XListenerSet
+// attached to the shared Gateway, and deletes the XListenerSets no longer needed.
+// Certificates in the namespace need no ReferenceGrant; the default certificate does, and
+// so do client CA certificates the listener policies reference in other namespaces.
+func (r *IngressReconciler) reconcileListenerSets(
+	ctx context.Context,
+	listenerSets map[string][]gatewayv1.Listener,
+	policies []*egv1alpha1.ClientTrafficPolicy,
+) error {
+	logger := log.FromContext(ctx)
+	sourceRef := r.gatewaySourceRef()
+	name := converter.ListenerSetName(r.Config.GatewayName)
+
+	namespaces := make([]string, 0, len(listenerSets))
+	for namespace := range listenerSets {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	var grants []*gatewayv1beta1.ReferenceGrant
+	for _, namespace := range namespaces {
+		listeners := listenerSets[namespace]
+		grants = append(grants, r.secretReferenceGrants(
+			fmt.Sprintf("%s-%s", namespace, name),
+			gatewayv1beta1.ReferenceGrantFrom{
+				Group:     gatewayv1.Group(gatewayxv1alpha1.GroupName),
+				Kind:      "XListenerSet",
+				Namespace: gatewayv1.Namespace(namespace),
+			},
+			listenerSecretRefs(listeners))...)
+
+		if err := r.reconcileListenerSet(ctx, r.desiredListenerSet(namespace, listeners)); err != nil {
+			return err
+		}
+
+		var namespacePolicies []*egv1alpha1.ClientTrafficPolicy
+		for _, policy := range policies {
+			if policy.Namespace == namespace {
+				namespacePolicies = append(namespacePolicies, policy)
+			}
+		}
+		grants = append(grants, r.secretReferenceGrants(
+			fmt.Sprintf("%s-%s-client-tls", namespace, name),
+			gatewayv1beta1.ReferenceGrantFrom{
+				Group:     gatewayv1.Group(egv1alpha1.GroupName),
+				Kind:      egv1alpha1.KindClientTrafficPolicy,
+				Namespace: gatewayv1.Namespace(namespace),
+			},
+			policySecretRefs(namespacePolicies))...)
+	}
+
+	if err := r.reconcileReferenceGrants(ctx, r.listenerSetSourceRef(), grants); err != nil {
+		return err
+	}
+
+	// Delete XListenerSets in namespaces that no longer have generated listeners. Outside
+	// ListenerSet mode the XListenerSet CRD may not be installed at all.
+	var existing gatewayxv1alpha1.XListenerSetList
+	if err := r.List(ctx, &existing); err != nil {
+		if meta.IsNoMatchError(err) && len(listenerSets) == 0 {
+			return nil
+		}
+		return err
+	}
+	for _, listenerSet := range existing.Items {
+		if listenerSet.Annotations[SourceAnnotation] != sourceRef {
+			continue
+		}
+		if _, ok := listenerSets[listenerSet.Namespace]; ok && listenerSet.Name == name {
+			continue
+		}
+		if err := r.Delete(ctx, &listenerSet); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		logger.Info("Deleted stale XListenerSet", "name", listenerSet.Name, "namespace", listenerSet.Namespace)
+	}
+
+	return nil
+}
+
+// desiredListenerSet builds the XListenerSet holding a namespace's generated listeners.
+func (r *IngressReconciler) desiredListenerSet(namespace string, listeners []gatewayv1.Listener) *gatewayxv1alpha1.XListenerSet {
+	entries := make([]gatewayxv1alpha1.ListenerEntry, 0, len(listeners))
+	for _, listener := range listeners {
+		entries = append(entries, gatewayxv1alpha1.ListenerEntry{
+			Name:          listener.Name,
+			Hostname:      listener.Hostname,
+			Port:          listener.Port,
+			Protocol:      listener.Protocol,
+			TLS:           listener.TLS,
+			AllowedRoutes: listener.AllowedRoutes,
+		})
+	}
+
+	group := gatewayv1.Group(gatewayv1.GroupName)
+	kind := gatewayv1.Kind("Gateway")
+	gatewayNamespace := gatewayv1.Namespace(r.Config.GatewayNamespace)
+	return &gatewayxv1alpha1.XListenerSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      converter.ListenerSetName(r.Config.GatewayName),
+			Namespace: namespace,
+			Annotations: map[string]string{
+				SourceAnnotation: r.gatewaySourceRef(),
+			},
+		},
+		Spec: gatewayxv1alpha1.ListenerSetSpec{
+			ParentRef: gatewayxv1alpha1.ParentGatewayReference{
+				Group:     &group,
+				Kind:      &kind,
+				Name:      gatewayv1.ObjectName(r.Config.GatewayName),
+				Namespace: &gatewayNamespace,
+			},
+			Listeners: entries,
+		},
+	}
+}
+
+// reconcileListenerSet creates or updates an XListenerSet.
+// XListenerSets that the controller did not create are never overwritten.
+func (r *IngressReconciler) reconcileListenerSet(ctx context.Context, listenerSet *gatewayxv1alpha1.XListenerSet) error {
+	logger := log.FromContext(ctx)
+
+	existing := &gatewayxv1alpha1.XListenerSet{}
+	err := r.Get(ctx, client.ObjectKeyFromObject(listenerSet), existing)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			if err := r.Create(ctx, listenerSet); err != nil {
+				if apierrors.IsInvalid(err) || apierrors.IsBadRequest(err) {
+					logger.Error(err, "Invalid XListenerSet, will retry with longer delay", "name", listenerSet.Name)
+					return newPermanentError(err)
+				}
+				return err
+			}
+			logger.Info("Created XListenerSet", "name", listenerSet.Name, "namespace", listenerSet.Namespace)
+			return nil
+		}
+		return err
+	}
+
+	if existing.Annotations[SourceAnnotation] != r.gatewaySourceRef() {
+		logger.Info("XListenerSet exists and is not managed by the controller, skipping",
+			"name", listenerSet.Name, "namespace", listenerSet.Namespace)
+		return nil
+	}
+	if equality.Semantic.DeepEqual(existing.Spec, listenerSet.Spec) {
+		return nil
+	}
+
+	existing.Spec = listenerSet.Spec
+	if err := r.Update(ctx, existing); err != nil {
+		if apierrors.IsInvalid(err) || apierrors.IsBadRequest(err) {
+			logger.Error(err, "Invalid XListenerSet update, will retry with longer delay", "name", listenerSet.Name)
+			return newPermanentError(err)
+		}
+		return err
+	}
+	logger.Info("Updated XListenerSet", "name", listenerSet.Name, "namespace", listenerSet.Namespace)
+	return nil
+}
+
+// listenerSetSourceRef is the source annotation value for ReferenceGrants that let the
+// generated XListenerSets use the default certificate.
+func (r *IngressReconciler) listenerSetSourceRef() string {
+	return fmt.Sprintf("XListenerSet/%s/%s", r.Config.GatewayNamespace, r.Config.GatewayName)
+}
+
+// allowsListenerSets reports whether the Gateway accepts ListenerSets from any namespace.
+func allowsListenerSets(gateway *gatewayv1.Gateway) bool {
+	allowed := gateway.Spec.AllowedListeners
+	return allowed != nil && allowed.Namespaces != nil && allowed.Namespaces.From != nil &&
+		*allowed.Namespaces.From != gatewayv1.NamespacesFromNone
+}
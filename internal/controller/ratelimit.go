@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	egv1alpha1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// envoyGatewayConfigKey is the ConfigMap key holding the Envoy Gateway configuration.
+const envoyGatewayConfigKey = "envoy-gateway.yaml"
+
+// redisPingTimeout bounds the check that the rate limit Redis answers.
+const redisPingTimeout = 5 * time.Second
+
+// CheckGlobalRateLimit reads the Envoy Gateway configuration in the namespace/name
+// ConfigMap and returns whether global rate limiting is enabled. When it is, the Redis of
+// the rate limit service is sent a PING, and an error is returned if it does not answer;
+// Envoy Gateway still accepts global rate limits then, but cannot enforce them.
+func CheckGlobalRateLimit(ctx context.Context, reader client.Reader, configMap string) (bool, error) {
+	namespace, name, ok := strings.Cut(configMap, "/")
+	if !ok {
+		return false, fmt.Errorf("invalid Envoy Gateway config %q, expected namespace/name", configMap)
+	}
+
+	var cm corev1.ConfigMap
+	if err := reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &cm); err != nil {
+		return false, fmt.Errorf("reading Envoy Gateway config %s: %w", configMap, err)
+	}
+	var eg egv1alpha1.EnvoyGateway
+	if err := yaml.Unmarshal([]byte(cm.Data[envoyGatewayConfigKey]), &eg); err != nil {
+		return false, fmt.Errorf("parsing %s of Envoy Gateway config %s: %w", envoyGatewayConfigKey, configMap, err)
+	}
+
+	if eg.RateLimit == nil {
+		return false, nil
+	}
+	redis := eg.RateLimit.Backend.Redis
+	if eg.RateLimit.Backend.Type != egv1alpha1.RedisBackendType || redis == nil || redis.URL == "" {
+		return false, fmt.Errorf("no rate limit Redis in Envoy Gateway config %s", configMap)
+	}
+	if redis.TLS != nil {
+		// Answering a PING over TLS needs the client certificate of the rate limit service
+		return true, nil
+	}
+	return true, pingRedis(ctx, redis.URL)
+}
+
+// pingRedis sends a PING to the first address of a Redis URL, which lists one address
+// or, for Sentinel and Cluster deployments, several separated by commas. Any reply,
+// including an error such as NOAUTH, shows that Redis is there.
+func pingRedis(ctx context.Context, url string) error {
+	var addr string
+	for _, entry := range strings.Split(url, ",") {
+		if _, _, err := net.SplitHostPort(strings.TrimSpace(entry)); err == nil {
+			addr = strings.TrimSpace(entry)
+			break
+		}
+	}
+	if addr == "" {
+		return fmt.Errorf("rate limit Redis URL %q has no host:port address", url)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, redisPingTimeout)
+	defer cancel()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("connecting to rate limit Redis %s: %w", addr, err)
+	}
+	defer func() { _ = conn.Close() }()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if _, err := conn.Write([]byte("*1\r\n$4\r\nPING\r\n")); err != nil {
+		return fmt.Errorf("sending PING to rate limit Redis %s: %w", addr, err)
+	}
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("reading PING reply of rate limit Redis %s: %w", addr, err)
+	}
+	if !strings.HasPrefix(reply, "+") && !strings.HasPrefix(reply, "-") {
+		return fmt.Errorf("rate limit Redis %s replied %q to PING", addr, strings.TrimSpace(reply))
+	}
+	return nil
+}
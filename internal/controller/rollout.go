@@ -0,0 +1,137 @@
+package controller
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/werdnum/ingress-gateway-api/internal/annotations"
+)
+
+// Event reasons for progressive canary rollouts.
+const (
+	ReasonCanaryStepStarted     = "CanaryStepStarted"
+	ReasonCanaryRolloutComplete = "CanaryRolloutComplete"
+	ReasonCanaryRolloutAborted  = "CanaryRolloutAborted"
+	ReasonInvalidCanarySteps    = "InvalidCanarySteps"
+)
+
+// advanceCanaryRollout moves a canary Ingress with canary-steps through its rollout. The
+// current step and its start are recorded in the canary-step and canary-step-started
+// annotations; the primary Ingress converts the step into the canary weight, and is
+// reconciled again when they change. A paused step has no start and restarts its full
+// duration when resumed. It returns the time left in the current step, or 0 when the
+// rollout does not move on its own.
+func (r *IngressReconciler) advanceCanaryRollout(
+	ctx context.Context,
+	ingress *networkingv1.Ingress,
+	now time.Time,
+) (time.Duration, error) {
+	annots := annotations.NewAnnotationSet(ingress.Annotations)
+	steps, ok, err := annots.GetCanarySteps()
+	step, recorded := annots.GetCanaryStep()
+
+	switch {
+	case !annots.IsCanary() || !ok:
+		return 0, r.recordCanaryStep(ctx, ingress, 0, time.Time{})
+	case err != nil:
+		log.FromContext(ctx).Info("Invalid canary steps", "error", err.Error())
+		r.canaryEventf(ingress, corev1.EventTypeWarning, ReasonInvalidCanarySteps, "canary-steps is ignored: %v", err)
+		return 0, nil
+	case annots.IsCanaryAborted():
+		if recorded {
+			r.canaryEventf(ingress, corev1.EventTypeWarning, ReasonCanaryRolloutAborted,
+				"Canary rollout aborted at step %d of %d, every request goes to the primary", step, len(steps))
+		}
+		return 0, r.recordCanaryStep(ctx, ingress, 0, time.Time{})
+	}
+
+	step = min(max(step, 1), len(steps))
+	if annots.IsCanaryPaused() {
+		return 0, r.recordCanaryStep(ctx, ingress, step, time.Time{})
+	}
+
+	started, err := time.Parse(time.RFC3339, ingress.Annotations[annotations.CanaryStepStarted])
+	switch {
+	case !recorded:
+		started = now
+		r.canaryStepEvent(ingress, steps, step)
+	case err != nil:
+		// Resumed after a pause
+		started = now
+	case step < len(steps) && !now.Before(started.Add(steps[step-1].Duration)):
+		step, started = step+1, now
+		r.canaryStepEvent(ingress, steps, step)
+	}
+
+	if err := r.recordCanaryStep(ctx, ingress, step, started); err != nil {
+		return 0, err
+	}
+	if step == len(steps) {
+		return 0, nil
+	}
+	return started.Add(steps[step-1].Duration).Sub(now), nil
+}
+
+// recordCanaryStep sets the canary-step annotations of the Ingress, removing both for
+// step 0 and canary-step-started for a zero start.
+func (r *IngressReconciler) recordCanaryStep(
+	ctx context.Context,
+	ingress *networkingv1.Ingress,
+	step int,
+	started time.Time,
+) error {
+	desired := make(map[string]string)
+	if step > 0 {
+		desired[annotations.CanaryStep] = strconv.Itoa(step)
+		if !started.IsZero() {
+			desired[annotations.CanaryStepStarted] = started.UTC().Format(time.RFC3339)
+		}
+	}
+
+	changed := false
+	for _, key := range []string{annotations.CanaryStep, annotations.CanaryStepStarted} {
+		current, annotated := ingress.Annotations[key]
+		value, wanted := desired[key]
+		changed = changed || annotated != wanted || current != value
+	}
+	if !changed {
+		return nil
+	}
+
+	patch := client.MergeFrom(ingress.DeepCopy())
+	if ingress.Annotations == nil {
+		ingress.Annotations = make(map[string]string)
+	}
+	for _, key := range []string{annotations.CanaryStep, annotations.CanaryStepStarted} {
+		if value, ok := desired[key]; ok {
+			ingress.Annotations[key] = value
+		} else {
+			delete(ingress.Annotations, key)
+		}
+	}
+	return r.Patch(ctx, ingress, patch)
+}
+
+// canaryStepEvent reports the step a rollout moved to.
+func (r *IngressReconciler) canaryStepEvent(ingress *networkingv1.Ingress, steps []annotations.CanaryRolloutStep, step int) {
+	if step == len(steps) {
+		r.canaryEventf(ingress, corev1.EventTypeNormal, ReasonCanaryRolloutComplete,
+			"Canary rollout reached its last step, canary weight %d", steps[step-1].Weight)
+		return
+	}
+	r.canaryEventf(ingress, corev1.EventTypeNormal, ReasonCanaryStepStarted,
+		"Canary weight %d for %s (step %d of %d)", steps[step-1].Weight, steps[step-1].Duration, step, len(steps))
+}
+
+// canaryEventf emits an event for the canary Ingress, if events are recorded.
+func (r *IngressReconciler) canaryEventf(ingress *networkingv1.Ingress, eventType, reason, note string, args ...any) {
+	if r.Recorder != nil {
+		r.Recorder.Eventf(ingress, nil, eventType, reason, "RolloutCanary", note, args...)
+	}
+}
@@ -0,0 +1,262 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	egv1alpha1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/werdnum/ingress-gateway-api/internal/annotations"
+	"github.com/werdnum/ingress-gateway-api/internal/config"
+)
+
+// SourceSecretAnnotation records the namespace/name of the Secret a copy was made from.
+const SourceSecretAnnotation = "ingress-gateway-api.io/source-secret"
+
+// CopiedSecretName returns the name of the copy of a Secret in the Gateway namespace.
+// Namespace names cannot contain dots, so the name is unique for every source Secret.
+func CopiedSecretName(namespace, name string) string {
+	return fmt.Sprintf("%s.%s", namespace, name)
+}
+
+// exposeListenerSecrets makes the Secrets referenced by generated listeners and listener
+// policies usable from the Gateway namespace, according to the configured TLS Secret mode.
+// In copy mode the references are rewritten to point at the copies.
+func (r *IngressReconciler) exposeListenerSecrets(
+	ctx context.Context,
+	listeners []gatewayv1.Listener,
+	policies []*egv1alpha1.ClientTrafficPolicy,
+) error {
+	certificateRefs := listenerSecretRefs(listeners)
+	caCertificateRefs := policySecretRefs(policies)
+
+	if r.Config.TLSSecretMode == config.TLSSecretModeCopy {
+		copies := r.rewriteToSecretCopies(append(certificateRefs, caCertificateRefs...))
+		if err := r.reconcileReferenceGrants(ctx, r.gatewaySourceRef(), nil); err != nil {
+			return err
+		}
+		return r.reconcileSecretCopies(ctx, copies)
+	}
+
+	if err := r.reconcileSecretCopies(ctx, nil); err != nil {
+		return err
+	}
+	grantName := fmt.Sprintf("gateway-%s-%s", r.Config.GatewayNamespace, r.Config.GatewayName)
+	grants := r.secretReferenceGrants(grantName, gatewayv1beta1.ReferenceGrantFrom{
+		Group:     gatewayv1.Group("gateway.networking.k8s.io"),
+		Kind:      "Gateway",
+		Namespace: gatewayv1.Namespace(r.Config.GatewayNamespace),
+	}, certificateRefs)
+	grants = append(grants, r.secretReferenceGrants(grantName+"-client-tls", gatewayv1beta1.ReferenceGrantFrom{
+		Group:     gatewayv1.Group(egv1alpha1.GroupName),
+		Kind:      egv1alpha1.KindClientTrafficPolicy,
+		Namespace: gatewayv1.Namespace(r.Config.GatewayNamespace),
+	}, caCertificateRefs)...)
+	return r.reconcileReferenceGrants(ctx, r.gatewaySourceRef(), grants)
+}
+
+// listenerSecretRefs returns pointers to the certificateRefs of the listeners.
+func listenerSecretRefs(listeners []gatewayv1.Listener) []*gatewayv1.SecretObjectReference {
+	var refs []*gatewayv1.SecretObjectReference
+	for i := range listeners {
+		if listeners[i].TLS == nil {
+			continue
+		}
+		for j := range listeners[i].TLS.CertificateRefs {
+			refs = append(refs, &listeners[i].TLS.CertificateRefs[j])
+		}
+	}
+	return refs
+}
+
+// policySecretRefs returns pointers to the client CA certificateRefs of the policies.
+func policySecretRefs(policies []*egv1alpha1.ClientTrafficPolicy) []*gatewayv1.SecretObjectReference {
+	var refs []*gatewayv1.SecretObjectReference
+	for _, policy := range policies {
+		if policy.Spec.TLS == nil || policy.Spec.TLS.ClientValidation == nil {
+			continue
+		}
+		validation := policy.Spec.TLS.ClientValidation
+		for i := range validation.CACertificateRefs {
+			refs = append(refs, &validation.CACertificateRefs[i])
+		}
+	}
+	return refs
+}
+
+// rewriteToSecretCopies points Secret references outside the Gateway namespace at their
+// copies, and returns the source Secret for each copy name.
+func (r *IngressReconciler) rewriteToSecretCopies(refs []*gatewayv1.SecretObjectReference) map[string]types.NamespacedName {
+	copies := make(map[string]types.NamespacedName)
+	for _, ref := range refs {
+		if ref.Namespace == nil || string(*ref.Namespace) == r.Config.GatewayNamespace {
+			continue
+		}
+
+		source := types.NamespacedName{Namespace: string(*ref.Namespace), Name: string(ref.Name)}
+		copyName := CopiedSecretName(source.Namespace, source.Name)
+		copies[copyName] = source
+
+		gatewayNamespace := gatewayv1.Namespace(r.Config.GatewayNamespace)
+		ref.Namespace = &gatewayNamespace
+		ref.Name = gatewayv1.ObjectName(copyName)
+	}
+	return copies
+}
+
+// reconcileSecretCopies creates and updates the desired Secret copies in the Gateway
+// namespace and deletes copies that are no longer referenced.
+func (r *IngressReconciler) reconcileSecretCopies(ctx context.Context, copies map[string]types.NamespacedName) error {
+	logger := log.FromContext(ctx)
+
+	for copyName, source := range copies {
+		if err := r.reconcileSecretCopy(ctx, copyName, source); err != nil {
+			return err
+		}
+	}
+
+	// Delete copies that no listener references any more
+	var secrets corev1.SecretList
+	if err := r.List(ctx, &secrets, client.InNamespace(r.Config.GatewayNamespace)); err != nil {
+		return err
+	}
+	for _, secret := range secrets.Items {
+		if secret.Annotations[SourceAnnotation] != r.gatewaySourceRef() {
+			continue
+		}
+		if _, ok := copies[secret.Name]; ok {
+			continue
+		}
+		if err := r.Delete(ctx, &secret); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		logger.Info("Deleted stale Secret copy", "name", secret.Name)
+	}
+
+	return nil
+}
+
+// reconcileSecretCopy creates or updates a single copy of a source Secret.
+func (r *IngressReconciler) reconcileSecretCopy(ctx context.Context, copyName string, source types.NamespacedName) error {
+	logger := log.FromContext(ctx)
+
+	sourceSecret := &corev1.Secret{}
+	if err := r.Get(ctx, source, sourceSecret); err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Info("TLS Secret not found, not copying", "secret", source.String())
+			return nil
+		}
+		return err
+	}
+
+	desired := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      copyName,
+			Namespace: r.Config.GatewayNamespace,
+			Annotations: map[string]string{
+				SourceAnnotation:       r.gatewaySourceRef(),
+				SourceSecretAnnotation: source.String(),
+			},
+		},
+		Type: sourceSecret.Type,
+		Data: sourceSecret.Data,
+	}
+
+	existing := &corev1.Secret{}
+	err := r.Get(ctx, client.ObjectKeyFromObject(desired), existing)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			if err := r.Create(ctx, desired); err != nil {
+				return err
+			}
+			logger.Info("Created Secret copy", "name", copyName, "source", source.String())
+			return nil
+		}
+		return err
+	}
+
+	// Never overwrite a Secret that the controller did not create
+	if existing.Annotations[SourceAnnotation] != r.gatewaySourceRef() {
+		logger.Info("Secret exists in Gateway namespace and is not managed by the controller, not copying",
+			"name", copyName, "source", source.String())
+		return nil
+	}
+
+	if equality.Semantic.DeepEqual(existing.Data, desired.Data) &&
+		existing.Annotations[SourceSecretAnnotation] == source.String() {
+		return nil
+	}
+
+	existing.Data = desired.Data
+	existing.Annotations[SourceSecretAnnotation] = source.String()
+	if err := r.Update(ctx, existing); err != nil {
+		return err
+	}
+	logger.Info("Updated Secret copy", "name", copyName, "source", source.String())
+	return nil
+}
+
+// findIngressesForSecret maps a Secret event to the Ingresses whose TLS or client
+// certificate authentication uses it, so that rotated certificates are copied again.
+// Events on a copy map back to its source.
+func (r *IngressReconciler) findIngressesForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	source := types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+	if obj.GetAnnotations()[SourceAnnotation] == r.gatewaySourceRef() {
+		ns, name, ok := splitSourceRef(obj.GetAnnotations()[SourceSecretAnnotation])
+		if !ok {
+			return nil
+		}
+		source = types.NamespacedName{Namespace: ns, Name: name}
+	}
+
+	var ingresses networkingv1.IngressList
+	if err := r.List(ctx, &ingresses, client.InNamespace(source.Namespace)); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list Ingresses for Secret", "secret", source.String())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, ingress := range ingresses.Items {
+		if ingressUsesSecret(&ingress, source) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name},
+			})
+		}
+	}
+	return requests
+}
+
+// ingressUsesSecret reports whether an Ingress references a Secret in its own namespace
+// for TLS or as the auth-tls-secret CA.
+func ingressUsesSecret(ingress *networkingv1.Ingress, secret types.NamespacedName) bool {
+	for _, tls := range ingress.Spec.TLS {
+		if tls.SecretName == secret.Name {
+			return true
+		}
+	}
+
+	authTLSSecret := ingress.Annotations[annotations.AuthTLSSecret]
+	ns, name, ok := strings.Cut(authTLSSecret, "/")
+	if !ok {
+		ns, name = ingress.Namespace, authTLSSecret
+	}
+	return ns == secret.Namespace && name == secret.Name
+}
+
+// splitSourceRef splits a namespace/name reference.
+func splitSourceRef(ref string) (string, string, bool) {
+	ns, name, ok := strings.Cut(ref, "/")
+	return ns, name, ok && ns != "" && name != ""
+}
@@ -0,0 +1,157 @@
+package converter
+
+import (
+	"fmt"
+
+	egv1alpha1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/werdnum/ingress-gateway-api/internal/annotations"
+	"github.com/werdnum/ingress-gateway-api/internal/config"
+)
+
+// defaultSessionCookieName is the ingress-nginx default for session-cookie-name.
+const defaultSessionCookieName = "INGRESSCOOKIE"
+
+// usesSessionPersistence returns true if cookie affinity becomes HTTPRoute session
+// persistence, and false if it becomes a consistent hash on the cookie.
+func (c *Converter) usesSessionPersistence(annots annotations.AnnotationSet) bool {
+	switch c.cfg.SessionAffinity {
+	case config.SessionAffinitySessionPersistence:
+		return true
+	case config.SessionAffinityConsistentHash:
+		return false
+	default:
+		return annots.IsPersistentAffinity()
+	}
+}
+
+// usesCookieHash returns true if the BackendTrafficPolicy hashes on the session cookie.
+func (c *Converter) usesCookieHash(annots annotations.AnnotationSet) bool {
+	return annots.HasCookieAffinity() && !c.usesSessionPersistence(annots)
+}
+
+// addSessionPersistence keeps the sessions of each rule with backends on one endpoint,
+// with the session cookie Envoy sets on the path of the rule.
+func (c *Converter) addSessionPersistence(httpRoute *gatewayv1.HTTPRoute, annots annotations.AnnotationSet) {
+	if !annots.HasCookieAffinity() || !c.usesSessionPersistence(annots) {
+		return
+	}
+
+	persistence := gatewayv1.SessionPersistence{
+		SessionName:  ptr(sessionCookieName(annots)),
+		Type:         ptr(gatewayv1.CookieBasedSessionPersistence),
+		CookieConfig: &gatewayv1.CookieConfig{LifetimeType: ptr(gatewayv1.SessionCookieLifetimeType)},
+	}
+	if ttl, ok := sessionCookieTTL(annots); ok {
+		persistence.AbsoluteTimeout = ttl
+		persistence.CookieConfig.LifetimeType = ptr(gatewayv1.PermanentCookieLifetimeType)
+	}
+
+	for i := range httpRoute.Spec.Rules {
+		if len(httpRoute.Spec.Rules[i].BackendRefs) > 0 {
+			httpRoute.Spec.Rules[i].SessionPersistence = persistence.DeepCopy()
+		}
+	}
+}
+
+// buildCookieHash creates a consistent hash on the session cookie, which Envoy sets when
+// a request has none. Without an expiry the cookie lasts for the browser session. The
+// cookie path defaults to /, as the hash applies to every path of the route.
+func buildCookieHash(annots annotations.AnnotationSet) *egv1alpha1.LoadBalancer {
+	cookie := &egv1alpha1.Cookie{
+		Name: sessionCookieName(annots),
+		TTL:  ptr(gatewayv1.Duration("0s")),
+		Attributes: map[string]string{
+			"Path": "/",
+		},
+	}
+	if ttl, ok := sessionCookieTTL(annots); ok {
+		cookie.TTL = ttl
+	}
+	if path, ok := annots.GetString(annotations.SessionCookiePath); ok && path != "" {
+		cookie.Attributes["Path"] = path
+	}
+	if sameSite, ok := annots.GetString(annotations.SessionCookieSameSite); ok && sameSite != "" {
+		cookie.Attributes["SameSite"] = sameSite
+	}
+	if domain, ok := annots.GetString(annotations.SessionCookieDomain); ok && domain != "" {
+		cookie.Attributes["Domain"] = domain
+	}
+	if secure, ok := annots.GetBool(annotations.SessionCookieSecure); ok && secure {
+		cookie.Attributes["Secure"] = ""
+	}
+
+	return &egv1alpha1.LoadBalancer{
+		Type: egv1alpha1.ConsistentHashLoadBalancerType,
+		ConsistentHash: &egv1alpha1.ConsistentHash{
+			Type:   egv1alpha1.CookieConsistentHashType,
+			Cookie: cookie,
+		},
+	}
+}
+
+// sessionAffinityWarnings reports the session affinity annotations that cannot be
+// converted as is.
+func (c *Converter) sessionAffinityWarnings(annots annotations.AnnotationSet) []string {
+	affinity, ok := annots.GetString(annotations.Affinity)
+	if !ok {
+		return nil
+	}
+	if !annots.HasCookieAffinity() {
+		return []string{fmt.Sprintf("%s %q is not supported, only cookie", annotations.Affinity, affinity)}
+	}
+
+	var warnings []string
+	if _, ok := annots.GetString(annotations.UpstreamHashBy); ok {
+		warnings = append(warnings, fmt.Sprintf("%s is ignored with cookie affinity", annotations.UpstreamHashBy))
+	}
+
+	mode, explicit := annots.GetString(annotations.AffinityMode)
+	if !c.usesSessionPersistence(annots) {
+		if annots.IsPersistentAffinity() {
+			warnings = append(warnings, fmt.Sprintf(
+				"%s persistent is converted to a consistent hash, which moves some sessions when endpoints change",
+				annotations.AffinityMode))
+		}
+		return warnings
+	}
+
+	warnings = append(warnings, fmt.Sprintf(
+		"%s cookie is converted to HTTPRoute session persistence, which needs the experimental Gateway API CRDs; "+
+			"the standard channel CRDs drop it and sessions are not kept",
+		annotations.Affinity))
+	if explicit && mode == "balanced" {
+		warnings = append(warnings, fmt.Sprintf(
+			"%s balanced is converted to session persistence, which keeps sessions on their endpoint when endpoints are added",
+			annotations.AffinityMode))
+	}
+	for _, key := range []string{
+		annotations.SessionCookiePath,
+		annotations.SessionCookieSameSite,
+		annotations.SessionCookieSecure,
+		annotations.SessionCookieDomain,
+	} {
+		if _, ok := annots.GetString(key); ok {
+			warnings = append(warnings, fmt.Sprintf("%s is not supported with session persistence and is ignored", key))
+		}
+	}
+	return warnings
+}
+
+// sessionCookieName returns session-cookie-name, or the ingress-nginx default.
+func sessionCookieName(annots annotations.AnnotationSet) string {
+	if name, ok := annots.GetString(annotations.SessionCookieName); ok && name != "" {
+		return name
+	}
+	return defaultSessionCookieName
+}
+
+// sessionCookieTTL returns the lifetime of the session cookie from session-cookie-max-age,
+// or session-cookie-expires, both in seconds.
+func sessionCookieTTL(annots annotations.AnnotationSet) (*gatewayv1.Duration, bool) {
+	if ttl, ok := annots.GetDuration(annotations.SessionCookieMaxAge); ok {
+		return ttl, true
+	}
+	return annots.GetDuration(annotations.SessionCookieExpires)
+}
@@ -0,0 +1,197 @@
+package converter
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	egv1alpha1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/werdnum/ingress-gateway-api/internal/annotations"
+	"github.com/werdnum/ingress-gateway-api/internal/config"
+)
+
+func TestConvertIngressFull_CookieAffinity(t *testing.T) {
+	tests := []struct {
+		name            string
+		sessionAffinity string
+		annots          map[string]string
+		wantPersistence bool
+		wantCookieHash  bool
+		wantWarning     string
+	}{
+		{
+			name:            "balanced by default",
+			sessionAffinity: config.SessionAffinityAuto,
+			annots:          map[string]string{annotations.Affinity: "cookie"},
+			wantCookieHash:  true,
+		},
+		{
+			name:            "persistent",
+			sessionAffinity: config.SessionAffinityAuto,
+			annots: map[string]string{
+				annotations.Affinity:     "cookie",
+				annotations.AffinityMode: "persistent",
+			},
+			wantPersistence: true,
+			wantWarning:     "needs the experimental Gateway API CRDs",
+		},
+		{
+			name:            "session persistence for balanced",
+			sessionAffinity: config.SessionAffinitySessionPersistence,
+			annots: map[string]string{
+				annotations.Affinity:     "cookie",
+				annotations.AffinityMode: "balanced",
+			},
+			wantPersistence: true,
+			wantWarning:     "keeps sessions on their endpoint",
+		},
+		{
+			name:            "consistent hash for persistent",
+			sessionAffinity: config.SessionAffinityConsistentHash,
+			annots: map[string]string{
+				annotations.Affinity:     "cookie",
+				annotations.AffinityMode: "persistent",
+			},
+			wantCookieHash: true,
+			wantWarning:    "moves some sessions",
+		},
+		{
+			name:            "cookie attributes with session persistence",
+			sessionAffinity: config.SessionAffinitySessionPersistence,
+			annots: map[string]string{
+				annotations.Affinity:              "cookie",
+				annotations.SessionCookieSameSite: "Strict",
+			},
+			wantPersistence: true,
+			wantWarning:     "session-cookie-samesite is not supported with session persistence",
+		},
+		{
+			name:            "precedence over upstream-hash-by",
+			sessionAffinity: config.SessionAffinityAuto,
+			annots: map[string]string{
+				annotations.Affinity:       "cookie",
+				annotations.UpstreamHashBy: "$remote_addr",
+			},
+			wantCookieHash: true,
+			wantWarning:    "upstream-hash-by is ignored with cookie affinity",
+		},
+		{
+			name:            "unsupported affinity",
+			sessionAffinity: config.SessionAffinityAuto,
+			annots:          map[string]string{annotations.Affinity: "ip"},
+			wantWarning:     `"ip" is not supported`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(&config.Config{
+				GatewayName:      "eg-gateway",
+				GatewayNamespace: "envoy-gateway",
+				SessionAffinity:  tt.sessionAffinity,
+			})
+			ingress := tlsTestIngress([]string{"example.com"}, nil)
+			ingress.Annotations = tt.annots
+
+			result := c.ConvertIngressFull(context.Background(), ingress)
+
+			for _, rule := range result.HTTPRoutes[0].Spec.Rules {
+				persistence := rule.SessionPersistence
+				if (persistence != nil) != tt.wantPersistence {
+					t.Errorf("expected session persistence %v, got %+v", tt.wantPersistence, persistence)
+				}
+				if persistence != nil && *persistence.SessionName != "INGRESSCOOKIE" {
+					t.Errorf("expected the INGRESSCOOKIE session, got %s", *persistence.SessionName)
+				}
+			}
+
+			var hash *egv1alpha1.ConsistentHash
+			if len(result.BackendTrafficPolicies) == 1 {
+				if lb := result.BackendTrafficPolicies[0].Spec.ClusterSettings.LoadBalancer; lb != nil {
+					hash = lb.ConsistentHash
+				}
+			}
+			cookieHash := hash != nil && hash.Type == egv1alpha1.CookieConsistentHashType
+			if cookieHash != tt.wantCookieHash {
+				t.Errorf("expected cookie consistent hash %v, got %+v", tt.wantCookieHash, hash)
+			}
+
+			warned := slices.ContainsFunc(result.Warnings, func(w string) bool {
+				return tt.wantWarning != "" && strings.Contains(w, tt.wantWarning)
+			})
+			if warned != (tt.wantWarning != "") {
+				t.Errorf("expected warning %q, got %v", tt.wantWarning, result.Warnings)
+			}
+		})
+	}
+}
+
+func TestBuildCookieHash(t *testing.T) {
+	tests := []struct {
+		name           string
+		annots         map[string]string
+		wantName       string
+		wantTTL        gatewayv1.Duration
+		wantAttributes map[string]string
+	}{
+		{
+			name:           "defaults",
+			annots:         map[string]string{annotations.Affinity: "cookie"},
+			wantName:       "INGRESSCOOKIE",
+			wantTTL:        "0s",
+			wantAttributes: map[string]string{"Path": "/"},
+		},
+		{
+			name: "max-age over expires",
+			annots: map[string]string{
+				annotations.SessionCookieName:    "route",
+				annotations.SessionCookieMaxAge:  "3600",
+				annotations.SessionCookieExpires: "60",
+			},
+			wantName:       "route",
+			wantTTL:        "1h",
+			wantAttributes: map[string]string{"Path": "/"},
+		},
+		{
+			name: "attributes",
+			annots: map[string]string{
+				annotations.SessionCookieExpires:  "172800",
+				annotations.SessionCookiePath:     "/app",
+				annotations.SessionCookieSameSite: "None",
+				annotations.SessionCookieSecure:   "true",
+				annotations.SessionCookieDomain:   ".example.com",
+			},
+			wantName: "INGRESSCOOKIE",
+			wantTTL:  "48h",
+			wantAttributes: map[string]string{
+				"Path":     "/app",
+				"SameSite": "None",
+				"Secure":   "",
+				"Domain":   ".example.com",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cookie := buildCookieHash(annotations.NewAnnotationSet(tt.annots)).ConsistentHash.Cookie
+			if cookie.Name != tt.wantName {
+				t.Errorf("expected cookie %s, got %s", tt.wantName, cookie.Name)
+			}
+			if cookie.TTL == nil || *cookie.TTL != tt.wantTTL {
+				t.Errorf("expected TTL %s, got %v", tt.wantTTL, cookie.TTL)
+			}
+			if len(cookie.Attributes) != len(tt.wantAttributes) {
+				t.Errorf("expected attributes %v, got %v", tt.wantAttributes, cookie.Attributes)
+			}
+			for name, value := range tt.wantAttributes {
+				if got, ok := cookie.Attributes[name]; !ok || got != value {
+					t.Errorf("expected attribute %s=%q, got %v", name, value, cookie.Attributes)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,331 @@
+package converter
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/werdnum/ingress-gateway-api/internal/annotations"
+)
+
+// defaultCanaryWeightTotal is the canary-weight-total ingress-nginx uses when unset.
+const defaultCanaryWeightTotal = 100
+
+// canaryPath is a path of a canary Ingress. It overlays the same path of the primary
+// Ingress for the host instead of getting an HTTPRoute of its own.
+type canaryPath struct {
+	ingress *networkingv1.Ingress
+	path    networkingv1.HTTPIngressPath
+}
+
+// canaryRef returns namespace/name of the canary Ingress for messages.
+func (p canaryPath) canaryRef() string {
+	return fmt.Sprintf("%s/%s", p.ingress.Namespace, p.ingress.Name)
+}
+
+// canaryWarnings reports the paths of a canary Ingress that have no primary Ingress to
+// overlay. The canary itself is converted by the primary, like in ingress-nginx.
+func (c *Converter) canaryWarnings(ctx context.Context, ingress *networkingv1.Ingress) []string {
+	var hosts []string
+	for _, rule := range ingress.Spec.Rules {
+		if rule.HTTP != nil && !slices.Contains(hosts, rule.Host) {
+			hosts = append(hosts, rule.Host)
+		}
+	}
+	slices.Sort(hosts)
+	siblings, warnings := c.siblingIngresses(ctx, ingress, hosts)
+	annots := annotations.NewAnnotationSet(ingress.Annotations)
+
+	for _, host := range hosts {
+		var primaries []networkingv1.Ingress
+		useRegex := annots.UsesRegex()
+		for _, sibling := range siblings[host] {
+			siblingAnnots := annotations.NewAnnotationSet(sibling.Annotations)
+			useRegex = useRegex || siblingAnnots.UsesRegex()
+			if !siblingAnnots.IsCanary() {
+				primaries = append(primaries, sibling)
+			}
+		}
+		primaryPaths := hostPaths(primaries, host)
+		for _, path := range hostPaths([]networkingv1.Ingress{*ingress}, host) {
+			found := slices.ContainsFunc(primaryPaths, func(p networkingv1.HTTPIngressPath) bool {
+				return samePath(p, path, useRegex)
+			})
+			if !found {
+				warnings = append(warnings, fmt.Sprintf(
+					"canary path %s for host %s has no primary Ingress and is ignored", path.Path, displayHost(host)))
+			}
+		}
+	}
+	return warnings
+}
+
+// hostCanaryPaths returns the paths of the canary Ingresses of a host. Like ingress-nginx,
+// a path only has one canary, from the oldest Ingress; the others are reported.
+func hostCanaryPaths(host string, siblings []networkingv1.Ingress) ([]canaryPath, []string) {
+	var canaries []*networkingv1.Ingress
+	for i := range siblings {
+		if annotations.NewAnnotationSet(siblings[i].Annotations).IsCanary() {
+			canaries = append(canaries, &siblings[i])
+		}
+	}
+	slices.SortStableFunc(canaries, func(a, b *networkingv1.Ingress) int {
+		if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+			return a.CreationTimestamp.Compare(b.CreationTimestamp.Time)
+		}
+		return strings.Compare(a.Namespace+"/"+a.Name, b.Namespace+"/"+b.Name)
+	})
+
+	var paths []canaryPath
+	var warnings []string
+	for _, canary := range canaries {
+		for _, path := range hostPaths([]networkingv1.Ingress{*canary}, host) {
+			candidate := canaryPath{ingress: canary, path: path}
+			existing := slices.IndexFunc(paths, func(p canaryPath) bool { return samePath(p.path, path, false) })
+			if existing >= 0 {
+				warnings = append(warnings, fmt.Sprintf(
+					"canary %s for host %s path %s is ignored because %s is already its canary",
+					candidate.canaryRef(), displayHost(host), path.Path, paths[existing].canaryRef()))
+				continue
+			}
+			paths = append(paths, candidate)
+		}
+	}
+	return paths, warnings
+}
+
+// translateCanaryPaths rewrites canary paths on a regex host the way translateRegexPaths
+// rewrites the primary paths, so that both still compare equal. Paths that cannot be
+// translated are dropped along with the primary path.
+func translateCanaryPaths(host string, paths []canaryPath, padding map[string]int) []canaryPath {
+	var translated []canaryPath
+	for _, path := range paths {
+		result, _ := translateRegexPaths(host, []networkingv1.HTTPIngressPath{path.path}, padding)
+		if len(result) == 1 {
+			path.path = result[0]
+			translated = append(translated, path)
+		}
+	}
+	return translated
+}
+
+// samePath reports whether two Ingress paths are the same nginx location. On regex hosts
+// every path type is the same regex location.
+func samePath(a, b networkingv1.HTTPIngressPath, useRegex bool) bool {
+	if a.Path != b.Path {
+		return false
+	}
+	return useRegex || ingressPathType(a) == ingressPathType(b)
+}
+
+// ingressPathType returns the path type, with the Prefix default convertPathMatch uses.
+func ingressPathType(path networkingv1.HTTPIngressPath) networkingv1.PathType {
+	if path.PathType == nil {
+		return networkingv1.PathTypePrefix
+	}
+	return *path.PathType
+}
+
+// addCanaryRules overlays the canary paths on the rules of the primary HTTPRoute. The
+// canary backend gets canary-weight out of canary-weight-total of the requests, and
+// rules with header matches send the requests chosen by canary-by-header and
+// canary-by-cookie to the canary or keep them on the primary. Gateway API prefers rules
+// with more header matches and then earlier rules, so header rules win over cookie
+// rules and both over the weighted rule, which is the ingress-nginx precedence.
+func (c *Converter) addCanaryRules(
+	ctx context.Context,
+	httpRoute *gatewayv1.HTTPRoute,
+	paths []networkingv1.HTTPIngressPath,
+	canaries []canaryPath,
+	useRegex bool,
+) []string {
+	var warnings []string
+	var extra []gatewayv1.HTTPRouteRule
+
+	for i := range httpRoute.Spec.Rules {
+		rule := &httpRoute.Spec.Rules[i]
+		if i >= len(paths) || len(rule.BackendRefs) == 0 {
+			continue
+		}
+		index := slices.IndexFunc(canaries, func(p canaryPath) bool { return samePath(p.path, paths[i], useRegex) })
+		if index < 0 {
+			continue
+		}
+		canary := canaries[index]
+		canaryAnnots := annotations.NewAnnotationSet(canary.ingress.Annotations)
+		if rolloutAborted(canaryAnnots) {
+			// An aborted rollout sends every request to the primary, headers and cookies included
+			continue
+		}
+
+		canaryBackend := c.convertIngressBackend(ctx, canary.ingress.Namespace, canary.path.Backend)
+		if canary.ingress.Namespace != httpRoute.Namespace {
+			canaryBackend.Namespace = ptr(gatewayv1.Namespace(canary.ingress.Namespace))
+		}
+		primaryBackends := rule.BackendRefs
+
+		rules, ruleWarnings := canaryMatchRules(*rule, primaryBackends, canaryBackend, canaryAnnots, canary.canaryRef())
+		extra = append(extra, rules...)
+		warnings = append(warnings, ruleWarnings...)
+
+		weight, total, weightWarnings := canaryWeight(canaryAnnots, canary.canaryRef())
+		warnings = append(warnings, weightWarnings...)
+		if weight > 0 {
+			weighted := make([]gatewayv1.HTTPBackendRef, 0, len(primaryBackends)+1)
+			for _, backend := range primaryBackends {
+				backend.Weight = ptr(total - weight)
+				weighted = append(weighted, backend)
+			}
+			canaryBackend.Weight = ptr(weight)
+			rule.BackendRefs = append(weighted, canaryBackend)
+		}
+	}
+
+	httpRoute.Spec.Rules = append(httpRoute.Spec.Rules, extra...)
+	return warnings
+}
+
+// canaryWeight returns canary-weight and canary-weight-total. A progressive rollout in
+// canary-steps replaces canary-weight with the weight of the current step, or 0 once
+// aborted. A weight above the total sends every request to the canary.
+func canaryWeight(annots annotations.AnnotationSet, ref string) (int32, int32, []string) {
+	var warnings []string
+	parse := func(key string, fallback int32) int32 {
+		value, ok := annots.GetString(key)
+		if !ok {
+			return fallback
+		}
+		parsed, err := strconv.ParseInt(strings.TrimSpace(value), 10, 32)
+		if err != nil || parsed < 0 {
+			warnings = append(warnings, fmt.Sprintf("canary %s: %s %q is not a valid weight and is ignored", ref, key, value))
+			return fallback
+		}
+		return int32(parsed)
+	}
+
+	total := parse(annotations.CanaryWeightTotal, defaultCanaryWeightTotal)
+	if total == 0 {
+		total = defaultCanaryWeightTotal
+	}
+	weight := parse(annotations.CanaryWeight, 0)
+
+	steps, ok, err := annots.GetCanarySteps()
+	switch {
+	case err != nil:
+		warnings = append(warnings, fmt.Sprintf("canary %s: canary-steps is ignored: %v", ref, err))
+	case rolloutAborted(annots):
+		weight = 0
+	case ok:
+		step, _ := annots.GetCanaryStep()
+		weight = steps[min(max(step, 1), len(steps))-1].Weight
+	}
+
+	return min(weight, total), total, warnings
+}
+
+// rolloutAborted returns true if canary-abort stops the progressive rollout of canary-steps.
+func rolloutAborted(annots annotations.AnnotationSet) bool {
+	_, ok, err := annots.GetCanarySteps()
+	return ok && err == nil && annots.IsCanaryAborted()
+}
+
+// canaryMatchRules returns copies of the primary rule that match canary-by-header and
+// canary-by-cookie. A header equal to canary-by-header-value, or matching
+// canary-by-header-pattern, selects the canary; without either, the values always and
+// never select the canary or the primary. The cookie only knows always and never.
+func canaryMatchRules(
+	rule gatewayv1.HTTPRouteRule,
+	primary []gatewayv1.HTTPBackendRef,
+	canary gatewayv1.HTTPBackendRef,
+	annots annotations.AnnotationSet,
+	ref string,
+) ([]gatewayv1.HTTPRouteRule, []string) {
+	var rules []gatewayv1.HTTPRouteRule
+	var warnings []string
+	canaryBackends := []gatewayv1.HTTPBackendRef{canary}
+
+	if header, ok := annots.GetString(annotations.CanaryByHeader); ok {
+		value, hasValue := annots.GetString(annotations.CanaryByHeaderValue)
+		pattern, hasPattern := annots.GetString(annotations.CanaryByHeaderPattern)
+		switch {
+		case !headerNamePattern.MatchString(header):
+			warnings = append(warnings, fmt.Sprintf(
+				"canary %s: canary-by-header %q is not a valid header name and is ignored", ref, header))
+		case hasValue && value != "":
+			rules = append(rules, withHeaderMatch(rule, exactHeader(header, value), canaryBackends))
+		case hasPattern && pattern != "":
+			translated, _, err := translatePCRE(pattern)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf(
+					"canary %s: canary-by-header-pattern %q is ignored: %v", ref, pattern, err))
+				break
+			}
+			// ingress-nginx searches the header, Envoy matches all of it
+			match := gatewayv1.HTTPHeaderMatch{
+				Type:  ptr(gatewayv1.HeaderMatchRegularExpression),
+				Name:  gatewayv1.HTTPHeaderName(header),
+				Value: ".*(?:" + translated + ").*",
+			}
+			rules = append(rules, withHeaderMatch(rule, match, canaryBackends))
+		default:
+			rules = append(rules,
+				withHeaderMatch(rule, exactHeader(header, "always"), canaryBackends),
+				withHeaderMatch(rule, exactHeader(header, "never"), primary))
+		}
+	}
+
+	if cookie, ok := annots.GetString(annotations.CanaryByCookie); ok && cookie != "" {
+		rules = append(rules,
+			withHeaderMatch(rule, cookieHeader(cookie, "always"), canaryBackends),
+			withHeaderMatch(rule, cookieHeader(cookie, "never"), primary))
+	}
+
+	return rules, warnings
+}
+
+// exactHeader returns a match for a header with the given value.
+func exactHeader(name, value string) gatewayv1.HTTPHeaderMatch {
+	return gatewayv1.HTTPHeaderMatch{
+		Type:  ptr(gatewayv1.HeaderMatchExact),
+		Name:  gatewayv1.HTTPHeaderName(name),
+		Value: value,
+	}
+}
+
+// cookieHeader returns a match for a Cookie header that sets the cookie to the value.
+func cookieHeader(name, value string) gatewayv1.HTTPHeaderMatch {
+	return gatewayv1.HTTPHeaderMatch{
+		Type:  ptr(gatewayv1.HeaderMatchRegularExpression),
+		Name:  "Cookie",
+		Value: "(?:.*;\\s*)?" + regexp.QuoteMeta(name+"="+value) + "(?:\\s*;.*)?",
+	}
+}
+
+// withHeaderMatch returns a copy of the rule that also requires the header and sends
+// requests to the backends.
+func withHeaderMatch(
+	rule gatewayv1.HTTPRouteRule,
+	header gatewayv1.HTTPHeaderMatch,
+	backends []gatewayv1.HTTPBackendRef,
+) gatewayv1.HTTPRouteRule {
+	copied := *rule.DeepCopy()
+	copied.Name = nil
+	if len(copied.Matches) == 0 {
+		copied.Matches = []gatewayv1.HTTPRouteMatch{{}}
+	}
+	for i := range copied.Matches {
+		copied.Matches[i].Headers = append(copied.Matches[i].Headers, header)
+	}
+	copied.BackendRefs = nil
+	for _, backend := range backends {
+		backend.Weight = nil
+		copied.BackendRefs = append(copied.BackendRefs, *backend.DeepCopy())
+	}
+	return copied
+}
@@ -0,0 +1,265 @@
+package converter
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/werdnum/ingress-gateway-api/internal/annotations"
+	"github.com/werdnum/ingress-gateway-api/internal/config"
+)
+
+func canaryTestIngress(namespace string, annots map[string]string) networkingv1.Ingress {
+	canary := tlsTestIngress([]string{"example.com"}, nil)
+	canary.Name = "canary"
+	canary.Namespace = namespace
+	canary.Annotations = map[string]string{annotations.Canary: "true"}
+	for key, value := range annots {
+		canary.Annotations[key] = value
+	}
+	canary.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Name = "web-canary"
+	return *canary
+}
+
+func TestConvertIngressFull_Canary(t *testing.T) {
+	type wantRule struct {
+		header   string // header match name, empty for the weighted rule
+		value    string
+		backends map[string]int32 // service name to weight, 0 for unweighted
+	}
+
+	tests := []struct {
+		name      string
+		canary    networkingv1.Ingress
+		wantRules []wantRule
+	}{
+		{
+			name:   "weight",
+			canary: canaryTestIngress("default", map[string]string{annotations.CanaryWeight: "20"}),
+			wantRules: []wantRule{
+				{backends: map[string]int32{"web": 80, "web-canary": 20}},
+			},
+		},
+		{
+			name: "weight total",
+			canary: canaryTestIngress("default", map[string]string{
+				annotations.CanaryWeight:      "10",
+				annotations.CanaryWeightTotal: "1000",
+			}),
+			wantRules: []wantRule{
+				{backends: map[string]int32{"web": 990, "web-canary": 10}},
+			},
+		},
+		{
+			name:   "weight above the total",
+			canary: canaryTestIngress("default", map[string]string{annotations.CanaryWeight: "150"}),
+			wantRules: []wantRule{
+				{backends: map[string]int32{"web": 0, "web-canary": 100}},
+			},
+		},
+		{
+			name: "rollout step replaces the weight",
+			canary: canaryTestIngress("default", map[string]string{
+				annotations.CanaryWeight: "5",
+				annotations.CanarySteps:  "10:5m,50:10m,100",
+				annotations.CanaryStep:   "2",
+			}),
+			wantRules: []wantRule{
+				{backends: map[string]int32{"web": 50, "web-canary": 50}},
+			},
+		},
+		{
+			name: "aborted rollout",
+			canary: canaryTestIngress("default", map[string]string{
+				annotations.CanarySteps: "10:5m,50:10m,100",
+				annotations.CanaryStep:  "2",
+				annotations.CanaryAbort: "true",
+			}),
+			wantRules: []wantRule{
+				{backends: map[string]int32{"web": 0}},
+			},
+		},
+		{
+			name: "aborted rollout with header and cookie",
+			canary: canaryTestIngress("default", map[string]string{
+				annotations.CanarySteps:    "10:5m,50:10m,100",
+				annotations.CanaryAbort:    "true",
+				annotations.CanaryByHeader: "X-Canary",
+				annotations.CanaryByCookie: "canary",
+			}),
+			wantRules: []wantRule{
+				{backends: map[string]int32{"web": 0}},
+			},
+		},
+		{
+			name:   "by-header always and never",
+			canary: canaryTestIngress("default", map[string]string{annotations.CanaryByHeader: "X-Canary"}),
+			wantRules: []wantRule{
+				{backends: map[string]int32{"web": 0}},
+				{header: "X-Canary", value: "always", backends: map[string]int32{"web-canary": 0}},
+				{header: "X-Canary", value: "never", backends: map[string]int32{"web": 0}},
+			},
+		},
+		{
+			name: "by-header value",
+			canary: canaryTestIngress("default", map[string]string{
+				annotations.CanaryByHeader:        "X-Canary",
+				annotations.CanaryByHeaderValue:   "beta",
+				annotations.CanaryByHeaderPattern: "ignored",
+			}),
+			wantRules: []wantRule{
+				{backends: map[string]int32{"web": 0}},
+				{header: "X-Canary", value: "beta", backends: map[string]int32{"web-canary": 0}},
+			},
+		},
+		{
+			name: "by-header pattern",
+			canary: canaryTestIngress("default", map[string]string{
+				annotations.CanaryByHeader:        "X-Canary",
+				annotations.CanaryByHeaderPattern: "^beta|alpha",
+			}),
+			wantRules: []wantRule{
+				{backends: map[string]int32{"web": 0}},
+				{header: "X-Canary", value: ".*(?:^beta|alpha).*", backends: map[string]int32{"web-canary": 0}},
+			},
+		},
+		{
+			name: "header, cookie and weight together",
+			canary: canaryTestIngress("default", map[string]string{
+				annotations.CanaryByHeader: "X-Canary",
+				annotations.CanaryByCookie: "canary",
+				annotations.CanaryWeight:   "5",
+			}),
+			wantRules: []wantRule{
+				{backends: map[string]int32{"web": 95, "web-canary": 5}},
+				{header: "X-Canary", value: "always", backends: map[string]int32{"web-canary": 0}},
+				{header: "X-Canary", value: "never", backends: map[string]int32{"web": 0}},
+				{header: "Cookie", value: `(?:.*;\s*)?canary=always(?:\s*;.*)?`, backends: map[string]int32{"web-canary": 0}},
+				{header: "Cookie", value: `(?:.*;\s*)?canary=never(?:\s*;.*)?`, backends: map[string]int32{"web": 0}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewWithResolvers(&config.Config{
+				GatewayName:      "eg-gateway",
+				GatewayNamespace: "envoy-gateway",
+			}, Resolvers{Ingresses: &staticIngressResolver{ingresses: []networkingv1.Ingress{tt.canary}}})
+			primary := tlsTestIngress([]string{"example.com"}, nil)
+
+			result := c.ConvertIngressFull(context.Background(), primary)
+
+			if len(result.HTTPRoutes) != 1 {
+				t.Fatalf("expected 1 HTTPRoute, got %d", len(result.HTTPRoutes))
+			}
+			rules := result.HTTPRoutes[0].Spec.Rules
+			if len(rules) != len(tt.wantRules) {
+				t.Fatalf("expected %d rules, got %d: %+v", len(tt.wantRules), len(rules), rules)
+			}
+			for i, want := range tt.wantRules {
+				rule := rules[i]
+				if *rule.Matches[0].Path.Value != "/" {
+					t.Errorf("rule %d: expected the primary path match, got %s", i, *rule.Matches[0].Path.Value)
+				}
+				headers := rule.Matches[0].Headers
+				switch {
+				case want.header == "" && len(headers) != 0:
+					t.Errorf("rule %d: expected no header matches, got %+v", i, headers)
+				case want.header != "" && (len(headers) != 1 || string(headers[0].Name) != want.header || headers[0].Value != want.value):
+					t.Errorf("rule %d: expected header %s %s, got %+v", i, want.header, want.value, headers)
+				}
+				if len(rule.BackendRefs) != len(want.backends) {
+					t.Errorf("rule %d: expected backends %v, got %+v", i, want.backends, rule.BackendRefs)
+				}
+				for _, ref := range rule.BackendRefs {
+					weight, ok := want.backends[string(ref.Name)]
+					var got int32
+					if ref.Weight != nil {
+						got = *ref.Weight
+					}
+					if !ok || got != weight || (weight == 0 && len(want.backends) == 1 && ref.Weight != nil) {
+						t.Errorf("rule %d: unexpected backend %s with weight %v", i, ref.Name, ref.Weight)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCookieHeader(t *testing.T) {
+	re := regexp.MustCompile("^(?:" + cookieHeader("canary", "always").Value + ")$")
+	for cookie, want := range map[string]bool{
+		"canary=always":                true,
+		"session=abc; canary=always":   true,
+		"canary=always; session=abc":   true,
+		"a=1;canary=always;b=2":        true,
+		"canary=never":                 false,
+		"mycanary=always":              false,
+		"canary=alwaysx; session=abc":  false,
+		"session=canary=always-not-ok": false,
+	} {
+		if got := re.MatchString(cookie); got != want {
+			t.Errorf("cookie %q: expected match %v, got %v", cookie, want, got)
+		}
+	}
+}
+
+func TestConvertIngressFull_CanaryIngress(t *testing.T) {
+	tests := []struct {
+		name        string
+		primaries   []networkingv1.Ingress
+		wantWarning bool
+	}{
+		{
+			name:      "merged into the primary",
+			primaries: []networkingv1.Ingress{*tlsTestIngress([]string{"example.com"}, nil)},
+		},
+		{
+			name:        "no primary",
+			wantWarning: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewWithResolvers(&config.Config{
+				GatewayName:      "eg-gateway",
+				GatewayNamespace: "envoy-gateway",
+			}, Resolvers{Ingresses: &staticIngressResolver{ingresses: tt.primaries}})
+			canary := canaryTestIngress("default", map[string]string{annotations.CanaryWeight: "10"})
+			canary.Spec.TLS = []networkingv1.IngressTLS{{Hosts: []string{"example.com"}, SecretName: "tls"}}
+
+			result := c.ConvertIngressFull(context.Background(), &canary)
+
+			if len(result.HTTPRoutes) != 0 || len(result.Listeners) != 0 {
+				t.Errorf("expected no HTTPRoutes or listeners for a canary, got %d and %d",
+					len(result.HTTPRoutes), len(result.Listeners))
+			}
+			warned := len(result.Warnings) == 1 && strings.Contains(result.Warnings[0], "has no primary Ingress")
+			if warned != tt.wantWarning {
+				t.Errorf("expected warning %v, got %v", tt.wantWarning, result.Warnings)
+			}
+		})
+	}
+}
+
+func TestConvertIngressFull_CanaryInOtherNamespace(t *testing.T) {
+	canary := canaryTestIngress("canaries", map[string]string{annotations.CanaryWeight: "50"})
+	c := NewWithResolvers(&config.Config{
+		GatewayName:      "eg-gateway",
+		GatewayNamespace: "envoy-gateway",
+	}, Resolvers{Ingresses: &staticIngressResolver{ingresses: []networkingv1.Ingress{canary}}})
+	primary := tlsTestIngress([]string{"example.com"}, nil)
+
+	result := c.ConvertIngressFull(context.Background(), primary)
+
+	refs := result.HTTPRoutes[0].Spec.Rules[0].BackendRefs
+	if len(refs) != 2 || refs[1].Namespace == nil || *refs[1].Namespace != gatewayv1.Namespace("canaries") {
+		t.Errorf("expected the canary backend in namespace canaries, got %+v", refs)
+	}
+}
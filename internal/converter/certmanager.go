@@ -0,0 +1,129 @@
+package converter
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/werdnum/ingress-gateway-api/internal/annotations"
+)
+
+// CertificateGVK is the cert-manager Certificate kind. Certificates are handled as
+// unstructured objects so that the controller does not depend on cert-manager.
+var CertificateGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+
+// generateCertificates creates a cert-manager Certificate for each TLS Secret of an
+// Ingress with an issuer annotation, like cert-manager's ingress-shim: the Certificate
+// is named after the Secret and covers the hosts of the TLS entries that use it.
+// Listeners reference the same Secrets, so they pick up the issued certificates.
+func (c *Converter) generateCertificates(ingress *networkingv1.Ingress, annots annotations.AnnotationSet) ([]*unstructured.Unstructured, []string) {
+	if !annots.HasCertManagerIssuer() {
+		return nil, nil
+	}
+	if annots.HasSSLPassthrough() {
+		return nil, []string{"cert-manager annotations are ignored with ssl-passthrough, the backend terminates TLS"}
+	}
+
+	issuerRef, warnings := certificateIssuerRef(annots)
+	if issuerRef == nil {
+		return nil, warnings
+	}
+
+	// Collect the hosts of each Secret in declaration order
+	var secretNames []string
+	hostsBySecret := make(map[string][]string)
+	for _, tls := range ingress.Spec.TLS {
+		if tls.SecretName == "" {
+			warnings = append(warnings, fmt.Sprintf(
+				"TLS entry for hosts %v has no secretName, cert-manager cannot issue a certificate for it", tls.Hosts))
+			continue
+		}
+		if len(tls.Hosts) == 0 {
+			warnings = append(warnings, fmt.Sprintf(
+				"TLS entry for Secret %s has no hosts, cert-manager cannot issue a certificate for it", tls.SecretName))
+			continue
+		}
+		if _, ok := hostsBySecret[tls.SecretName]; !ok {
+			secretNames = append(secretNames, tls.SecretName)
+		}
+		for _, host := range tls.Hosts {
+			if !slices.Contains(hostsBySecret[tls.SecretName], host) {
+				hostsBySecret[tls.SecretName] = append(hostsBySecret[tls.SecretName], host)
+			}
+		}
+	}
+
+	spec := map[string]any{"issuerRef": issuerRef}
+	if commonName, ok := annots.GetString(annotations.CertManagerCommonName); ok {
+		spec["commonName"] = commonName
+	}
+	for _, field := range []struct{ key, name string }{
+		{annotations.CertManagerDuration, "duration"},
+		{annotations.CertManagerRenewBefore, "renewBefore"},
+	} {
+		value, ok := annots.GetString(field.key)
+		if !ok {
+			continue
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("invalid %s %q, using the cert-manager default", field.key, value))
+			continue
+		}
+		spec[field.name] = d.String()
+	}
+
+	certificates := make([]*unstructured.Unstructured, 0, len(secretNames))
+	for _, secretName := range secretNames {
+		certificate := &unstructured.Unstructured{}
+		certificate.SetGroupVersionKind(CertificateGVK)
+		certificate.SetName(secretName)
+		certificate.SetNamespace(ingress.Namespace)
+		certificate.SetLabels(copyLabels(ingress.Labels))
+		certificate.SetAnnotations(map[string]string{
+			"ingress-gateway-api.io/source": fmt.Sprintf("%s/%s", ingress.Namespace, ingress.Name),
+		})
+
+		certificateSpec := runtime.DeepCopyJSON(spec)
+		certificateSpec["secretName"] = secretName
+		dnsNames := make([]any, 0, len(hostsBySecret[secretName]))
+		for _, host := range hostsBySecret[secretName] {
+			dnsNames = append(dnsNames, host)
+		}
+		certificateSpec["dnsNames"] = dnsNames
+		certificate.Object["spec"] = certificateSpec
+
+		certificates = append(certificates, certificate)
+	}
+
+	return certificates, warnings
+}
+
+// certificateIssuerRef returns the Certificate issuerRef for the issuer annotations.
+// issuer-kind and issuer-group select an external issuer for cert-manager.io/issuer.
+func certificateIssuerRef(annots annotations.AnnotationSet) (map[string]any, []string) {
+	issuer, hasIssuer := annots.GetString(annotations.CertManagerIssuer)
+	clusterIssuer, hasClusterIssuer := annots.GetString(annotations.CertManagerClusterIssuer)
+	if hasIssuer && hasClusterIssuer {
+		return nil, []string{"both cert-manager.io/issuer and cert-manager.io/cluster-issuer are set, no Certificate created"}
+	}
+
+	name, kind := clusterIssuer, "ClusterIssuer"
+	if hasIssuer {
+		name, kind = issuer, "Issuer"
+		if issuerKind, ok := annots.GetString(annotations.CertManagerIssuerKind); ok {
+			kind = issuerKind
+		}
+	}
+	group := CertificateGVK.Group
+	if issuerGroup, ok := annots.GetString(annotations.CertManagerIssuerGroup); ok {
+		group = issuerGroup
+	}
+
+	return map[string]any{"name": name, "kind": kind, "group": group}, nil
+}
@@ -0,0 +1,157 @@
+package converter
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/werdnum/ingress-gateway-api/internal/config"
+)
+
+func TestConvertIngressFull_CertManagerCertificates(t *testing.T) {
+	tests := []struct {
+		name          string
+		annotations   map[string]string
+		tls           []networkingv1.IngressTLS
+		wantIssuerRef map[string]any
+		wantCerts     map[string][]any
+		wantSpec      map[string]any
+		wantWarnings  int
+	}{
+		{
+			name:        "cluster issuer",
+			annotations: map[string]string{"cert-manager.io/cluster-issuer": "letsencrypt"},
+			tls: []networkingv1.IngressTLS{
+				{Hosts: []string{"example.com", "www.example.com"}, SecretName: "example-tls"},
+				{Hosts: []string{"api.example.org"}, SecretName: "api-tls"},
+			},
+			wantIssuerRef: map[string]any{"name": "letsencrypt", "kind": "ClusterIssuer", "group": "cert-manager.io"},
+			wantCerts: map[string][]any{
+				"example-tls": {"example.com", "www.example.com"},
+				"api-tls":     {"api.example.org"},
+			},
+		},
+		{
+			name: "external issuer with certificate settings",
+			annotations: map[string]string{
+				"cert-manager.io/issuer":       "vault",
+				"cert-manager.io/issuer-kind":  "VaultIssuer",
+				"cert-manager.io/issuer-group": "vault.example.com",
+				"cert-manager.io/common-name":  "example.com",
+				"cert-manager.io/duration":     "2160h",
+				"cert-manager.io/renew-before": "360h",
+			},
+			tls: []networkingv1.IngressTLS{
+				{Hosts: []string{"example.com"}, SecretName: "example-tls"},
+			},
+			wantIssuerRef: map[string]any{"name": "vault", "kind": "VaultIssuer", "group": "vault.example.com"},
+			wantCerts:     map[string][]any{"example-tls": {"example.com"}},
+			wantSpec: map[string]any{
+				"commonName":  "example.com",
+				"duration":    "2160h0m0s",
+				"renewBefore": "360h0m0s",
+			},
+		},
+		{
+			name:        "entries sharing a Secret are merged",
+			annotations: map[string]string{"cert-manager.io/issuer": "ca"},
+			tls: []networkingv1.IngressTLS{
+				{Hosts: []string{"example.com"}, SecretName: "example-tls"},
+				{Hosts: []string{"example.com", "www.example.com"}, SecretName: "example-tls"},
+			},
+			wantIssuerRef: map[string]any{"name": "ca", "kind": "Issuer", "group": "cert-manager.io"},
+			wantCerts:     map[string][]any{"example-tls": {"example.com", "www.example.com"}},
+		},
+		{
+			name:        "entry without secretName",
+			annotations: map[string]string{"cert-manager.io/cluster-issuer": "letsencrypt"},
+			tls: []networkingv1.IngressTLS{
+				{Hosts: []string{"example.com"}},
+			},
+			// One from the listener without a certificate
+			wantWarnings: 2,
+		},
+		{
+			name: "both issuer annotations",
+			annotations: map[string]string{
+				"cert-manager.io/issuer":         "ca",
+				"cert-manager.io/cluster-issuer": "letsencrypt",
+			},
+			tls: []networkingv1.IngressTLS{
+				{Hosts: []string{"example.com"}, SecretName: "example-tls"},
+			},
+			wantWarnings: 1,
+		},
+		{
+			name:        "invalid duration",
+			annotations: map[string]string{"cert-manager.io/cluster-issuer": "letsencrypt", "cert-manager.io/duration": "90d"},
+			tls: []networkingv1.IngressTLS{
+				{Hosts: []string{"example.com"}, SecretName: "example-tls"},
+			},
+			wantIssuerRef: map[string]any{"name": "letsencrypt", "kind": "ClusterIssuer", "group": "cert-manager.io"},
+			wantCerts:     map[string][]any{"example-tls": {"example.com"}},
+			wantWarnings:  1,
+		},
+		{
+			name: "no issuer annotation",
+			tls: []networkingv1.IngressTLS{
+				{Hosts: []string{"example.com"}, SecretName: "example-tls"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(&config.Config{
+				GatewayName:      "eg-gateway",
+				GatewayNamespace: "envoy-gateway",
+			})
+			ingress := tlsTestIngress([]string{"example.com", "www.example.com", "api.example.org"}, tt.tls)
+			ingress.Annotations = tt.annotations
+
+			result := c.ConvertIngressFull(context.Background(), ingress)
+
+			if len(result.Warnings) != tt.wantWarnings {
+				t.Errorf("expected %d warnings, got %v", tt.wantWarnings, result.Warnings)
+			}
+			if len(result.Certificates) != len(tt.wantCerts) {
+				t.Fatalf("expected %d Certificates, got %d", len(tt.wantCerts), len(result.Certificates))
+			}
+
+			for _, certificate := range result.Certificates {
+				if certificate.GroupVersionKind() != CertificateGVK {
+					t.Errorf("unexpected kind %s", certificate.GroupVersionKind())
+				}
+				if certificate.GetNamespace() != ingress.Namespace {
+					t.Errorf("expected Certificate in %s, got %s", ingress.Namespace, certificate.GetNamespace())
+				}
+				wantHosts, ok := tt.wantCerts[certificate.GetName()]
+				if !ok {
+					t.Errorf("unexpected Certificate %s", certificate.GetName())
+					continue
+				}
+
+				secretName, _, _ := unstructured.NestedString(certificate.Object, "spec", "secretName")
+				if secretName != certificate.GetName() {
+					t.Errorf("expected secretName %s, got %s", certificate.GetName(), secretName)
+				}
+				dnsNames, _, _ := unstructured.NestedSlice(certificate.Object, "spec", "dnsNames")
+				if !reflect.DeepEqual(dnsNames, wantHosts) {
+					t.Errorf("expected dnsNames %v, got %v", wantHosts, dnsNames)
+				}
+				issuerRef, _, _ := unstructured.NestedMap(certificate.Object, "spec", "issuerRef")
+				if !reflect.DeepEqual(issuerRef, tt.wantIssuerRef) {
+					t.Errorf("expected issuerRef %v, got %v", tt.wantIssuerRef, issuerRef)
+				}
+				for field, want := range tt.wantSpec {
+					if got := certificate.Object["spec"].(map[string]any)[field]; got != want {
+						t.Errorf("expected spec.%s %v, got %v", field, want, got)
+					}
+				}
+			}
+		})
+	}
+}
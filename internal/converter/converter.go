@@ -3,6 +3,8 @@ package converter
 import (
 	"context"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 
 	networkingv1 "k8s.io/api/networking/v1"
@@ -15,24 +17,62 @@ import (
 
 // Converter converts Ingress resources to HTTPRoutes.
 type Converter struct {
-	cfg      *config.Config
-	resolver ServicePortResolver
+	cfg        *config.Config
+	resolver   ServicePortResolver
+	listeners  ListenerResolver
+	configMaps ConfigMapResolver
+	ingresses  IngressResolver
+}
+
+// Resolvers bundles the cluster lookups used by the Converter.
+// Nil resolvers are replaced with no-op implementations.
+type Resolvers struct {
+	// Ports resolves named service ports.
+	Ports ServicePortResolver
+
+	// Listeners looks up the shared Gateway's listeners to choose parentRef sectionNames.
+	Listeners ListenerResolver
+
+	// ConfigMaps looks up the ConfigMaps referenced by proxy-set-headers.
+	ConfigMaps ConfigMapResolver
+
+	// Ingresses looks up the other Ingresses of a host, whose use-regex and
+	// rewrite-target annotations apply to the whole host.
+	Ingresses IngressResolver
 }
 
 // New creates a new Converter.
 func New(cfg *config.Config) *Converter {
-	return &Converter{
-		cfg:      cfg,
-		resolver: &NoopServicePortResolver{},
-	}
+	return NewWithResolvers(cfg, Resolvers{})
 }
 
 // NewWithResolver creates a new Converter with a service port resolver.
 func NewWithResolver(cfg *config.Config, resolver ServicePortResolver) *Converter {
-	return &Converter{
-		cfg:      cfg,
-		resolver: resolver,
+	return NewWithResolvers(cfg, Resolvers{Ports: resolver})
+}
+
+// NewWithResolvers creates a new Converter with the given cluster lookups.
+func NewWithResolvers(cfg *config.Config, resolvers Resolvers) *Converter {
+	c := &Converter{
+		cfg:        cfg,
+		resolver:   resolvers.Ports,
+		listeners:  resolvers.Listeners,
+		configMaps: resolvers.ConfigMaps,
+		ingresses:  resolvers.Ingresses,
 	}
+	if c.resolver == nil {
+		c.resolver = &NoopServicePortResolver{}
+	}
+	if c.listeners == nil {
+		c.listeners = &NoopListenerResolver{}
+	}
+	if c.configMaps == nil {
+		c.configMaps = &NoopConfigMapResolver{}
+	}
+	if c.ingresses == nil {
+		c.ingresses = &NoopIngressResolver{}
+	}
+	return c
 }
 
 // ConvertIngress converts an Ingress resource to HTTPRoute(s).
@@ -46,13 +86,30 @@ func (c *Converter) ConvertIngress(ctx context.Context, ingress *networkingv1.In
 
 // ConvertIngressFull converts an Ingress resource to HTTPRoute(s) and associated policies.
 // It creates one HTTPRoute per host in the Ingress, along with:
-// - BackendTrafficPolicy for timeout, load balancer, and body size annotations
+// - BackendTrafficPolicy for timeout, load balancer, session affinity, body size, and rate limit annotations
 // - ClientTrafficPolicy for buffer size annotation
 // - SecurityPolicy for CORS and ExtAuth annotations
+// - HTTPS listeners for the shared Gateway, or its per-namespace XListenerSet, from spec.tls
+// - TLSRoutes and TLS Passthrough listeners instead of HTTPRoutes for ssl-passthrough
+// - ClientTrafficPolicies for those listeners from auth-tls-*, ssl-protocols and ssl-ciphers annotations
+// - redirect-only HTTPRoutes sending plain HTTP to HTTPS (ssl-redirect, force-ssl-redirect)
+// - BackendTLSPolicies, or Backends when verification is off, for backend-protocol: HTTPS
+// - request header and Host rewrites for x-forwarded-prefix, upstream-vhost and proxy-set-headers
+// - extra matches and regex padding that keep the path nginx picks across the Ingresses of a host
+// - weighted backends and header or cookie matches for the canary Ingresses of its paths
+// - rule session persistence for cookie affinity, or a cookie consistent hash in the BackendTrafficPolicy
+//
+// HTTPRoutes attach to the Gateway or XListenerSet listeners that serve their host and protocol.
 func (c *Converter) ConvertIngressFull(ctx context.Context, ingress *networkingv1.Ingress) *ConversionResult {
 	result := &ConversionResult{}
 	annots := annotations.NewAnnotationSet(ingress.Annotations)
 
+	// Canary Ingresses are merged into the HTTPRoutes of their primary Ingress
+	if annots.IsCanary() {
+		result.Warnings = append(result.Warnings, c.canaryWarnings(ctx, ingress)...)
+		return result
+	}
+
 	// Group rules by host
 	rulesByHost := make(map[string][]networkingv1.HTTPIngressPath)
 	for _, rule := range ingress.Spec.Rules {
@@ -62,11 +119,124 @@ func (c *Converter) ConvertIngressFull(ctx context.Context, ingress *networkingv
 		}
 	}
 
+	// Generate HTTPS listeners for spec.tls
+	listeners, warnings := c.generateListeners(ctx, ingress, nil)
+	result.Listeners = listeners
+	result.Warnings = append(result.Warnings, warnings...)
+
+	// Generate per-listener ClientTrafficPolicies (client certificate authentication)
+	listenerPolicies, warnings := c.generateListenerPolicies(ingress, listeners, annots)
+	result.ListenerPolicies = listenerPolicies
+	result.Warnings = append(result.Warnings, warnings...)
+
+	// Issue the TLS certificates with cert-manager
+	certificates, warnings := c.generateCertificates(ingress, annots)
+	result.Certificates = certificates
+	result.Warnings = append(result.Warnings, warnings...)
+
+	// Look up the Gateway's listeners to attach routes to specific sections. Generated
+	// listeners only end up on the Gateway outside ListenerSet mode.
+	onGateway := listeners
+	if c.usesListenerSets() {
+		onGateway = nil
+	}
+	gatewayListeners, warnings := c.gatewayListeners(ctx, onGateway)
+	result.Warnings = append(result.Warnings, warnings...)
+
+	// Pass TLS through to the backends of ssl-passthrough hosts. No HTTP-level
+	// annotations apply, as ingress-nginx handles these connections at layer 4.
+	if annots.HasSSLPassthrough() {
+		for host, paths := range rulesByHost {
+			if host == "" {
+				continue
+			}
+			tlsRoute, warnings := c.createTLSRoute(ctx, ingress, host, paths)
+			result.Warnings = append(result.Warnings, warnings...)
+			if tlsRoute == nil {
+				continue
+			}
+			parentRefs, warnings := c.routeParentRefs(ingress, host, gatewayListeners, listeners,
+				[]gatewayv1.ProtocolType{gatewayv1.TLSProtocolType}, c.createParentRef())
+			tlsRoute.Spec.ParentRefs = parentRefs
+			result.Warnings = append(result.Warnings, warnings...)
+			result.TLSRoutes = append(result.TLSRoutes, tlsRoute)
+		}
+		return result
+	}
+
+	// Report redirect annotations that cannot be converted
+	result.Warnings = append(result.Warnings, annotationRedirectWarnings(annots)...)
+
+	// Report rate limit annotations that cannot be converted as is
+	result.Warnings = append(result.Warnings, c.rateLimitWarnings(annots)...)
+
+	// Report session affinity annotations that cannot be converted as is
+	result.Warnings = append(result.Warnings, c.sessionAffinityWarnings(annots)...)
+
+	// Modify upstream requests for x-forwarded-prefix, upstream-vhost and proxy-set-headers
+	var headers requestHeaders
+	if annots.HasRequestHeaders() {
+		headers, warnings = c.requestHeaderSettings(ctx, ingress, annots)
+		result.Warnings = append(result.Warnings, warnings...)
+	}
+
+	// Paths are regexes on hosts where any Ingress sets use-regex or rewrite-target
+	hosts := slices.Sorted(maps.Keys(rulesByHost))
+	siblings, warnings := c.siblingIngresses(ctx, ingress, hosts)
+	result.Warnings = append(result.Warnings, warnings...)
+	regexHosts, warnings := regexHosts(annots, hosts, siblings)
+	result.Warnings = append(result.Warnings, warnings...)
+
 	// Create an HTTPRoute for each host
 	for host, paths := range rulesByHost {
-		httpRoute := c.createHTTPRouteWithFilters(ctx, ingress, host, paths, annots)
+		// Keep the path nginx would pick across all Ingresses of the host. Envoy only
+		// understands RE2, so PCRE-only paths are translated or dropped
+		siblingPaths := hostPaths(siblings[host], host)
+		canaries, warnings := hostCanaryPaths(host, siblings[host])
+		result.Warnings = append(result.Warnings, warnings...)
+		if regexHosts[host] {
+			padding := regexPrecedencePadding(paths, siblingPaths)
+			paths, warnings = translateRegexPaths(host, paths, padding)
+			result.Warnings = append(result.Warnings, warnings...)
+			if len(paths) == 0 {
+				continue
+			}
+			canaries = translateCanaryPaths(host, canaries, padding)
+		} else {
+			paths, warnings = fixPrefixPrecedence(host, paths, siblingPaths)
+			result.Warnings = append(result.Warnings, warnings...)
+		}
+
+		httpRoute := c.createHTTPRouteWithFilters(ctx, ingress, host, paths, annots, regexHosts[host])
+		if !regexHosts[host] {
+			result.Warnings = append(result.Warnings, matchCharacterPrefixes(httpRoute, host, paths, siblingPaths)...)
+		}
+		httpRouteFilters := c.generateRegexRewriteFilters(ingress, httpRoute, paths, annots)
+		result.HTTPRouteFilters = append(result.HTTPRouteFilters, httpRouteFilters...)
+		addRequestHeaderFilters(httpRoute, headers)
+		warnings = c.addCanaryRules(ctx, httpRoute, paths, canaries, regexHosts[host])
+		result.Warnings = append(result.Warnings, warnings...)
+		c.addSessionPersistence(httpRoute, annots)
+		protocols, sslRedirect := hostRouting(ingress, host, annots)
+		fallback := c.createParentRef()
+		if sslRedirect {
+			fallback = c.portParentRef(HTTPSListenerPort)
+		}
+		parentRefs, warnings := c.routeParentRefs(ingress, host, gatewayListeners, listeners, protocols, fallback)
+		httpRoute.Spec.ParentRefs = parentRefs
+		result.Warnings = append(result.Warnings, warnings...)
 		result.HTTPRoutes = append(result.HTTPRoutes, httpRoute)
 
+		// Redirect plain HTTP to HTTPS with a separate route on the HTTP listener
+		if sslRedirect {
+			redirectRoute := c.createSSLRedirectRoute(httpRoute)
+			parentRefs, warnings := c.routeParentRefs(ingress, host, gatewayListeners, listeners,
+				[]gatewayv1.ProtocolType{gatewayv1.HTTPProtocolType}, c.portParentRef(HTTPListenerPort))
+			redirectRoute.Spec.ParentRefs = parentRefs
+			result.Warnings = append(result.Warnings, warnings...)
+			result.HTTPRoutes = append(result.HTTPRoutes, redirectRoute)
+		}
+
 		// Generate BackendTrafficPolicy if needed
 		if btp := c.generateBackendTrafficPolicy(ingress, httpRoute, annots); btp != nil {
 			result.BackendTrafficPolicies = append(result.BackendTrafficPolicies, btp)
@@ -78,9 +248,23 @@ func (c *Converter) ConvertIngressFull(ctx context.Context, ingress *networkingv
 		}
 	}
 
+	// Redirect the www counterparts of the hosts for from-to-www-redirect
+	redirects, warnings := c.wwwRedirectHosts(ctx, ingress, annots, nil)
+	result.Warnings = append(result.Warnings, warnings...)
+	for _, counterpart := range slices.Sorted(maps.Keys(redirects)) {
+		redirectRoute := c.createWWWRedirectRoute(ingress, redirects[counterpart], counterpart)
+		protocols := []gatewayv1.ProtocolType{gatewayv1.HTTPSProtocolType, gatewayv1.HTTPProtocolType}
+		parentRefs, warnings := c.routeParentRefs(ingress, counterpart, gatewayListeners, listeners, protocols, c.createParentRef())
+		redirectRoute.Spec.ParentRefs = parentRefs
+		result.Warnings = append(result.Warnings, warnings...)
+		result.HTTPRoutes = append(result.HTTPRoutes, redirectRoute)
+	}
+
 	// Handle default backend if present and no other rules
 	if ingress.Spec.DefaultBackend != nil && len(result.HTTPRoutes) == 0 {
 		httpRoute := c.createDefaultBackendRoute(ctx, ingress)
+		addRequestHeaderFilters(httpRoute, headers)
+		c.addSessionPersistence(httpRoute, annots)
 		result.HTTPRoutes = append(result.HTTPRoutes, httpRoute)
 
 		// Generate BackendTrafficPolicy if needed
@@ -100,21 +284,28 @@ func (c *Converter) ConvertIngressFull(ctx context.Context, ingress *networkingv
 	}
 
 	// Generate BackendTLSPolicies for backend-protocol: HTTPS
+	result.Warnings = append(result.Warnings, c.upstreamTLSWarnings(ingress, annots)...)
 	if tlsPolicies := c.generateBackendTLSPolicies(ingress, result.HTTPRoutes, annots); len(tlsPolicies) > 0 {
 		result.BackendTLSPolicies = tlsPolicies
 	}
 
+	// Route to Backends that skip certificate verification for proxy-ssl-verify: off
+	backends, warnings := c.generateInsecureBackends(ingress, result.HTTPRoutes, annots)
+	result.Backends = backends
+	result.Warnings = append(result.Warnings, warnings...)
+
 	return result
 }
 
 // createHTTPRoute creates an HTTPRoute for a specific host.
 // This is the legacy method that doesn't apply filters.
 func (c *Converter) createHTTPRoute(ctx context.Context, ingress *networkingv1.Ingress, host string, paths []networkingv1.HTTPIngressPath) *gatewayv1.HTTPRoute {
-	return c.createHTTPRouteWithFilters(ctx, ingress, host, paths, nil)
+	return c.createHTTPRouteWithFilters(ctx, ingress, host, paths, nil, false)
 }
 
 // createHTTPRouteWithFilters creates an HTTPRoute for a specific host with optional filter support.
-func (c *Converter) createHTTPRouteWithFilters(ctx context.Context, ingress *networkingv1.Ingress, host string, paths []networkingv1.HTTPIngressPath, annots annotations.AnnotationSet) *gatewayv1.HTTPRoute {
+// useRegex converts every path to a regular expression, as ingress-nginx does on regex hosts.
+func (c *Converter) createHTTPRouteWithFilters(ctx context.Context, ingress *networkingv1.Ingress, host string, paths []networkingv1.HTTPIngressPath, annots annotations.AnnotationSet, useRegex bool) *gatewayv1.HTTPRoute {
 	routeName := c.generateRouteName(ingress, host)
 
 	httpRoute := &gatewayv1.HTTPRoute{
@@ -142,10 +333,15 @@ func (c *Converter) createHTTPRouteWithFilters(ctx context.Context, ingress *net
 
 	// Convert paths to rules with filters
 	for _, path := range paths {
-		rule := c.convertPathWithFilters(ctx, ingress.Namespace, path, annots)
+		rule := c.convertPathWithFilters(ctx, ingress.Namespace, path, annots, useRegex)
 		httpRoute.Spec.Rules = append(httpRoute.Spec.Rules, rule)
 	}
 
+	// Redirect "/" for app-root, even when no path matches it
+	if annots.HasAppRoot() {
+		addAppRootRedirect(httpRoute, annots)
+	}
+
 	return httpRoute
 }
 
@@ -182,17 +378,17 @@ func (c *Converter) createDefaultBackendRoute(ctx context.Context, ingress *netw
 // convertPath converts an Ingress path to an HTTPRouteRule.
 // This is the legacy method that doesn't apply filters.
 func (c *Converter) convertPath(ctx context.Context, namespace string, path networkingv1.HTTPIngressPath) gatewayv1.HTTPRouteRule {
-	return c.convertPathWithFilters(ctx, namespace, path, nil)
+	return c.convertPathWithFilters(ctx, namespace, path, nil, false)
 }
 
 // convertPathWithFilters converts an Ingress path to an HTTPRouteRule with optional filter support.
-func (c *Converter) convertPathWithFilters(ctx context.Context, namespace string, path networkingv1.HTTPIngressPath, annots annotations.AnnotationSet) gatewayv1.HTTPRouteRule {
+func (c *Converter) convertPathWithFilters(ctx context.Context, namespace string, path networkingv1.HTTPIngressPath, annots annotations.AnnotationSet, useRegex bool) gatewayv1.HTTPRouteRule {
 	rule := gatewayv1.HTTPRouteRule{}
 
 	// Convert path match
 	originalPath := path.Path
 	if originalPath != "" {
-		pathMatch := c.convertPathMatch(path, annots)
+		pathMatch := c.convertPathMatch(path, useRegex)
 		rule.Matches = []gatewayv1.HTTPRouteMatch{
 			{
 				Path: &pathMatch,
@@ -203,7 +399,7 @@ func (c *Converter) convertPathWithFilters(ctx context.Context, namespace string
 	// Apply filters from annotations
 	hasRedirect := false
 	if annots != nil && annots.HasHTTPRouteFilters() {
-		hasRedirect = applyFilters(&rule, annots, originalPath)
+		hasRedirect = applyFilters(&rule, annots)
 	}
 
 	// Convert backend (skip if redirect filter is applied)
@@ -217,94 +413,35 @@ func (c *Converter) convertPathWithFilters(ctx context.Context, namespace string
 }
 
 // convertPathMatch converts an Ingress path type to Gateway API path match.
-func (c *Converter) convertPathMatch(path networkingv1.HTTPIngressPath, annots annotations.AnnotationSet) gatewayv1.HTTPPathMatch {
-	pathValue := path.Path
-	pathType := networkingv1.PathTypePrefix
-	if path.PathType != nil {
-		pathType = *path.PathType
-	}
-
-	// Check if regex is enabled via annotation
-	useRegex := false
-	if annots != nil {
-		if v, ok := annots.GetBool(annotations.UseRegex); ok && v {
-			useRegex = true
+// On regex hosts, every path type becomes a case-insensitive regular expression
+// matching paths that start with it, like an ingress-nginx ~* location.
+func (c *Converter) convertPathMatch(path networkingv1.HTTPIngressPath, useRegex bool) gatewayv1.HTTPPathMatch {
+	if useRegex {
+		return gatewayv1.HTTPPathMatch{
+			Type:  ptr(gatewayv1.PathMatchRegularExpression),
+			Value: ptr(regexPath(path.Path)),
 		}
 	}
 
-	// Check if rewrite-target with capture groups is used
-	// In this case, we need to use PathPrefix match (not regex) for Gateway API compatibility
-	hasRewriteWithCapture := false
-	if annots != nil {
-		if rewrite, ok := annots.GetString(annotations.RewriteTarget); ok {
-			hasRewriteWithCapture = containsCaptureGroups(rewrite)
-		}
-	}
-
-	// If using regex path with rewrite capture groups, extract the static prefix
-	// Gateway API requires PathPrefix match when using ReplacePrefixMatch
-	if useRegex && hasRewriteWithCapture {
-		pathValue = extractStaticPrefix(path.Path)
-		useRegex = false // Force PathPrefix match
+	pathType := networkingv1.PathTypePrefix
+	if path.PathType != nil {
+		pathType = *path.PathType
 	}
 
 	pathMatch := gatewayv1.HTTPPathMatch{
-		Value: ptr(pathValue),
+		Value: ptr(path.Path),
 	}
 
 	switch pathType {
 	case networkingv1.PathTypeExact:
 		pathMatch.Type = ptr(gatewayv1.PathMatchExact)
-	case networkingv1.PathTypePrefix:
-		if useRegex {
-			pathMatch.Type = ptr(gatewayv1.PathMatchRegularExpression)
-		} else {
-			pathMatch.Type = ptr(gatewayv1.PathMatchPathPrefix)
-		}
-	case networkingv1.PathTypeImplementationSpecific:
-		// For implementation-specific, check if regex is enabled
-		if useRegex {
-			pathMatch.Type = ptr(gatewayv1.PathMatchRegularExpression)
-		} else {
-			pathMatch.Type = ptr(gatewayv1.PathMatchPathPrefix)
-		}
+	case networkingv1.PathTypePrefix, networkingv1.PathTypeImplementationSpecific:
+		pathMatch.Type = ptr(gatewayv1.PathMatchPathPrefix)
 	}
 
 	return pathMatch
 }
 
-// extractStaticPrefix extracts the static prefix from a regex path.
-// Examples:
-//   - /data(/|$)(.*) → /data
-//   - /()(.*) → /
-//   - /api/v[0-9]+/(.*) → /api/v (best effort)
-func extractStaticPrefix(regexPath string) string {
-	if regexPath == "" {
-		return "/"
-	}
-
-	// Find where the regex pattern starts
-	// Common patterns: (, [, *, +, ?, {, |, \, $
-	regexChars := []byte{'(', '[', '*', '+', '?', '{', '|', '\\', '$'}
-
-	minIdx := len(regexPath)
-	for _, ch := range regexChars {
-		if idx := strings.IndexByte(regexPath, ch); idx >= 0 && idx < minIdx {
-			minIdx = idx
-		}
-	}
-
-	prefix := regexPath[:minIdx]
-
-	// Clean up trailing slashes but keep at least "/"
-	prefix = strings.TrimRight(prefix, "/")
-	if prefix == "" {
-		prefix = "/"
-	}
-
-	return prefix
-}
-
 // convertIngressBackend converts an Ingress backend to an HTTPBackendRef.
 func (c *Converter) convertIngressBackend(ctx context.Context, namespace string, backend networkingv1.IngressBackend) gatewayv1.HTTPBackendRef {
 	if backend.Service != nil {
@@ -375,10 +512,13 @@ func (c *Converter) generateRouteName(ingress *networkingv1.Ingress, host string
 	if host == "" {
 		return ingress.Name
 	}
-	// Sanitize host for use in resource name
-	sanitized := strings.ReplaceAll(host, ".", "-")
-	sanitized = strings.ReplaceAll(sanitized, "*", "wildcard")
-	return fmt.Sprintf("%s-%s", ingress.Name, sanitized)
+	return fmt.Sprintf("%s-%s", ingress.Name, sanitizeHost(host))
+}
+
+// sanitizeHost converts a hostname for use in resource and listener names.
+func sanitizeHost(host string) string {
+	sanitized := strings.ReplaceAll(strings.ToLower(host), ".", "-")
+	return strings.ReplaceAll(sanitized, "*", "wildcard")
 }
 
 // copyLabels creates a copy of labels map.
@@ -273,13 +273,13 @@ func TestConvertIngress(t *testing.T) {
 				if pathMatch == nil || *pathMatch.Type != gatewayv1.PathMatchRegularExpression {
 					t.Errorf("expected regex path match, got %v", pathMatch)
 				}
-				if *pathMatch.Value != "/api/v[0-9]+/.*" {
-					t.Errorf("expected path /api/v[0-9]+/.*, got %s", *pathMatch.Value)
+				if *pathMatch.Value != "(?i)/api/v[0-9]+/.*.*" {
+					t.Errorf("expected path (?i)/api/v[0-9]+/.*.*, got %s", *pathMatch.Value)
 				}
 			},
 		},
 		{
-			name: "regex path with rewrite-target capture group converts to PathPrefix",
+			name: "regex path with rewrite-target capture group stays regex",
 			ingress: &networkingv1.Ingress{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "rewrite-regex-ingress",
@@ -326,21 +326,20 @@ func TestConvertIngress(t *testing.T) {
 					t.Fatalf("expected 1 match, got %d", len(route.Spec.Rules[0].Matches))
 				}
 				pathMatch := route.Spec.Rules[0].Matches[0].Path
-				// Should be PathPrefix, not regex, because rewrite-target has capture groups
-				if pathMatch == nil || *pathMatch.Type != gatewayv1.PathMatchPathPrefix {
-					t.Errorf("expected PathPrefix match for rewrite with capture groups, got %v", pathMatch.Type)
+				if pathMatch == nil || *pathMatch.Type != gatewayv1.PathMatchRegularExpression {
+					t.Errorf("expected regex match for rewrite with capture groups, got %v", pathMatch.Type)
 				}
-				// Path should be the extracted static prefix
-				if *pathMatch.Value != "/data" {
-					t.Errorf("expected path /data, got %s", *pathMatch.Value)
+				if *pathMatch.Value != "(?i)/data(/|$)(.*).*" {
+					t.Errorf("expected path (?i)/data(/|$)(.*).*, got %s", *pathMatch.Value)
 				}
-				// Should have a URLRewrite filter
+				// Should reference the regex rewrite HTTPRouteFilter
 				if len(route.Spec.Rules[0].Filters) != 1 {
 					t.Fatalf("expected 1 filter, got %d", len(route.Spec.Rules[0].Filters))
 				}
 				filter := route.Spec.Rules[0].Filters[0]
-				if filter.Type != gatewayv1.HTTPRouteFilterURLRewrite {
-					t.Errorf("expected URLRewrite filter, got %v", filter.Type)
+				if filter.Type != gatewayv1.HTTPRouteFilterExtensionRef || filter.ExtensionRef == nil ||
+					filter.ExtensionRef.Name != "rewrite-regex-ingress-example-com-rewrite-0" {
+					t.Errorf("expected ExtensionRef filter, got %+v", filter)
 				}
 			},
 		},
@@ -449,29 +448,3 @@ func TestGenerateRouteName(t *testing.T) {
 		})
 	}
 }
-
-func TestExtractStaticPrefix(t *testing.T) {
-	tests := []struct {
-		regexPath string
-		want      string
-	}{
-		{"/data(/|$)(.*)", "/data"},
-		{"/()(.*)", "/"},
-		{"/api/v1(/|$)(.*)", "/api/v1"},
-		{"/auth/realms(/|$)(.*)", "/auth/realms"},
-		{"/foo/bar", "/foo/bar"},
-		{"", "/"},
-		{"/", "/"},
-		{"/prefix(.*)", "/prefix"},
-		{"/with[0-9]+regex", "/with"},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.regexPath, func(t *testing.T) {
-			got := extractStaticPrefix(tt.regexPath)
-			if got != tt.want {
-				t.Errorf("extractStaticPrefix(%q) = %q, want %q", tt.regexPath, got, tt.want)
-			}
-		})
-	}
-}
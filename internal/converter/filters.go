@@ -1,19 +1,24 @@
 package converter
 
 import (
+	"fmt"
 	"regexp"
-	"strings"
+	"slices"
 
+	egv1alpha1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	"github.com/werdnum/ingress-gateway-api/internal/annotations"
 )
 
 // addRewriteFilter adds a URLRewrite filter to the rule based on rewrite-target annotation.
-// Handles nginx capture group syntax (e.g., /$1, /$2) by converting to Gateway API format.
-func addRewriteFilter(rule *gatewayv1.HTTPRouteRule, annots annotations.AnnotationSet, originalPath string) {
+// Targets with nginx capture groups (e.g., /$1, /$2) are handled by
+// generateRegexRewriteFilters instead, as URLRewrite cannot use regex captures.
+func addRewriteFilter(rule *gatewayv1.HTTPRouteRule, annots annotations.AnnotationSet) {
 	rewriteTarget, ok := annots.GetString(annotations.RewriteTarget)
-	if !ok {
+	if !ok || containsCaptureGroups(rewriteTarget) {
 		return
 	}
 
@@ -24,72 +29,120 @@ func addRewriteFilter(rule *gatewayv1.HTTPRouteRule, annots annotations.Annotati
 		},
 	}
 
-	// Check if the rewrite target contains capture groups
-	if containsCaptureGroups(rewriteTarget) {
-		// Use ReplacePrefixMatch for capture group replacement
-		// This is a simplified approach - full regex support would require ExtensionRef
+	// Simple static replacement. Prefix replacement is not allowed with regex matches,
+	// where ingress-nginx replaces the whole path anyway.
+	if rewriteTarget == "/" && !hasRegexMatch(rule) {
 		filter.URLRewrite.Path.Type = gatewayv1.PrefixMatchHTTPPathModifier
-		filter.URLRewrite.Path.ReplacePrefixMatch = ptr(convertCaptureGroups(rewriteTarget, originalPath))
+		filter.URLRewrite.Path.ReplacePrefixMatch = ptr("/")
 	} else {
-		// Simple static replacement
-		if rewriteTarget == "/" {
-			filter.URLRewrite.Path.Type = gatewayv1.PrefixMatchHTTPPathModifier
-			filter.URLRewrite.Path.ReplacePrefixMatch = ptr("/")
-		} else {
-			filter.URLRewrite.Path.Type = gatewayv1.FullPathHTTPPathModifier
-			filter.URLRewrite.Path.ReplaceFullPath = ptr(rewriteTarget)
-		}
+		filter.URLRewrite.Path.Type = gatewayv1.FullPathHTTPPathModifier
+		filter.URLRewrite.Path.ReplaceFullPath = ptr(rewriteTarget)
 	}
 
 	rule.Filters = append(rule.Filters, filter)
 }
 
+// hasRegexMatch reports whether the rule matches paths with a regular expression.
+func hasRegexMatch(rule *gatewayv1.HTTPRouteRule) bool {
+	return slices.ContainsFunc(rule.Matches, func(match gatewayv1.HTTPRouteMatch) bool {
+		return match.Path != nil && match.Path.Type != nil && *match.Path.Type == gatewayv1.PathMatchRegularExpression
+	})
+}
+
+// captureGroupPattern matches nginx capture group references such as $1.
+var captureGroupPattern = regexp.MustCompile(`\$(\d+)`)
+
 // containsCaptureGroups checks if the rewrite target contains nginx capture group references.
 func containsCaptureGroups(target string) bool {
-	// Check for $1, $2, etc.
-	return regexp.MustCompile(`\$\d+`).MatchString(target)
+	return captureGroupPattern.MatchString(target)
 }
 
-// convertCaptureGroups converts nginx capture group syntax to a static path.
-// This is a best-effort conversion as Gateway API doesn't support regex capture groups.
-// For complex patterns, users should use ExtensionRef with a custom filter.
-func convertCaptureGroups(target string, originalPath string) string {
-	// If the target is just $1 or /$1, use prefix replacement
-	if target == "/$1" || target == "$1" {
-		// Return empty string for prefix replacement - the matched prefix will be removed
-		return "/"
-	}
+// RegexRewriteFilterName returns the name of the HTTPRouteFilter for a rule of an HTTPRoute.
+func RegexRewriteFilterName(routeName string, rule int) string {
+	return fmt.Sprintf("%s-rewrite-%d", routeName, rule)
+}
 
-	// For more complex patterns, strip the capture group references
-	// and return what remains
-	result := regexp.MustCompile(`\$\d+`).ReplaceAllString(target, "")
+// generateRegexRewriteFilters handles rewrite-target with capture groups. Each rule of
+// the HTTPRoute that routes to a backend gets an Envoy Gateway HTTPRouteFilter that
+// rewrites the path with the regex of its Ingress path, referenced through ExtensionRef.
+// Like the nginx rewrite directive, the whole path is replaced by the target, so the
+// pattern is case-insensitive, anchored and consumes the rest of the path.
+func (c *Converter) generateRegexRewriteFilters(
+	ingress *networkingv1.Ingress,
+	httpRoute *gatewayv1.HTTPRoute,
+	paths []networkingv1.HTTPIngressPath,
+	annots annotations.AnnotationSet,
+) []*egv1alpha1.HTTPRouteFilter {
+	rewriteTarget, ok := annots.GetString(annotations.RewriteTarget)
+	if !ok || !containsCaptureGroups(rewriteTarget) {
+		return nil
+	}
+	substitution := captureGroupPattern.ReplaceAllString(rewriteTarget, `\${1}`)
 
-	// Clean up any double slashes
-	result = strings.ReplaceAll(result, "//", "/")
+	var filters []*egv1alpha1.HTTPRouteFilter
+	for i := range httpRoute.Spec.Rules {
+		rule := &httpRoute.Spec.Rules[i]
+		if i >= len(paths) || paths[i].Path == "" || len(rule.BackendRefs) == 0 {
+			continue
+		}
 
-	if result == "" {
-		result = "/"
+		name := RegexRewriteFilterName(httpRoute.Name, i)
+		filters = append(filters, &egv1alpha1.HTTPRouteFilter{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: ingress.Namespace,
+				Labels:    copyLabels(ingress.Labels),
+				Annotations: map[string]string{
+					"ingress-gateway-api.io/source": fmt.Sprintf("%s/%s", ingress.Namespace, ingress.Name),
+				},
+			},
+			Spec: egv1alpha1.HTTPRouteFilterSpec{
+				URLRewrite: &egv1alpha1.HTTPURLRewriteFilter{
+					Path: &egv1alpha1.HTTPPathModifier{
+						Type: egv1alpha1.RegexHTTPPathModifier,
+						ReplaceRegexMatch: &egv1alpha1.ReplaceRegexMatch{
+							Pattern:      fmt.Sprintf("(?i)^(?:%s).*", paths[i].Path),
+							Substitution: substitution,
+						},
+					},
+				},
+			},
+		})
+		rule.Filters = append(rule.Filters, gatewayv1.HTTPRouteFilter{
+			Type: gatewayv1.HTTPRouteFilterExtensionRef,
+			ExtensionRef: &gatewayv1.LocalObjectReference{
+				Group: gatewayv1.Group(egv1alpha1.GroupName),
+				Kind:  gatewayv1.Kind(egv1alpha1.KindHTTPRouteFilter),
+				Name:  gatewayv1.ObjectName(name),
+			},
+		})
 	}
 
-	return result
+	return filters
 }
 
-// addAppRootRedirect adds a RequestRedirect filter for app-root annotation.
-// This redirects requests to "/" to the specified app root.
-func addAppRootRedirect(rule *gatewayv1.HTTPRouteRule, annots annotations.AnnotationSet) {
+// addAppRootRedirect redirects requests for "/" to the app-root annotation. Like the
+// `if ($uri = /)` block ingress-nginx adds to the server, only "/" itself is redirected,
+// whatever paths the Ingress has: a rule matching exactly "/" becomes the redirect,
+// otherwise an Exact "/" rule is added, and prefix or regex rules keep serving the
+// other paths. The added rule goes last so rules still line up with the Ingress paths.
+func addAppRootRedirect(httpRoute *gatewayv1.HTTPRoute, annots annotations.AnnotationSet) {
 	appRoot, ok := annots.GetString(annotations.AppRoot)
 	if !ok {
 		return
 	}
 
-	// Only apply to root path
-	if len(rule.Matches) == 0 {
-		return
-	}
-
-	for _, match := range rule.Matches {
-		if match.Path != nil && match.Path.Value != nil && *match.Path.Value == "/" {
-			filter := gatewayv1.HTTPRouteFilter{
+	redirect := gatewayv1.HTTPRouteRule{
+		Matches: []gatewayv1.HTTPRouteMatch{
+			{
+				Path: &gatewayv1.HTTPPathMatch{
+					Type:  ptr(gatewayv1.PathMatchExact),
+					Value: ptr("/"),
+				},
+			},
+		},
+		Filters: []gatewayv1.HTTPRouteFilter{
+			{
 				Type: gatewayv1.HTTPRouteFilterRequestRedirect,
 				RequestRedirect: &gatewayv1.HTTPRequestRedirectFilter{
 					Path: &gatewayv1.HTTPPathModifier{
@@ -98,30 +151,41 @@ func addAppRootRedirect(rule *gatewayv1.HTTPRouteRule, annots annotations.Annota
 					},
 					StatusCode: ptr(302),
 				},
+			},
+		},
+	}
+
+	for i, rule := range httpRoute.Spec.Rules {
+		for _, match := range rule.Matches {
+			if match.Path != nil && match.Path.Type != nil && *match.Path.Type == gatewayv1.PathMatchExact &&
+				match.Path.Value != nil && *match.Path.Value == "/" {
+				httpRoute.Spec.Rules[i] = redirect
+				return
 			}
-			rule.Filters = append(rule.Filters, filter)
-			return
 		}
 	}
+	httpRoute.Spec.Rules = append(httpRoute.Spec.Rules, redirect)
 }
 
 // applyFilters applies all annotation-based filters to an HTTPRoute rule.
 // Returns true if any redirect filter was applied (meaning backend refs should be removed).
-func applyFilters(rule *gatewayv1.HTTPRouteRule, annots annotations.AnnotationSet, originalPath string) bool {
+func applyFilters(rule *gatewayv1.HTTPRouteRule, annots annotations.AnnotationSet) bool {
 	hasRedirect := false
 
-	// Check for app root redirect on root path
-	if annots.HasAppRoot() {
-		beforeLen := len(rule.Filters)
-		addAppRootRedirect(rule, annots)
-		if len(rule.Filters) > beforeLen {
+	// Redirect every path for permanent-redirect and temporal-redirect
+	if annots.HasRedirect() {
+		if redirect, _ := annotationRedirect(annots); redirect != nil {
+			rule.Filters = append(rule.Filters, gatewayv1.HTTPRouteFilter{
+				Type:            gatewayv1.HTTPRouteFilterRequestRedirect,
+				RequestRedirect: redirect,
+			})
 			hasRedirect = true
 		}
 	}
 
 	// Add rewrite filter if not a redirect
 	if !hasRedirect && annots.HasRewrite() {
-		addRewriteFilter(rule, annots, originalPath)
+		addRewriteFilter(rule, annots)
 	}
 
 	return hasRedirect
@@ -1,42 +1,36 @@
 package converter
 
 import (
+	"context"
+	"regexp"
 	"testing"
 
+	networkingv1 "k8s.io/api/networking/v1"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	"github.com/werdnum/ingress-gateway-api/internal/annotations"
+	"github.com/werdnum/ingress-gateway-api/internal/config"
 )
 
 func TestAddRewriteFilter(t *testing.T) {
 	tests := []struct {
 		name           string
 		rewriteTarget  string
-		originalPath   string
 		wantFilterType gatewayv1.HTTPRouteFilterType
 		wantPathType   gatewayv1.HTTPPathModifierType
 	}{
 		{
 			name:           "simple rewrite to root",
 			rewriteTarget:  "/",
-			originalPath:   "/api",
 			wantFilterType: gatewayv1.HTTPRouteFilterURLRewrite,
 			wantPathType:   gatewayv1.PrefixMatchHTTPPathModifier,
 		},
 		{
 			name:           "rewrite to specific path",
 			rewriteTarget:  "/v2",
-			originalPath:   "/api",
 			wantFilterType: gatewayv1.HTTPRouteFilterURLRewrite,
 			wantPathType:   gatewayv1.FullPathHTTPPathModifier,
 		},
-		{
-			name:           "capture group rewrite",
-			rewriteTarget:  "/$1",
-			originalPath:   "/api/(.*)",
-			wantFilterType: gatewayv1.HTTPRouteFilterURLRewrite,
-			wantPathType:   gatewayv1.PrefixMatchHTTPPathModifier,
-		},
 	}
 
 	for _, tt := range tests {
@@ -46,7 +40,7 @@ func TestAddRewriteFilter(t *testing.T) {
 				"nginx.ingress.kubernetes.io/rewrite-target": tt.rewriteTarget,
 			})
 
-			addRewriteFilter(rule, annots, tt.originalPath)
+			addRewriteFilter(rule, annots)
 
 			if len(rule.Filters) != 1 {
 				t.Errorf("expected 1 filter, got %d", len(rule.Filters))
@@ -72,62 +66,84 @@ func TestAddRewriteFilter(t *testing.T) {
 
 func TestAddAppRootRedirect(t *testing.T) {
 	tests := []struct {
-		name       string
-		appRoot    string
-		pathValue  string
-		wantFilter bool
+		name      string
+		pathType  gatewayv1.PathMatchType
+		pathValue string
+		wantRules int
 	}{
 		{
-			name:       "root path gets redirect",
-			appRoot:    "/app",
-			pathValue:  "/",
-			wantFilter: true,
+			name:      "no root path",
+			pathType:  gatewayv1.PathMatchPathPrefix,
+			pathValue: "/app",
+			wantRules: 2,
 		},
 		{
-			name:       "non-root path no redirect",
-			appRoot:    "/app",
-			pathValue:  "/api",
-			wantFilter: false,
+			name:      "root prefix keeps serving other paths",
+			pathType:  gatewayv1.PathMatchPathPrefix,
+			pathValue: "/",
+			wantRules: 2,
+		},
+		{
+			name:      "root regex keeps serving other paths",
+			pathType:  gatewayv1.PathMatchRegularExpression,
+			pathValue: "(?i)/.*",
+			wantRules: 2,
+		},
+		{
+			name:      "exact root becomes the redirect",
+			pathType:  gatewayv1.PathMatchExact,
+			pathValue: "/",
+			wantRules: 1,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			rule := &gatewayv1.HTTPRouteRule{
-				Matches: []gatewayv1.HTTPRouteMatch{
-					{
-						Path: &gatewayv1.HTTPPathMatch{
-							Value: ptr(tt.pathValue),
+			httpRoute := &gatewayv1.HTTPRoute{
+				Spec: gatewayv1.HTTPRouteSpec{
+					Rules: []gatewayv1.HTTPRouteRule{
+						{
+							Matches: []gatewayv1.HTTPRouteMatch{
+								{
+									Path: &gatewayv1.HTTPPathMatch{
+										Type:  ptr(tt.pathType),
+										Value: ptr(tt.pathValue),
+									},
+								},
+							},
+							BackendRefs: []gatewayv1.HTTPBackendRef{{}},
 						},
 					},
 				},
 			}
 			annots := annotations.NewAnnotationSet(map[string]string{
-				"nginx.ingress.kubernetes.io/app-root": tt.appRoot,
+				"nginx.ingress.kubernetes.io/app-root": "/app",
 			})
 
-			addAppRootRedirect(rule, annots)
+			addAppRootRedirect(httpRoute, annots)
 
-			if tt.wantFilter && len(rule.Filters) == 0 {
-				t.Error("expected filter to be added")
+			rules := httpRoute.Spec.Rules
+			if len(rules) != tt.wantRules {
+				t.Fatalf("expected %d rules, got %d", tt.wantRules, len(rules))
 			}
-			if !tt.wantFilter && len(rule.Filters) > 0 {
-				t.Error("expected no filter to be added")
+			if len(rules) > 1 && (len(rules[0].Filters) != 0 || len(rules[0].BackendRefs) != 1) {
+				t.Errorf("expected the original rule to be unchanged, got %+v", rules[0])
 			}
 
-			if tt.wantFilter && len(rule.Filters) > 0 {
-				filter := rule.Filters[0]
-				if filter.Type != gatewayv1.HTTPRouteFilterRequestRedirect {
-					t.Errorf("expected RequestRedirect filter, got %s", filter.Type)
-				}
-				if filter.RequestRedirect == nil || filter.RequestRedirect.Path == nil {
-					t.Error("expected redirect path config")
-					return
-				}
-				if filter.RequestRedirect.Path.ReplaceFullPath == nil ||
-					*filter.RequestRedirect.Path.ReplaceFullPath != tt.appRoot {
-					t.Errorf("expected redirect to %s", tt.appRoot)
-				}
+			redirect := rules[len(rules)-1]
+			match := redirect.Matches[0].Path
+			if *match.Type != gatewayv1.PathMatchExact || *match.Value != "/" {
+				t.Errorf("expected an Exact / match, got %s %s", *match.Type, *match.Value)
+			}
+			if len(redirect.BackendRefs) != 0 {
+				t.Error("expected no backends on the redirect rule")
+			}
+			if len(redirect.Filters) != 1 || redirect.Filters[0].Type != gatewayv1.HTTPRouteFilterRequestRedirect {
+				t.Fatalf("expected a RequestRedirect filter, got %+v", redirect.Filters)
+			}
+			path := redirect.Filters[0].RequestRedirect.Path
+			if path == nil || path.ReplaceFullPath == nil || *path.ReplaceFullPath != "/app" {
+				t.Errorf("expected redirect to /app, got %+v", path)
 			}
 		})
 	}
@@ -169,7 +185,7 @@ func TestApplyFilters(t *testing.T) {
 			}
 			annots := annotations.NewAnnotationSet(tt.annotations)
 
-			hasRedirect := applyFilters(rule, annots, "/test")
+			hasRedirect := applyFilters(rule, annots)
 
 			if hasRedirect != tt.wantRedirect {
 				t.Errorf("expected hasRedirect = %v, got %v", tt.wantRedirect, hasRedirect)
@@ -202,20 +218,82 @@ func TestContainsCaptureGroups(t *testing.T) {
 	}
 }
 
-func TestConvertCaptureGroups(t *testing.T) {
+func TestConvertIngressFull_RegexRewriteFilters(t *testing.T) {
 	tests := []struct {
-		target string
-		want   string
+		name          string
+		path          string
+		rewriteTarget string
+		requestPath   string
+		wantPath      string
 	}{
-		{"/$1", "/"},
-		{"$1", "/"},
-		{"/api/$1/path", "/api/path"},
+		{
+			name:          "versioned API prefix",
+			path:          "/api/v[0-9]+/(.*)",
+			rewriteTarget: "/$1",
+			requestPath:   "/api/v2/users/42",
+			wantPath:      "/users/42",
+		},
+		{
+			name:          "optional trailing slash",
+			path:          "/data(/|$)(.*)",
+			rewriteTarget: "/$2",
+			requestPath:   "/data/items",
+			wantPath:      "/items",
+		},
+		{
+			name:          "whole path is replaced",
+			path:          "/old/([0-9]+)",
+			rewriteTarget: "/new/$1/view",
+			requestPath:   "/old/12/extra",
+			wantPath:      "/new/12/view",
+		},
+		{
+			name:          "case-insensitive like nginx",
+			path:          "/Data/(.*)",
+			rewriteTarget: "/$1",
+			requestPath:   "/DATA/Items",
+			wantPath:      "/Items",
+		},
 	}
 
 	for _, tt := range tests {
-		t.Run(tt.target, func(t *testing.T) {
-			if got := convertCaptureGroups(tt.target, "/original"); got != tt.want {
-				t.Errorf("convertCaptureGroups(%s) = %v, want %v", tt.target, got, tt.want)
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(&config.Config{
+				GatewayName:      "eg-gateway",
+				GatewayNamespace: "envoy-gateway",
+			})
+			ingress := tlsTestIngress([]string{"example.com"}, nil)
+			ingress.Spec.Rules[0].HTTP.Paths[0].Path = tt.path
+			ingress.Spec.Rules[0].HTTP.Paths[0].PathType = ptr(networkingv1.PathTypeImplementationSpecific)
+			ingress.Annotations = map[string]string{annotations.RewriteTarget: tt.rewriteTarget}
+
+			result := c.ConvertIngressFull(context.Background(), ingress)
+
+			if len(result.HTTPRouteFilters) != 1 {
+				t.Fatalf("expected 1 HTTPRouteFilter, got %d", len(result.HTTPRouteFilters))
+			}
+			filter := result.HTTPRouteFilters[0]
+			if filter.Name != "test-ingress-example-com-rewrite-0" || filter.Namespace != "default" {
+				t.Errorf("unexpected HTTPRouteFilter %s/%s", filter.Namespace, filter.Name)
+			}
+
+			rule := result.HTTPRoutes[0].Spec.Rules[0]
+			wantMatch := "(?i)" + tt.path + ".*"
+			if match := rule.Matches[0].Path; *match.Type != gatewayv1.PathMatchRegularExpression || *match.Value != wantMatch {
+				t.Errorf("expected regex match %s, got %s %s", wantMatch, *match.Type, *match.Value)
+			}
+			if len(rule.Filters) != 1 || rule.Filters[0].ExtensionRef == nil ||
+				string(rule.Filters[0].ExtensionRef.Name) != filter.Name ||
+				rule.Filters[0].ExtensionRef.Kind != "HTTPRouteFilter" {
+				t.Fatalf("expected an ExtensionRef to %s, got %+v", filter.Name, rule.Filters)
+			}
+
+			// Envoy substitutes \N; Go's regexp uses ${N}
+			rewrite := filter.Spec.URLRewrite.Path.ReplaceRegexMatch
+			pattern := regexp.MustCompile(rewrite.Pattern)
+			substitution := regexp.MustCompile(`\\(\d+)`).ReplaceAllString(rewrite.Substitution, `$${$1}`)
+			if got := pattern.ReplaceAllString(tt.requestPath, substitution); got != tt.wantPath {
+				t.Errorf("expected %s to be rewritten to %s, got %s", tt.requestPath, tt.wantPath, got)
 			}
 		})
 	}
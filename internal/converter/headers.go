@@ -0,0 +1,176 @@
+package converter
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net"
+	"regexp"
+	"slices"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/werdnum/ingress-gateway-api/internal/annotations"
+)
+
+// maxSetHeaders is the number of headers a RequestHeaderModifier filter can set.
+const maxSetHeaders = 16
+
+// headerNamePattern matches the header names Gateway API accepts.
+var headerNamePattern = regexp.MustCompile("^[A-Za-z0-9!#$%&'*+\\-.^_`|~]+$")
+
+// requestHeaders is how the annotations modify requests sent to the backends.
+type requestHeaders struct {
+	// set are the headers to set, sorted by name.
+	set []gatewayv1.HTTPHeader
+
+	// hostname replaces the Host header. Empty leaves it unchanged.
+	hostname string
+}
+
+// requestHeaderSettings reads x-forwarded-prefix, upstream-vhost and the ConfigMap named
+// by proxy-set-headers. Values with nginx variables cannot be converted and are skipped.
+// A Host entry in the ConfigMap is treated like upstream-vhost, which takes precedence,
+// as Envoy does not let header modifiers change the Host header.
+func (c *Converter) requestHeaderSettings(
+	ctx context.Context,
+	ingress *networkingv1.Ingress,
+	annots annotations.AnnotationSet,
+) (requestHeaders, []string) {
+	var settings requestHeaders
+	var warnings []string
+	set := make(map[string]gatewayv1.HTTPHeader)
+
+	if ref, ok := annots.GetString(annotations.ProxySetHeaders); ok {
+		data, configMapWarnings := c.proxySetHeaders(ctx, ingress, ref)
+		warnings = append(warnings, configMapWarnings...)
+		for _, name := range slices.Sorted(maps.Keys(data)) {
+			value := data[name]
+			switch {
+			case !headerNamePattern.MatchString(name):
+				warnings = append(warnings, fmt.Sprintf("proxy-set-headers entry %q is not a valid header name and is ignored", name))
+			case strings.Contains(value, "$"):
+				warnings = append(warnings, fmt.Sprintf(
+					"proxy-set-headers entry %s uses nginx variables in %q and is ignored", name, value))
+			case strings.EqualFold(name, "Host"):
+				settings.hostname = value
+			default:
+				set[strings.ToLower(name)] = gatewayv1.HTTPHeader{Name: gatewayv1.HTTPHeaderName(name), Value: value}
+			}
+		}
+	}
+
+	if prefix, ok := annots.GetString(annotations.XForwardedPrefix); ok {
+		if strings.Contains(prefix, "$") {
+			warnings = append(warnings, fmt.Sprintf("x-forwarded-prefix %q uses nginx variables and is ignored", prefix))
+		} else {
+			set["x-forwarded-prefix"] = gatewayv1.HTTPHeader{Name: "X-Forwarded-Prefix", Value: prefix}
+		}
+	}
+
+	if vhost, ok := annots.GetString(annotations.UpstreamVhost); ok {
+		settings.hostname = vhost
+	}
+	if settings.hostname != "" {
+		hostname, hostnameWarnings := upstreamHostname(settings.hostname)
+		settings.hostname = hostname
+		warnings = append(warnings, hostnameWarnings...)
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(set)) {
+		if len(settings.set) == maxSetHeaders {
+			warnings = append(warnings, fmt.Sprintf(
+				"only %d request headers can be set, %s and later headers are ignored", maxSetHeaders, set[key].Name))
+			break
+		}
+		settings.set = append(settings.set, set[key])
+	}
+
+	return settings, warnings
+}
+
+// proxySetHeaders returns the entries of the ConfigMap named by proxy-set-headers.
+// Unlike ingress-nginx, the ConfigMap must be in the Ingress namespace, so that an
+// Ingress cannot copy ConfigMaps from other namespaces into requests.
+func (c *Converter) proxySetHeaders(ctx context.Context, ingress *networkingv1.Ingress, ref string) (map[string]string, []string) {
+	namespace, name := ProxySetHeadersConfigMap(ingress.Namespace, ref)
+	if namespace != ingress.Namespace || name == "" {
+		return nil, []string{fmt.Sprintf(
+			"proxy-set-headers %q must name a ConfigMap in namespace %s; its headers are not set", ref, ingress.Namespace)}
+	}
+
+	data, found, err := c.configMaps.ResolveConfigMap(ctx, namespace, name)
+	if err != nil {
+		return nil, []string{fmt.Sprintf("proxy-set-headers ConfigMap %s/%s could not be read: %v", namespace, name, err)}
+	}
+	if !found {
+		return nil, []string{fmt.Sprintf("proxy-set-headers ConfigMap %s/%s not found", namespace, name)}
+	}
+	return data, nil
+}
+
+// ProxySetHeadersConfigMap returns the namespace and name of the ConfigMap a
+// proxy-set-headers value refers to. A bare name is in the Ingress namespace.
+func ProxySetHeadersConfigMap(ingressNamespace, ref string) (string, string) {
+	return splitNamespacedName(ref, ingressNamespace)
+}
+
+// upstreamHostname converts an upstream-vhost value to a URLRewrite hostname, which
+// cannot have a port or nginx variables.
+func upstreamHostname(vhost string) (string, []string) {
+	if strings.Contains(vhost, "$") {
+		return "", []string{fmt.Sprintf("upstream-vhost %q uses nginx variables and is ignored", vhost)}
+	}
+
+	var warnings []string
+	hostname := vhost
+	if host, port, err := net.SplitHostPort(vhost); err == nil {
+		hostname = host
+		warnings = append(warnings, fmt.Sprintf("upstream-vhost port %s cannot be set and is dropped from the Host header", port))
+	}
+	hostname = strings.ToLower(hostname)
+	if errs := validation.IsDNS1123Subdomain(hostname); len(errs) > 0 {
+		return "", append(warnings, fmt.Sprintf("upstream-vhost %q is not a valid hostname and is ignored", vhost))
+	}
+	return hostname, warnings
+}
+
+// addRequestHeaderFilters sets the request headers and Host header on the rules of an
+// HTTPRoute that route to backends. The hostname is added to the rule's URLRewrite
+// filter if it already has one, as a rule can only have one.
+func addRequestHeaderFilters(httpRoute *gatewayv1.HTTPRoute, headers requestHeaders) {
+	for i := range httpRoute.Spec.Rules {
+		rule := &httpRoute.Spec.Rules[i]
+		if len(rule.BackendRefs) == 0 {
+			continue
+		}
+
+		if len(headers.set) > 0 {
+			rule.Filters = append(rule.Filters, gatewayv1.HTTPRouteFilter{
+				Type: gatewayv1.HTTPRouteFilterRequestHeaderModifier,
+				RequestHeaderModifier: &gatewayv1.HTTPHeaderFilter{
+					Set: slices.Clone(headers.set),
+				},
+			})
+		}
+
+		if headers.hostname == "" {
+			continue
+		}
+		hostname := gatewayv1.PreciseHostname(headers.hostname)
+		index := slices.IndexFunc(rule.Filters, func(f gatewayv1.HTTPRouteFilter) bool {
+			return f.Type == gatewayv1.HTTPRouteFilterURLRewrite && f.URLRewrite != nil
+		})
+		if index >= 0 {
+			rule.Filters[index].URLRewrite.Hostname = &hostname
+			continue
+		}
+		rule.Filters = append(rule.Filters, gatewayv1.HTTPRouteFilter{
+			Type:       gatewayv1.HTTPRouteFilterURLRewrite,
+			URLRewrite: &gatewayv1.HTTPURLRewriteFilter{Hostname: &hostname},
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package converter
+
+import (
+	"context"
+	"testing"
+
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/werdnum/ingress-gateway-api/internal/annotations"
+	"github.com/werdnum/ingress-gateway-api/internal/config"
+)
+
+// staticConfigMapResolver returns fixed ConfigMaps by namespace/name.
+type staticConfigMapResolver struct {
+	configMaps map[string]map[string]string
+}
+
+func (r *staticConfigMapResolver) ResolveConfigMap(ctx context.Context, namespace, name string) (map[string]string, bool, error) {
+	data, ok := r.configMaps[namespace+"/"+name]
+	return data, ok, nil
+}
+
+func TestConvertIngressFull_RequestHeaders(t *testing.T) {
+	configMaps := &staticConfigMapResolver{configMaps: map[string]map[string]string{
+		"default/custom-headers": {
+			"X-Team":     "payments",
+			"X-Real-Env": "$host",
+			"Host":       "configmap.internal",
+			"bad header": "value",
+		},
+		"other/custom-headers": {"X-Team": "other"},
+	}}
+
+	tests := []struct {
+		name         string
+		annots       map[string]string
+		rewrite      bool
+		wantSet      map[string]string
+		wantHostname string
+		wantWarnings int
+	}{
+		{
+			name:    "x-forwarded-prefix",
+			annots:  map[string]string{annotations.XForwardedPrefix: "/app"},
+			wantSet: map[string]string{"X-Forwarded-Prefix": "/app"},
+		},
+		{
+			name:         "upstream-vhost",
+			annots:       map[string]string{annotations.UpstreamVhost: "Internal.Example.com"},
+			wantHostname: "internal.example.com",
+		},
+		{
+			name:         "upstream-vhost drops the port",
+			annots:       map[string]string{annotations.UpstreamVhost: "internal.example.com:8080"},
+			wantHostname: "internal.example.com",
+			wantWarnings: 1,
+		},
+		{
+			name:         "upstream-vhost with nginx variables",
+			annots:       map[string]string{annotations.UpstreamVhost: "$host"},
+			wantWarnings: 1,
+		},
+		{
+			name:         "upstream-vhost joins the rewrite-target URLRewrite",
+			annots:       map[string]string{annotations.UpstreamVhost: "internal.example.com", annotations.RewriteTarget: "/"},
+			rewrite:      true,
+			wantHostname: "internal.example.com",
+		},
+		{
+			name:         "proxy-set-headers ConfigMap",
+			annots:       map[string]string{annotations.ProxySetHeaders: "custom-headers"},
+			wantSet:      map[string]string{"X-Team": "payments"},
+			wantHostname: "configmap.internal",
+			wantWarnings: 2,
+		},
+		{
+			name: "annotations override the ConfigMap",
+			annots: map[string]string{
+				annotations.ProxySetHeaders:  "default/custom-headers",
+				annotations.UpstreamVhost:    "vhost.internal",
+				annotations.XForwardedPrefix: "/app",
+			},
+			wantSet:      map[string]string{"X-Team": "payments", "X-Forwarded-Prefix": "/app"},
+			wantHostname: "vhost.internal",
+			wantWarnings: 2,
+		},
+		{
+			name:         "ConfigMap in another namespace",
+			annots:       map[string]string{annotations.ProxySetHeaders: "other/custom-headers"},
+			wantWarnings: 1,
+		},
+		{
+			name:         "missing ConfigMap",
+			annots:       map[string]string{annotations.ProxySetHeaders: "missing"},
+			wantWarnings: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewWithResolvers(&config.Config{
+				GatewayName:      "eg-gateway",
+				GatewayNamespace: "envoy-gateway",
+			}, Resolvers{ConfigMaps: configMaps})
+			ingress := tlsTestIngress([]string{"example.com"}, nil)
+			ingress.Annotations = tt.annots
+
+			result := c.ConvertIngressFull(context.Background(), ingress)
+
+			if len(result.Warnings) != tt.wantWarnings {
+				t.Errorf("expected %d warnings, got %v", tt.wantWarnings, result.Warnings)
+			}
+
+			var set []gatewayv1.HTTPHeader
+			var rewrite *gatewayv1.HTTPURLRewriteFilter
+			for _, filter := range result.HTTPRoutes[0].Spec.Rules[0].Filters {
+				switch filter.Type {
+				case gatewayv1.HTTPRouteFilterRequestHeaderModifier:
+					set = append(set, filter.RequestHeaderModifier.Set...)
+				case gatewayv1.HTTPRouteFilterURLRewrite:
+					if rewrite != nil {
+						t.Error("expected a single URLRewrite filter")
+					}
+					rewrite = filter.URLRewrite
+				}
+			}
+
+			if len(set) != len(tt.wantSet) {
+				t.Errorf("expected headers %v, got %v", tt.wantSet, set)
+			}
+			for _, header := range set {
+				if tt.wantSet[string(header.Name)] != header.Value {
+					t.Errorf("unexpected header %s: %s", header.Name, header.Value)
+				}
+			}
+
+			var hostname string
+			if rewrite != nil && rewrite.Hostname != nil {
+				hostname = string(*rewrite.Hostname)
+			}
+			if hostname != tt.wantHostname {
+				t.Errorf("expected hostname %q, got %q", tt.wantHostname, hostname)
+			}
+			if tt.rewrite && (rewrite == nil || rewrite.Path == nil) {
+				t.Errorf("expected the rewrite-target path rewrite to be kept, got %+v", rewrite)
+			}
+		})
+	}
+}
@@ -0,0 +1,320 @@
+package converter
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	egv1alpha1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayxv1alpha1 "sigs.k8s.io/gateway-api/apisx/v1alpha1"
+
+	"github.com/werdnum/ingress-gateway-api/internal/annotations"
+)
+
+// GenerateListenerPolicies returns the ClientTrafficPolicies the Ingress needs on the
+// Gateway listeners generated for it. Conversion warnings are discarded.
+func (c *Converter) GenerateListenerPolicies(ingress *networkingv1.Ingress, listeners []gatewayv1.Listener) []*egv1alpha1.ClientTrafficPolicy {
+	policies, _ := c.generateListenerPolicies(ingress, listeners, annotations.NewAnnotationSet(ingress.Annotations))
+	return policies
+}
+
+// generateListenerPolicies creates one ClientTrafficPolicy per generated listener for the
+// settings ingress-nginx applies per server rather than per location, such as client
+// certificate authentication and TLS protocols and ciphers. Policies live in the Gateway
+// namespace, or in ListenerSet mode in the namespace of the XListenerSet, and target the
+// listener by sectionName, so they only affect the hosts that listener serves.
+func (c *Converter) generateListenerPolicies(
+	ingress *networkingv1.Ingress,
+	listeners []gatewayv1.Listener,
+	annots annotations.AnnotationSet,
+) ([]*egv1alpha1.ClientTrafficPolicy, []string) {
+	if annots.HasSSLPassthrough() {
+		var warnings []string
+		if annots.HasAuthTLS() {
+			warnings = append(warnings, "auth-tls annotations are ignored with ssl-passthrough, the backend terminates TLS")
+		}
+		if annots.HasListenerTLSAnnotations() {
+			warnings = append(warnings, "ssl-protocols and ssl-ciphers are ignored with ssl-passthrough, the backend terminates TLS")
+		}
+		return nil, warnings
+	}
+
+	tlsSettings, warnings := c.buildListenerTLSSettings(annots)
+
+	var validation *egv1alpha1.ClientValidationContext
+	var headers *egv1alpha1.HeaderSettings
+	if annots.HasAuthTLS() {
+		var authWarnings []string
+		validation, headers, authWarnings = c.buildClientCertificateAuth(ingress, annots)
+		warnings = append(warnings, authWarnings...)
+	}
+	if validation == nil && tlsSettings == nil {
+		return nil, warnings
+	}
+
+	// Client certificates and TLS settings only apply to hosts with TLS
+	for _, host := range ruleHosts(ingress) {
+		if host == "" || hostHasTLS(ingress, host) {
+			continue
+		}
+		if validation != nil {
+			warnings = append(warnings, fmt.Sprintf(
+				"auth-tls-secret has no effect on host %q because it has no TLS configuration", host))
+		}
+		if annots.HasListenerTLSAnnotations() {
+			warnings = append(warnings, fmt.Sprintf(
+				"ssl-protocols and ssl-ciphers have no effect on host %q because it has no TLS configuration", host))
+		}
+	}
+
+	clientTLS := &egv1alpha1.ClientTLSSettings{ClientValidation: validation}
+	if tlsSettings != nil {
+		clientTLS.TLSSettings = *tlsSettings
+	}
+
+	// Policy target references are local, so in ListenerSet mode the policies live in the
+	// namespace of the XListenerSet holding the listeners
+	namespace := c.cfg.GatewayNamespace
+	target := gatewayv1.LocalPolicyTargetReference{
+		Group: gatewayv1.Group("gateway.networking.k8s.io"),
+		Kind:  gatewayv1.Kind("Gateway"),
+		Name:  gatewayv1.ObjectName(c.cfg.GatewayName),
+	}
+	if c.usesListenerSets() {
+		namespace = ingress.Namespace
+		target = gatewayv1.LocalPolicyTargetReference{
+			Group: gatewayv1.Group(gatewayxv1alpha1.GroupName),
+			Kind:  gatewayv1.Kind("XListenerSet"),
+			Name:  gatewayv1.ObjectName(ListenerSetName(c.cfg.GatewayName)),
+		}
+	}
+
+	policies := make([]*egv1alpha1.ClientTrafficPolicy, 0, len(listeners))
+	for _, listener := range listeners {
+		name := ListenerPolicyName(c.cfg.GatewayName, listener.Name)
+		if c.usesListenerSets() {
+			name = ListenerSetPolicyName(c.cfg.GatewayName, namespace, listener.Name)
+		}
+		policies = append(policies, &egv1alpha1.ClientTrafficPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: egv1alpha1.ClientTrafficPolicySpec{
+				PolicyTargetReferences: egv1alpha1.PolicyTargetReferences{
+					TargetRefs: []gatewayv1.LocalPolicyTargetReferenceWithSectionName{
+						{
+							LocalPolicyTargetReference: target,
+							SectionName:                ptr(listener.Name),
+						},
+					},
+				},
+				TLS:     clientTLS.DeepCopy(),
+				Headers: headers.DeepCopy(),
+			},
+		})
+	}
+
+	return policies, warnings
+}
+
+// ListenerPolicyName returns the name of the ClientTrafficPolicy for a generated listener.
+func ListenerPolicyName(gatewayName string, listener gatewayv1.SectionName) string {
+	return fmt.Sprintf("%s-%s", gatewayName, listener)
+}
+
+// ListenerSetPolicyName returns the name of the ClientTrafficPolicy for a listener
+// generated in the XListenerSet of a namespace.
+func ListenerSetPolicyName(gatewayName, namespace string, listener gatewayv1.SectionName) string {
+	return fmt.Sprintf("%s-%s-%s", gatewayName, namespace, listener)
+}
+
+// buildClientCertificateAuth converts the auth-tls-* annotations into client certificate
+// validation and, when the certificate is passed upstream, XFCC header settings.
+// Like ingress-nginx, the CA Secret may be in another namespace; the controller grants
+// the listener policy access to it.
+func (c *Converter) buildClientCertificateAuth(
+	ingress *networkingv1.Ingress,
+	annots annotations.AnnotationSet,
+) (*egv1alpha1.ClientValidationContext, *egv1alpha1.HeaderSettings, []string) {
+	var warnings []string
+
+	secret, _ := annots.GetString(annotations.AuthTLSSecret)
+	namespace, name := splitNamespacedName(secret, ingress.Namespace)
+	if namespace == "" || name == "" {
+		return nil, nil, []string{fmt.Sprintf(
+			"auth-tls-secret %q is not a valid Secret name; client certificates are not verified", secret)}
+	}
+
+	validation := &egv1alpha1.ClientValidationContext{
+		CACertificateRefs: []gatewayv1.SecretObjectReference{
+			{
+				Group:     ptr(gatewayv1.Group("")),
+				Kind:      ptr(gatewayv1.Kind("Secret")),
+				Name:      gatewayv1.ObjectName(name),
+				Namespace: ptr(gatewayv1.Namespace(namespace)),
+			},
+		},
+	}
+
+	verifyClient, _ := annots.GetString(annotations.AuthTLSVerifyClient)
+	switch verifyClient {
+	case "", "on":
+	case "optional":
+		validation.Optional = true
+	case "optional_no_ca":
+		validation.Optional = true
+		warnings = append(warnings,
+			"auth-tls-verify-client optional_no_ca is not supported, client certificates are verified against the CA when presented")
+	default:
+		warnings = append(warnings, fmt.Sprintf(
+			"unknown auth-tls-verify-client value %q, requiring client certificates", verifyClient))
+	}
+
+	if depth, ok := annots.GetString(annotations.AuthTLSVerifyDepth); ok {
+		if n, err := strconv.Atoi(depth); err != nil || n != 1 {
+			warnings = append(warnings, fmt.Sprintf(
+				"auth-tls-verify-depth %q is not supported, the full certificate chain is verified", depth))
+		}
+	}
+
+	// ingress-nginx passes the certificate in the ssl-client-cert header; Envoy passes
+	// it in the Cert field of the x-forwarded-client-cert header instead.
+	var headers *egv1alpha1.HeaderSettings
+	if pass, ok := annots.GetBool(annotations.AuthTLSPassCertificateToUpstream); ok && pass {
+		headers = &egv1alpha1.HeaderSettings{
+			XForwardedClientCert: &egv1alpha1.XForwardedClientCert{
+				Mode:             ptr(egv1alpha1.XFCCForwardModeSanitizeSet),
+				CertDetailsToAdd: []egv1alpha1.XFCCCertData{egv1alpha1.XFCCCertDataCert},
+			},
+		}
+	}
+
+	return validation, headers, warnings
+}
+
+// tlsProtocol is an ssl-protocols name and its TLS version.
+type tlsProtocol struct {
+	name    string
+	version egv1alpha1.TLSVersion
+}
+
+// tlsVersions lists the supported ssl-protocols names in ascending order.
+var tlsVersions = []tlsProtocol{
+	{"TLSv1", egv1alpha1.TLSv10},
+	{"TLSv1.1", egv1alpha1.TLSv11},
+	{"TLSv1.2", egv1alpha1.TLSv12},
+	{"TLSv1.3", egv1alpha1.TLSv13},
+}
+
+// ecdhCurves maps OpenSSL curve names to the names Envoy uses.
+var ecdhCurves = map[string]string{
+	"X25519":     "X25519",
+	"prime256v1": "P-256",
+	"secp256r1":  "P-256",
+	"P-256":      "P-256",
+	"secp384r1":  "P-384",
+	"P-384":      "P-384",
+	"secp521r1":  "P-521",
+	"P-521":      "P-521",
+}
+
+// buildListenerTLSSettings converts ssl-protocols, ssl-ciphers and ssl-ecdh-curve into
+// listener TLS settings. Annotations override the controller-wide defaults, like the
+// ingress-nginx ConfigMap keys of the same names. Returns nil when nothing is set.
+func (c *Converter) buildListenerTLSSettings(annots annotations.AnnotationSet) (*egv1alpha1.TLSSettings, []string) {
+	var warnings []string
+	settings := &egv1alpha1.TLSSettings{}
+	configured := false
+
+	if protocols := c.listenerTLSValue(annots, annotations.SSLProtocols, c.cfg.SSLProtocols); protocols != "" {
+		minVersion, maxVersion, protocolWarnings := parseSSLProtocols(protocols)
+		warnings = append(warnings, protocolWarnings...)
+		if minVersion != nil {
+			settings.MinVersion = minVersion
+			settings.MaxVersion = maxVersion
+			configured = true
+		}
+	}
+
+	if ciphers := c.listenerTLSValue(annots, annotations.SSLCiphers, c.cfg.SSLCiphers); ciphers != "" {
+		for _, cipher := range strings.Split(ciphers, ":") {
+			cipher = strings.TrimSpace(cipher)
+			if cipher == "" {
+				continue
+			}
+			if !isCipherSuiteName(cipher) {
+				warnings = append(warnings, fmt.Sprintf(
+					"ssl-ciphers entry %q is not a TLS 1.2 cipher suite name and is ignored", cipher))
+				continue
+			}
+			settings.Ciphers = append(settings.Ciphers, cipher)
+			configured = true
+		}
+	}
+
+	if curves := c.listenerTLSValue(annots, annotations.SSLECDHCurve, c.cfg.SSLECDHCurve); curves != "" && curves != "auto" {
+		for _, curve := range strings.Split(curves, ":") {
+			curve = strings.TrimSpace(curve)
+			name, ok := ecdhCurves[curve]
+			if !ok {
+				warnings = append(warnings, fmt.Sprintf("ssl-ecdh-curve %q is not supported and is ignored", curve))
+				continue
+			}
+			settings.ECDHCurves = append(settings.ECDHCurves, name)
+			configured = true
+		}
+	}
+
+	if !configured {
+		return nil, warnings
+	}
+	return settings, warnings
+}
+
+// listenerTLSValue returns the annotation value, or the controller-wide default.
+func (c *Converter) listenerTLSValue(annots annotations.AnnotationSet, key, defaultValue string) string {
+	if value, ok := annots.GetString(key); ok {
+		return strings.TrimSpace(value)
+	}
+	return strings.TrimSpace(defaultValue)
+}
+
+// parseSSLProtocols converts a space-separated ssl-protocols list into the range of TLS
+// versions it covers. Envoy has no way to disable a version inside that range.
+func parseSSLProtocols(protocols string) (*egv1alpha1.TLSVersion, *egv1alpha1.TLSVersion, []string) {
+	var warnings []string
+	minIndex, maxIndex := len(tlsVersions), -1
+	for _, protocol := range strings.Fields(protocols) {
+		index := slices.IndexFunc(tlsVersions, func(v tlsProtocol) bool { return v.name == protocol })
+		if index < 0 {
+			warnings = append(warnings, fmt.Sprintf("ssl-protocols %q is not supported and is ignored", protocol))
+			continue
+		}
+		minIndex = min(minIndex, index)
+		maxIndex = max(maxIndex, index)
+	}
+	if maxIndex < 0 {
+		return nil, nil, warnings
+	}
+	return ptr(tlsVersions[minIndex].version), ptr(tlsVersions[maxIndex].version), warnings
+}
+
+// isCipherSuiteName reports whether an OpenSSL cipher list entry names cipher suites,
+// e.g. ECDHE-RSA-AES128-GCM-SHA256 or an [A|B] group, rather than a keyword like HIGH
+// or an exclusion like !aNULL. Envoy only accepts suite names.
+func isCipherSuiteName(cipher string) bool {
+	if !strings.Contains(cipher, "-") {
+		return false
+	}
+	for _, r := range cipher {
+		if !(r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-|[]", r)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,299 @@
+package converter
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	egv1alpha1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	networkingv1 "k8s.io/api/networking/v1"
+
+	"github.com/werdnum/ingress-gateway-api/internal/annotations"
+	"github.com/werdnum/ingress-gateway-api/internal/config"
+)
+
+func TestGenerateListenerPolicies_ClientCertificateAuth(t *testing.T) {
+	exampleTLS := []networkingv1.IngressTLS{{Hosts: []string{"example.com"}, SecretName: "example-tls"}}
+
+	tests := []struct {
+		name         string
+		annotations  map[string]string
+		hosts        []string
+		tls          []networkingv1.IngressTLS
+		wantPolicies int
+		wantCA       string
+		wantOptional bool
+		wantXFCC     bool
+		wantWarnings int
+	}{
+		{
+			name:         "no annotations",
+			hosts:        []string{"example.com"},
+			tls:          exampleTLS,
+			wantPolicies: 0,
+		},
+		{
+			name:         "required client certificate",
+			annotations:  map[string]string{annotations.AuthTLSSecret: "default/client-ca"},
+			hosts:        []string{"example.com"},
+			tls:          exampleTLS,
+			wantPolicies: 1,
+		},
+		{
+			name: "optional client certificate passed upstream",
+			annotations: map[string]string{
+				annotations.AuthTLSSecret:                    "client-ca",
+				annotations.AuthTLSVerifyClient:              "optional",
+				annotations.AuthTLSPassCertificateToUpstream: "true",
+			},
+			hosts:        []string{"example.com"},
+			tls:          exampleTLS,
+			wantPolicies: 1,
+			wantOptional: true,
+			wantXFCC:     true,
+		},
+		{
+			name: "verification off",
+			annotations: map[string]string{
+				annotations.AuthTLSSecret:       "default/client-ca",
+				annotations.AuthTLSVerifyClient: "off",
+			},
+			hosts:        []string{"example.com"},
+			tls:          exampleTLS,
+			wantPolicies: 0,
+		},
+		{
+			name: "unsupported verify depth",
+			annotations: map[string]string{
+				annotations.AuthTLSSecret:      "default/client-ca",
+				annotations.AuthTLSVerifyDepth: "3",
+			},
+			hosts:        []string{"example.com"},
+			tls:          exampleTLS,
+			wantPolicies: 1,
+			wantWarnings: 1,
+		},
+		{
+			name:         "CA secret in another namespace",
+			annotations:  map[string]string{annotations.AuthTLSSecret: "other/client-ca"},
+			hosts:        []string{"example.com"},
+			tls:          exampleTLS,
+			wantPolicies: 1,
+			wantCA:       "other/client-ca",
+		},
+		{
+			name:         "invalid CA secret",
+			annotations:  map[string]string{annotations.AuthTLSSecret: "other/"},
+			hosts:        []string{"example.com"},
+			tls:          exampleTLS,
+			wantPolicies: 0,
+			wantWarnings: 1,
+		},
+		{
+			name:         "host without tls",
+			annotations:  map[string]string{annotations.AuthTLSSecret: "default/client-ca"},
+			hosts:        []string{"example.com", "plain.example.org"},
+			tls:          exampleTLS,
+			wantPolicies: 1,
+			wantWarnings: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(&config.Config{
+				GatewayName:      "eg-gateway",
+				GatewayNamespace: "envoy-gateway",
+			})
+
+			ingress := tlsTestIngress(tt.hosts, tt.tls)
+			ingress.Annotations = tt.annotations
+			listeners, _ := c.generateListeners(context.Background(), ingress, nil)
+			policies, warnings := c.generateListenerPolicies(ingress, listeners, annotations.NewAnnotationSet(tt.annotations))
+
+			if len(warnings) != tt.wantWarnings {
+				t.Errorf("expected %d warnings, got %d: %v", tt.wantWarnings, len(warnings), warnings)
+			}
+			if len(policies) != tt.wantPolicies {
+				t.Fatalf("expected %d policies, got %d", tt.wantPolicies, len(policies))
+			}
+			if tt.wantPolicies == 0 {
+				return
+			}
+
+			policy := policies[0]
+			if policy.Name != "eg-gateway-https-example-com" || policy.Namespace != "envoy-gateway" {
+				t.Errorf("unexpected policy %s/%s", policy.Namespace, policy.Name)
+			}
+			targetRef := policy.Spec.TargetRefs[0]
+			if targetRef.Name != "eg-gateway" || targetRef.SectionName == nil || *targetRef.SectionName != "https-example-com" {
+				t.Errorf("expected policy to target eg-gateway/https-example-com, got %+v", targetRef)
+			}
+
+			validation := policy.Spec.TLS.ClientValidation
+			if len(validation.CACertificateRefs) != 1 {
+				t.Fatalf("expected 1 CA certificate ref, got %d", len(validation.CACertificateRefs))
+			}
+			wantCA := tt.wantCA
+			if wantCA == "" {
+				wantCA = "default/client-ca"
+			}
+			caRef := validation.CACertificateRefs[0]
+			if caRef.Namespace == nil || string(*caRef.Namespace)+"/"+string(caRef.Name) != wantCA {
+				t.Errorf("expected CA %s, got %+v", wantCA, caRef)
+			}
+			if validation.Optional != tt.wantOptional {
+				t.Errorf("expected optional %v, got %v", tt.wantOptional, validation.Optional)
+			}
+
+			hasXFCC := policy.Spec.Headers != nil && policy.Spec.Headers.XForwardedClientCert != nil
+			if hasXFCC != tt.wantXFCC {
+				t.Fatalf("expected XFCC %v, got %v", tt.wantXFCC, hasXFCC)
+			}
+			if hasXFCC && *policy.Spec.Headers.XForwardedClientCert.Mode != egv1alpha1.XFCCForwardModeSanitizeSet {
+				t.Errorf("expected SanitizeSet XFCC mode, got %s", *policy.Spec.Headers.XForwardedClientCert.Mode)
+			}
+		})
+	}
+}
+
+func TestGenerateListenerPolicies_TLSSettings(t *testing.T) {
+	exampleTLS := []networkingv1.IngressTLS{{Hosts: []string{"example.com"}, SecretName: "example-tls"}}
+
+	tests := []struct {
+		name         string
+		cfg          config.Config
+		annotations  map[string]string
+		hosts        []string
+		wantPolicies int
+		wantMin      egv1alpha1.TLSVersion
+		wantMax      egv1alpha1.TLSVersion
+		wantCiphers  []string
+		wantCurves   []string
+		wantWarnings int
+	}{
+		{
+			name:         "no settings",
+			hosts:        []string{"example.com"},
+			wantPolicies: 0,
+		},
+		{
+			name: "protocols and ciphers from annotations",
+			annotations: map[string]string{
+				annotations.SSLProtocols: "TLSv1.2 TLSv1.3",
+				annotations.SSLCiphers:   "ECDHE-ECDSA-AES128-GCM-SHA256:ECDHE-RSA-AES128-GCM-SHA256:!aNULL",
+			},
+			hosts:        []string{"example.com"},
+			wantPolicies: 1,
+			wantMin:      egv1alpha1.TLSv12,
+			wantMax:      egv1alpha1.TLSv13,
+			wantCiphers:  []string{"ECDHE-ECDSA-AES128-GCM-SHA256", "ECDHE-RSA-AES128-GCM-SHA256"},
+			wantWarnings: 1, // !aNULL
+		},
+		{
+			name: "controller defaults",
+			cfg: config.Config{
+				SSLProtocols: "TLSv1.3",
+				SSLECDHCurve: "X25519:prime256v1",
+			},
+			hosts:        []string{"example.com"},
+			wantPolicies: 1,
+			wantMin:      egv1alpha1.TLSv13,
+			wantMax:      egv1alpha1.TLSv13,
+			wantCurves:   []string{"X25519", "P-256"},
+		},
+		{
+			name:         "annotation overrides controller default",
+			cfg:          config.Config{SSLProtocols: "TLSv1.3"},
+			annotations:  map[string]string{annotations.SSLProtocols: "SSLv3 TLSv1.2"},
+			hosts:        []string{"example.com"},
+			wantPolicies: 1,
+			wantMin:      egv1alpha1.TLSv12,
+			wantMax:      egv1alpha1.TLSv12,
+			wantWarnings: 1, // SSLv3
+		},
+		{
+			name:         "host without tls",
+			annotations:  map[string]string{annotations.SSLProtocols: "TLSv1.2"},
+			hosts:        []string{"example.com", "plain.example.org"},
+			wantPolicies: 1,
+			wantMin:      egv1alpha1.TLSv12,
+			wantMax:      egv1alpha1.TLSv12,
+			wantWarnings: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			cfg.GatewayName = "eg-gateway"
+			cfg.GatewayNamespace = "envoy-gateway"
+			c := New(&cfg)
+
+			ingress := tlsTestIngress(tt.hosts, exampleTLS)
+			ingress.Annotations = tt.annotations
+			listeners, _ := c.generateListeners(context.Background(), ingress, nil)
+			policies, warnings := c.generateListenerPolicies(ingress, listeners, annotations.NewAnnotationSet(tt.annotations))
+
+			if len(warnings) != tt.wantWarnings {
+				t.Errorf("expected %d warnings, got %d: %v", tt.wantWarnings, len(warnings), warnings)
+			}
+			if len(policies) != tt.wantPolicies {
+				t.Fatalf("expected %d policies, got %d", tt.wantPolicies, len(policies))
+			}
+			if tt.wantPolicies == 0 {
+				return
+			}
+
+			policy := policies[0]
+			if targetRef := policy.Spec.TargetRefs[0]; targetRef.SectionName == nil || *targetRef.SectionName != "https-example-com" {
+				t.Errorf("expected policy to target the https-example-com listener, got %+v", targetRef)
+			}
+			tls := policy.Spec.TLS
+			if tls.ClientValidation != nil {
+				t.Errorf("expected no client validation, got %+v", tls.ClientValidation)
+			}
+			if tt.wantMin != "" && (tls.MinVersion == nil || *tls.MinVersion != tt.wantMin) {
+				t.Errorf("expected min version %s, got %v", tt.wantMin, tls.MinVersion)
+			}
+			if tt.wantMax != "" && (tls.MaxVersion == nil || *tls.MaxVersion != tt.wantMax) {
+				t.Errorf("expected max version %s, got %v", tt.wantMax, tls.MaxVersion)
+			}
+			if !slices.Equal(tls.Ciphers, tt.wantCiphers) {
+				t.Errorf("expected ciphers %v, got %v", tt.wantCiphers, tls.Ciphers)
+			}
+			if !slices.Equal(tls.ECDHCurves, tt.wantCurves) {
+				t.Errorf("expected curves %v, got %v", tt.wantCurves, tls.ECDHCurves)
+			}
+		})
+	}
+}
+
+func TestGenerateListenerPolicies_MergesClientAuthAndTLSSettings(t *testing.T) {
+	c := New(&config.Config{
+		GatewayName:      "eg-gateway",
+		GatewayNamespace: "envoy-gateway",
+	})
+
+	annots := map[string]string{
+		annotations.AuthTLSSecret: "client-ca",
+		annotations.SSLProtocols:  "TLSv1.3",
+	}
+	ingress := tlsTestIngress([]string{"example.com"}, []networkingv1.IngressTLS{
+		{Hosts: []string{"example.com"}, SecretName: "example-tls"},
+	})
+	ingress.Annotations = annots
+	listeners, _ := c.generateListeners(context.Background(), ingress, nil)
+	policies, warnings := c.generateListenerPolicies(ingress, listeners, annotations.NewAnnotationSet(annots))
+
+	if len(warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+	if len(policies) != 1 {
+		t.Fatalf("expected 1 policy, got %d", len(policies))
+	}
+	tls := policies[0].Spec.TLS
+	if tls.ClientValidation == nil || tls.MinVersion == nil || *tls.MinVersion != egv1alpha1.TLSv13 {
+		t.Errorf("expected client validation and TLS 1.3 in one policy, got %+v", tls)
+	}
+}
@@ -0,0 +1,69 @@
+package converter
+
+import (
+	"fmt"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayxv1alpha1 "sigs.k8s.io/gateway-api/apisx/v1alpha1"
+
+	"github.com/werdnum/ingress-gateway-api/internal/config"
+)
+
+// ListenerSetName returns the name of the XListenerSet generated in each namespace for
+// the shared Gateway.
+func ListenerSetName(gatewayName string) string {
+	return fmt.Sprintf("%s-ingress", gatewayName)
+}
+
+// usesListenerSets reports whether generated listeners are placed in per-namespace
+// XListenerSets instead of on the shared Gateway.
+func (c *Converter) usesListenerSets() bool {
+	return c.cfg.ListenerMode == config.ListenerModeListenerSet
+}
+
+// listenerSetParentRef creates a ParentReference to a listener of the XListenerSet in the
+// route's namespace.
+func (c *Converter) listenerSetParentRef(section gatewayv1.SectionName) gatewayv1.ParentReference {
+	return gatewayv1.ParentReference{
+		Group:       ptr(gatewayv1.Group(gatewayxv1alpha1.GroupName)),
+		Kind:        ptr(gatewayv1.Kind("XListenerSet")),
+		Name:        gatewayv1.ObjectName(ListenerSetName(c.cfg.GatewayName)),
+		SectionName: ptr(section),
+	}
+}
+
+// routeParentRefs chooses the parent references of a route for the host. In ListenerSet
+// mode, protocols served by a listener generated for the Ingress attach to the namespace's
+// XListenerSet, and the remaining protocols attach to the shared Gateway as usual.
+func (c *Converter) routeParentRefs(
+	ingress *networkingv1.Ingress,
+	host string,
+	gatewayListeners []gatewayv1.Listener,
+	generated []gatewayv1.Listener,
+	protocols []gatewayv1.ProtocolType,
+	fallback gatewayv1.ParentReference,
+) ([]gatewayv1.ParentReference, []string) {
+	if !c.usesListenerSets() || host == "" {
+		return c.parentRefsForHost(ingress, host, gatewayListeners, protocols, fallback)
+	}
+
+	var parentRefs []gatewayv1.ParentReference
+	var remaining []gatewayv1.ProtocolType
+	for _, protocol := range protocols {
+		sections := c.selectListeners(generated, host, ingress.Namespace, protocol)
+		if len(sections) == 0 {
+			remaining = append(remaining, protocol)
+			continue
+		}
+		for _, section := range sections {
+			parentRefs = append(parentRefs, c.listenerSetParentRef(section))
+		}
+	}
+	if len(remaining) == 0 {
+		return parentRefs, nil
+	}
+
+	gatewayRefs, warnings := c.parentRefsForHost(ingress, host, gatewayListeners, remaining, fallback)
+	return append(parentRefs, gatewayRefs...), warnings
+}
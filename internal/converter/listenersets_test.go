@@ -0,0 +1,98 @@
+package converter
+
+import (
+	"context"
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/werdnum/ingress-gateway-api/internal/annotations"
+	"github.com/werdnum/ingress-gateway-api/internal/config"
+)
+
+func TestConvertIngressFull_ListenerSetParentRefs(t *testing.T) {
+	gatewayListeners := []gatewayv1.Listener{
+		{
+			Name:          "http",
+			Port:          80,
+			Protocol:      gatewayv1.HTTPProtocolType,
+			AllowedRoutes: allNamespaces(),
+		},
+	}
+	c := NewWithResolvers(&config.Config{
+		GatewayName:      "eg-gateway",
+		GatewayNamespace: "envoy-gateway",
+		ListenerMode:     config.ListenerModeListenerSet,
+	}, Resolvers{Listeners: &staticListenerResolver{listeners: gatewayListeners}})
+
+	ingress := tlsTestIngress([]string{"example.com", "plain.example.org"}, []networkingv1.IngressTLS{
+		{Hosts: []string{"example.com"}, SecretName: "example-tls"},
+	})
+	ingress.Annotations = map[string]string{annotations.SSLRedirect: "false"}
+
+	result := c.ConvertIngressFull(context.Background(), ingress)
+
+	if len(result.Warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", result.Warnings)
+	}
+	if len(result.Listeners) != 1 || result.Listeners[0].Name != "https-example-com" {
+		t.Fatalf("expected the https-example-com listener, got %+v", result.Listeners)
+	}
+
+	for _, route := range result.HTTPRoutes {
+		host := string(route.Spec.Hostnames[0])
+		switch host {
+		case "example.com":
+			if len(route.Spec.ParentRefs) != 2 {
+				t.Fatalf("expected 2 parent refs for %s, got %+v", host, route.Spec.ParentRefs)
+			}
+			listenerSet := route.Spec.ParentRefs[0]
+			if listenerSet.Kind == nil || *listenerSet.Kind != "XListenerSet" ||
+				listenerSet.Group == nil || *listenerSet.Group != "gateway.networking.x-k8s.io" ||
+				listenerSet.Name != "eg-gateway-ingress" || listenerSet.Namespace != nil ||
+				listenerSet.SectionName == nil || *listenerSet.SectionName != "https-example-com" {
+				t.Errorf("expected the XListenerSet https-example-com section, got %+v", listenerSet)
+			}
+			gateway := route.Spec.ParentRefs[1]
+			if gateway.Kind == nil || *gateway.Kind != "Gateway" || gateway.SectionName == nil || *gateway.SectionName != "http" {
+				t.Errorf("expected the Gateway http section, got %+v", gateway)
+			}
+		case "plain.example.org":
+			if len(route.Spec.ParentRefs) != 1 || *route.Spec.ParentRefs[0].Kind != "Gateway" {
+				t.Errorf("expected plain host to attach to the Gateway, got %+v", route.Spec.ParentRefs)
+			}
+		default:
+			t.Errorf("unexpected route for host %s", host)
+		}
+	}
+}
+
+func TestGenerateListenerPolicies_ListenerSetMode(t *testing.T) {
+	c := New(&config.Config{
+		GatewayName:      "eg-gateway",
+		GatewayNamespace: "envoy-gateway",
+		ListenerMode:     config.ListenerModeListenerSet,
+	})
+
+	ingress := tlsTestIngress([]string{"example.com"}, []networkingv1.IngressTLS{
+		{Hosts: []string{"example.com"}, SecretName: "example-tls"},
+	})
+	ingress.Annotations = map[string]string{annotations.AuthTLSSecret: "client-ca"}
+
+	policies := c.GenerateListenerPolicies(ingress, c.GenerateListeners(context.Background(), ingress, nil))
+
+	if len(policies) != 1 {
+		t.Fatalf("expected 1 policy, got %d", len(policies))
+	}
+	policy := policies[0]
+	if policy.Namespace != "default" || policy.Name != "eg-gateway-default-https-example-com" {
+		t.Errorf("unexpected policy %s/%s", policy.Namespace, policy.Name)
+	}
+	targetRef := policy.Spec.TargetRefs[0]
+	if targetRef.Group != "gateway.networking.x-k8s.io" || targetRef.Kind != "XListenerSet" ||
+		targetRef.Name != "eg-gateway-ingress" ||
+		targetRef.SectionName == nil || *targetRef.SectionName != "https-example-com" {
+		t.Errorf("expected policy to target the XListenerSet https-example-com section, got %+v", targetRef)
+	}
+}
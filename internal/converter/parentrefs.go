@@ -0,0 +1,199 @@
+package converter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// gatewayListeners returns the listeners known for the shared Gateway: those already on
+// the Gateway plus the ones this Ingress is about to add. It returns nil when the Gateway's
+// listeners are unknown, in which case routes attach to the whole Gateway.
+func (c *Converter) gatewayListeners(ctx context.Context, generated []gatewayv1.Listener) ([]gatewayv1.Listener, []string) {
+	existing, err := c.listeners.ResolveListeners(ctx, c.cfg.GatewayNamespace, c.cfg.GatewayName)
+	if err != nil {
+		return nil, []string{fmt.Sprintf("failed to look up Gateway listeners, attaching to the whole Gateway: %v", err)}
+	}
+	if len(existing) == 0 {
+		return nil, nil
+	}
+
+	listeners := append([]gatewayv1.Listener{}, existing...)
+	for _, listener := range generated {
+		if findListener(listeners, listener.Name) == nil {
+			listeners = append(listeners, listener)
+		}
+	}
+	return listeners, nil
+}
+
+// parentRefsForHost chooses the listeners an HTTPRoute for the host should attach to:
+// on each port, the most specific listener of one of the given protocols that accepts
+// the host. Without known listeners, or when no listener accepts the host, the route
+// uses the fallback parent reference.
+func (c *Converter) parentRefsForHost(
+	ingress *networkingv1.Ingress,
+	host string,
+	listeners []gatewayv1.Listener,
+	protocols []gatewayv1.ProtocolType,
+	fallback gatewayv1.ParentReference,
+) ([]gatewayv1.ParentReference, []string) {
+	if host == "" || len(listeners) == 0 {
+		return []gatewayv1.ParentReference{fallback}, nil
+	}
+
+	var sections []gatewayv1.SectionName
+	for _, protocol := range protocols {
+		sections = append(sections, c.selectListeners(listeners, host, ingress.Namespace, protocol)...)
+	}
+	if len(sections) == 0 {
+		names := make([]string, 0, len(protocols))
+		for _, protocol := range protocols {
+			names = append(names, string(protocol))
+		}
+		return []gatewayv1.ParentReference{fallback}, []string{fmt.Sprintf(
+			"no %s listener on Gateway %s/%s accepts host %q from namespace %s; the HTTPRoute will not be accepted until one is added",
+			strings.Join(names, " or "), c.cfg.GatewayNamespace, c.cfg.GatewayName, host, ingress.Namespace)}
+	}
+
+	parentRefs := make([]gatewayv1.ParentReference, 0, len(sections))
+	for _, section := range sections {
+		parentRef := c.createParentRef()
+		parentRef.SectionName = ptr(section)
+		parentRefs = append(parentRefs, parentRef)
+	}
+	return parentRefs, nil
+}
+
+// selectListeners returns the most specific listener on each port that accepts routes
+// for the host from the namespace over the given protocol, in listener order.
+func (c *Converter) selectListeners(listeners []gatewayv1.Listener, host, namespace string, protocol gatewayv1.ProtocolType) []gatewayv1.SectionName {
+	best := make(map[gatewayv1.PortNumber]gatewayv1.Listener)
+	var ports []gatewayv1.PortNumber
+	for _, listener := range listeners {
+		if listener.Protocol != protocol {
+			continue
+		}
+		listenerHost := ""
+		if listener.Hostname != nil {
+			listenerHost = string(*listener.Hostname)
+		}
+		if !HostnameMatches(listenerHost, host) {
+			continue
+		}
+		if !c.listenerAllowsRoutes(listener, routeKind(protocol), namespace) {
+			continue
+		}
+
+		current, ok := best[listener.Port]
+		if !ok {
+			ports = append(ports, listener.Port)
+			best[listener.Port] = listener
+			continue
+		}
+		if hostnameSpecificity(listener.Hostname) > hostnameSpecificity(current.Hostname) {
+			best[listener.Port] = listener
+		}
+	}
+
+	sections := make([]gatewayv1.SectionName, 0, len(ports))
+	for _, port := range ports {
+		sections = append(sections, best[port].Name)
+	}
+	return sections
+}
+
+// hostnameSpecificity ranks listener hostnames: exact hostnames beat wildcards, longer
+// wildcards beat shorter ones, and any hostname beats none.
+func hostnameSpecificity(hostname *gatewayv1.Hostname) int {
+	if hostname == nil || *hostname == "" {
+		return 0
+	}
+	if strings.HasPrefix(string(*hostname), "*.") {
+		return len(*hostname)
+	}
+	return 1 << 16
+}
+
+// routeKind returns the kind of route generated for listeners of the protocol.
+func routeKind(protocol gatewayv1.ProtocolType) gatewayv1.Kind {
+	if protocol == gatewayv1.TLSProtocolType {
+		return "TLSRoute"
+	}
+	return "HTTPRoute"
+}
+
+// listenerAllowsRoutes reports whether the listener accepts routes of the kind from the namespace.
+// Namespace selectors can only be evaluated against the namespace name label; selectors on
+// other labels are assumed to match.
+func (c *Converter) listenerAllowsRoutes(listener gatewayv1.Listener, kind gatewayv1.Kind, namespace string) bool {
+	allowed := listener.AllowedRoutes
+	if allowed != nil && len(allowed.Kinds) > 0 {
+		ok := false
+		for _, allowedKind := range allowed.Kinds {
+			group := gatewayv1.GroupName
+			if allowedKind.Group != nil {
+				group = string(*allowedKind.Group)
+			}
+			if allowedKind.Kind == kind && group == gatewayv1.GroupName {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+
+	from := gatewayv1.NamespacesFromSame
+	if allowed != nil && allowed.Namespaces != nil && allowed.Namespaces.From != nil {
+		from = *allowed.Namespaces.From
+	}
+
+	switch from {
+	case gatewayv1.NamespacesFromAll:
+		return true
+	case gatewayv1.NamespacesFromSelector:
+		return selectorMatchesNamespace(allowed.Namespaces.Selector, namespace)
+	default:
+		return namespace == c.cfg.GatewayNamespace
+	}
+}
+
+// selectorMatchesNamespace evaluates a namespace selector using only the namespace name label.
+func selectorMatchesNamespace(selector *metav1.LabelSelector, namespace string) bool {
+	if selector == nil {
+		return false
+	}
+	for key := range selector.MatchLabels {
+		if key != namespaceNameLabel {
+			return true
+		}
+	}
+	for _, expr := range selector.MatchExpressions {
+		if expr.Key != namespaceNameLabel {
+			return true
+		}
+	}
+
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return false
+	}
+	return s.Matches(labels.Set{namespaceNameLabel: namespace})
+}
+
+// findListener returns the listener with the given name, or nil.
+func findListener(listeners []gatewayv1.Listener, name gatewayv1.SectionName) *gatewayv1.Listener {
+	for i := range listeners {
+		if listeners[i].Name == name {
+			return &listeners[i]
+		}
+	}
+	return nil
+}
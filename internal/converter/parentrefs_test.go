@@ -0,0 +1,176 @@
+package converter
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/werdnum/ingress-gateway-api/internal/config"
+)
+
+// staticListenerResolver returns a fixed set of listeners.
+type staticListenerResolver struct {
+	listeners []gatewayv1.Listener
+}
+
+func (r *staticListenerResolver) ResolveListeners(ctx context.Context, namespace, name string) ([]gatewayv1.Listener, error) {
+	return r.listeners, nil
+}
+
+func allNamespaces() *gatewayv1.AllowedRoutes {
+	return &gatewayv1.AllowedRoutes{
+		Namespaces: &gatewayv1.RouteNamespaces{From: ptr(gatewayv1.NamespacesFromAll)},
+	}
+}
+
+func TestConvertIngressFull_ParentRefSectionNames(t *testing.T) {
+	gatewayListeners := []gatewayv1.Listener{
+		{
+			Name:          "http",
+			Port:          80,
+			Protocol:      gatewayv1.HTTPProtocolType,
+			AllowedRoutes: allNamespaces(),
+		},
+		{
+			Name:          "https-wildcard",
+			Hostname:      ptr(gatewayv1.Hostname("*.example.com")),
+			Port:          443,
+			Protocol:      gatewayv1.HTTPSProtocolType,
+			AllowedRoutes: allNamespaces(),
+		},
+		{
+			Name:     "internal",
+			Hostname: ptr(gatewayv1.Hostname("internal.example.org")),
+			Port:     8080,
+			Protocol: gatewayv1.HTTPProtocolType,
+		},
+	}
+
+	tests := []struct {
+		name         string
+		hosts        []string
+		tls          []networkingv1.IngressTLS
+		wantSections map[string][]gatewayv1.SectionName // host -> sections, nil means no section
+		wantWarnings int
+	}{
+		{
+			name:  "plain host attaches to the HTTP listener",
+			hosts: []string{"plain.example.org"},
+			wantSections: map[string][]gatewayv1.SectionName{
+				"plain.example.org": {"http"},
+			},
+		},
+		{
+			name:  "plain host also attaches to an HTTPS listener accepting it",
+			hosts: []string{"web.example.com"},
+			wantSections: map[string][]gatewayv1.SectionName{
+				"web.example.com": {"https-wildcard", "http"},
+			},
+		},
+		{
+			name:  "TLS host attaches to its generated HTTPS listener",
+			hosts: []string{"example.com"},
+			tls: []networkingv1.IngressTLS{
+				{Hosts: []string{"example.com"}, SecretName: "example-tls"},
+			},
+			wantSections: map[string][]gatewayv1.SectionName{
+				"example.com": {"https-example-com"},
+			},
+		},
+		{
+			name:  "most specific HTTPS listener wins",
+			hosts: []string{"api.example.com"},
+			tls: []networkingv1.IngressTLS{
+				{Hosts: []string{"api.example.com"}, SecretName: "api-tls"},
+			},
+			wantSections: map[string][]gatewayv1.SectionName{
+				"api.example.com": {"https-api-example-com"},
+			},
+		},
+		{
+			name:  "TLS host served by an existing wildcard listener",
+			hosts: []string{"web.example.com"},
+			tls: []networkingv1.IngressTLS{
+				{Hosts: []string{"web.example.com"}},
+			},
+			wantSections: map[string][]gatewayv1.SectionName{
+				"web.example.com": {"https-wildcard"},
+			},
+			wantWarnings: 1, // no secretName and no default certificate
+		},
+		{
+			name:  "no HTTPS listener accepts the host",
+			hosts: []string{"shop.example.org"},
+			tls: []networkingv1.IngressTLS{
+				{Hosts: []string{"shop.example.org"}},
+			},
+			wantSections: map[string][]gatewayv1.SectionName{
+				"shop.example.org": nil,
+			},
+			wantWarnings: 2,
+		},
+		{
+			name:  "listener restricted to its own namespace is skipped",
+			hosts: []string{"internal.example.org"},
+			wantSections: map[string][]gatewayv1.SectionName{
+				"internal.example.org": {"http"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewWithResolvers(&config.Config{
+				GatewayName:      "eg-gateway",
+				GatewayNamespace: "envoy-gateway",
+			}, Resolvers{Listeners: &staticListenerResolver{listeners: gatewayListeners}})
+
+			result := c.ConvertIngressFull(context.Background(), tlsTestIngress(tt.hosts, tt.tls))
+
+			if len(result.Warnings) != tt.wantWarnings {
+				t.Errorf("expected %d warnings, got %d: %v", tt.wantWarnings, len(result.Warnings), result.Warnings)
+			}
+			for _, route := range result.HTTPRoutes {
+				if strings.HasSuffix(route.Name, "-ssl-redirect") {
+					continue
+				}
+				host := string(route.Spec.Hostnames[0])
+				want := tt.wantSections[host]
+				if want == nil {
+					if len(route.Spec.ParentRefs) != 1 || route.Spec.ParentRefs[0].SectionName != nil {
+						t.Errorf("expected a parent ref without sectionName for %s, got %+v", host, route.Spec.ParentRefs)
+					}
+					continue
+				}
+				if len(route.Spec.ParentRefs) != len(want) {
+					t.Fatalf("expected %d parent refs for %s, got %d", len(want), host, len(route.Spec.ParentRefs))
+				}
+				for i, parentRef := range route.Spec.ParentRefs {
+					if parentRef.SectionName == nil || *parentRef.SectionName != want[i] {
+						t.Errorf("expected section %s for %s, got %v", want[i], host, parentRef.SectionName)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestConvertIngressFull_NoListenersOmitsSectionName(t *testing.T) {
+	c := New(&config.Config{
+		GatewayName:      "eg-gateway",
+		GatewayNamespace: "envoy-gateway",
+	})
+
+	result := c.ConvertIngressFull(context.Background(), tlsTestIngress([]string{"example.com"}, []networkingv1.IngressTLS{
+		{Hosts: []string{"example.com"}, SecretName: "example-tls"},
+	}))
+
+	for _, route := range result.HTTPRoutes {
+		if len(route.Spec.ParentRefs) != 1 || route.Spec.ParentRefs[0].SectionName != nil {
+			t.Errorf("expected a single parent ref without sectionName for %s, got %+v", route.Name, route.Spec.ParentRefs)
+		}
+	}
+}
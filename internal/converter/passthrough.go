@@ -0,0 +1,100 @@
+package converter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+// generatePassthroughListeners creates one TLS Passthrough listener per rule host of an
+// ssl-passthrough Ingress. Passthrough is routed by SNI, so rules without a host are skipped.
+func (c *Converter) generatePassthroughListeners(ingress *networkingv1.Ingress) ([]gatewayv1.Listener, []string) {
+	var warnings []string
+	listenersByHost := make(map[string]gatewayv1.Listener)
+
+	for _, host := range ruleHosts(ingress) {
+		if host == "" {
+			warnings = append(warnings, "ssl-passthrough requires a host, rules without a host are ignored")
+			continue
+		}
+		if _, exists := listenersByHost[host]; exists {
+			continue
+		}
+
+		listenersByHost[host] = gatewayv1.Listener{
+			Name:     gatewayv1.SectionName(ListenerName(gatewayv1.TLSProtocolType, host)),
+			Hostname: ptr(gatewayv1.Hostname(host)),
+			Port:     HTTPSListenerPort,
+			Protocol: gatewayv1.TLSProtocolType,
+			TLS: &gatewayv1.ListenerTLSConfig{
+				Mode: ptr(gatewayv1.TLSModePassthrough),
+			},
+			AllowedRoutes: allowedRoutesFromNamespaces(ingress.Namespace),
+		}
+	}
+
+	if len(ingress.Spec.TLS) > 0 {
+		warnings = append(warnings, "spec.tls is ignored with ssl-passthrough, the backend terminates TLS")
+	}
+
+	listeners := make([]gatewayv1.Listener, 0, len(listenersByHost))
+	for _, listener := range listenersByHost {
+		listeners = append(listeners, listener)
+	}
+	sort.Slice(listeners, func(i, j int) bool {
+		return listeners[i].Name < listeners[j].Name
+	})
+
+	return listeners, warnings
+}
+
+// createTLSRoute creates a TLSRoute that passes TLS connections for a host through to
+// the backend of its "/" path. Like ingress-nginx, other paths are ignored because the
+// proxy cannot see the request path of an encrypted connection.
+func (c *Converter) createTLSRoute(ctx context.Context, ingress *networkingv1.Ingress, host string, paths []networkingv1.HTTPIngressPath) (*gatewayv1alpha2.TLSRoute, []string) {
+	var warnings []string
+	var backend *networkingv1.IngressBackend
+	for i := range paths {
+		if paths[i].Path != "/" && paths[i].Path != "" {
+			warnings = append(warnings, fmt.Sprintf(
+				"ssl-passthrough ignores path %q for host %q, only \"/\" is passed through", paths[i].Path, host))
+			continue
+		}
+		if backend == nil {
+			backend = &paths[i].Backend
+		}
+	}
+	if backend == nil {
+		warnings = append(warnings, fmt.Sprintf(
+			"ssl-passthrough host %q has no \"/\" path, no TLSRoute created", host))
+		return nil, warnings
+	}
+
+	tlsRoute := &gatewayv1alpha2.TLSRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      c.generateRouteName(ingress, host),
+			Namespace: ingress.Namespace,
+			Labels:    copyLabels(ingress.Labels),
+			Annotations: map[string]string{
+				"ingress-gateway-api.io/source": fmt.Sprintf("%s/%s", ingress.Namespace, ingress.Name),
+			},
+		},
+		Spec: gatewayv1alpha2.TLSRouteSpec{
+			Hostnames: []gatewayv1alpha2.Hostname{gatewayv1alpha2.Hostname(host)},
+			Rules: []gatewayv1alpha2.TLSRouteRule{
+				{
+					BackendRefs: []gatewayv1alpha2.BackendRef{
+						c.convertIngressBackend(ctx, ingress.Namespace, *backend).BackendRef,
+					},
+				},
+			},
+		},
+	}
+
+	return tlsRoute, warnings
+}
@@ -0,0 +1,130 @@
+package converter
+
+import (
+	"context"
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/werdnum/ingress-gateway-api/internal/annotations"
+	"github.com/werdnum/ingress-gateway-api/internal/config"
+)
+
+func passthroughTestIngress(paths ...string) *networkingv1.Ingress {
+	ingress := tlsTestIngress([]string{"secure.example.com"}, nil)
+	ingress.Annotations = map[string]string{annotations.SSLPassthrough: "true"}
+	ingress.Spec.Rules[0].HTTP.Paths = nil
+	for _, path := range paths {
+		ingress.Spec.Rules[0].HTTP.Paths = append(ingress.Spec.Rules[0].HTTP.Paths, networkingv1.HTTPIngressPath{
+			Path:     path,
+			PathType: ptr(networkingv1.PathTypePrefix),
+			Backend: networkingv1.IngressBackend{
+				Service: &networkingv1.IngressServiceBackend{
+					Name: "secure-backend",
+					Port: networkingv1.ServiceBackendPort{Number: 8443},
+				},
+			},
+		})
+	}
+	return ingress
+}
+
+func TestConvertIngressFull_SSLPassthrough(t *testing.T) {
+	tests := []struct {
+		name          string
+		paths         []string
+		wantTLSRoutes int
+		wantWarnings  int
+	}{
+		{
+			name:          "root path is passed through",
+			paths:         []string{"/"},
+			wantTLSRoutes: 1,
+		},
+		{
+			name:          "other paths are ignored",
+			paths:         []string{"/", "/api"},
+			wantTLSRoutes: 1,
+			wantWarnings:  1,
+		},
+		{
+			name:          "no root path",
+			paths:         []string{"/api"},
+			wantTLSRoutes: 0,
+			wantWarnings:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(&config.Config{
+				GatewayName:      "eg-gateway",
+				GatewayNamespace: "envoy-gateway",
+			})
+
+			result := c.ConvertIngressFull(context.Background(), passthroughTestIngress(tt.paths...))
+
+			if len(result.Warnings) != tt.wantWarnings {
+				t.Errorf("expected %d warnings, got %d: %v", tt.wantWarnings, len(result.Warnings), result.Warnings)
+			}
+			if len(result.HTTPRoutes) != 0 {
+				t.Errorf("expected no HTTPRoutes, got %d", len(result.HTTPRoutes))
+			}
+			if len(result.Listeners) != 1 {
+				t.Fatalf("expected 1 listener, got %d", len(result.Listeners))
+			}
+			listener := result.Listeners[0]
+			if listener.Name != "tls-secure-example-com" || listener.Protocol != gatewayv1.TLSProtocolType ||
+				listener.TLS == nil || *listener.TLS.Mode != gatewayv1.TLSModePassthrough {
+				t.Errorf("expected TLS Passthrough listener tls-secure-example-com, got %+v", listener)
+			}
+
+			if len(result.TLSRoutes) != tt.wantTLSRoutes {
+				t.Fatalf("expected %d TLSRoutes, got %d", tt.wantTLSRoutes, len(result.TLSRoutes))
+			}
+			if tt.wantTLSRoutes == 0 {
+				return
+			}
+
+			route := result.TLSRoutes[0]
+			if route.Name != "test-ingress-secure-example-com" {
+				t.Errorf("expected TLSRoute test-ingress-secure-example-com, got %s", route.Name)
+			}
+			if len(route.Spec.Hostnames) != 1 || route.Spec.Hostnames[0] != "secure.example.com" {
+				t.Errorf("expected hostname secure.example.com, got %v", route.Spec.Hostnames)
+			}
+			if len(route.Spec.Rules) != 1 || len(route.Spec.Rules[0].BackendRefs) != 1 {
+				t.Fatalf("expected 1 rule with 1 backend, got %+v", route.Spec.Rules)
+			}
+			backendRef := route.Spec.Rules[0].BackendRefs[0]
+			if backendRef.Name != "secure-backend" || backendRef.Port == nil || *backendRef.Port != 8443 {
+				t.Errorf("expected backend secure-backend:8443, got %+v", backendRef)
+			}
+		})
+	}
+}
+
+func TestConvertIngressFull_SSLPassthroughSectionName(t *testing.T) {
+	c := NewWithResolvers(&config.Config{
+		GatewayName:      "eg-gateway",
+		GatewayNamespace: "envoy-gateway",
+	}, Resolvers{Listeners: &staticListenerResolver{listeners: []gatewayv1.Listener{
+		{
+			Name:          "http",
+			Port:          80,
+			Protocol:      gatewayv1.HTTPProtocolType,
+			AllowedRoutes: allNamespaces(),
+		},
+	}}})
+
+	result := c.ConvertIngressFull(context.Background(), passthroughTestIngress("/"))
+
+	if len(result.TLSRoutes) != 1 {
+		t.Fatalf("expected 1 TLSRoute, got %d", len(result.TLSRoutes))
+	}
+	parentRefs := result.TLSRoutes[0].Spec.ParentRefs
+	if len(parentRefs) != 1 || parentRefs[0].SectionName == nil || *parentRefs[0].SectionName != "tls-secure-example-com" {
+		t.Errorf("expected TLSRoute to attach to tls-secure-example-com, got %+v", parentRefs)
+	}
+}
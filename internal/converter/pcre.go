@@ -0,0 +1,236 @@
+package converter
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"slices"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+// translatePCRE rewrites an nginx (PCRE) regular expression into the RE2 syntax used
+// by Envoy. Possessive quantifiers and atomic groups become their backtracking forms,
+// which match the same paths except in contrived cases, and named groups and \Z use
+// the RE2 spellings. Lookarounds, backreferences, recursion and conditionals have no
+// RE2 equivalent and return an error explaining which construct was found.
+func translatePCRE(pattern string) (string, []string, error) {
+	var b strings.Builder
+	var notes []string
+	quantified := false // the previous token was a quantifier
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		rest := pattern[i:]
+
+		switch {
+		case c == '\\' && i+1 < len(pattern):
+			next := pattern[i+1]
+			switch {
+			case next >= '1' && next <= '9', next == 'k', next == 'g':
+				return "", nil, fmt.Errorf("backreference %s is not supported by RE2", backslashToken(rest))
+			case next == 'G':
+				return "", nil, fmt.Errorf(`\G is not supported by RE2`)
+			case next == 'Z':
+				// Paths cannot end with a newline, so \Z is the end of the text
+				b.WriteString(`\z`)
+			case next == 'Q':
+				end := strings.Index(pattern[i+2:], `\E`)
+				if end < 0 {
+					b.WriteString(pattern[i:])
+					i = len(pattern)
+					continue
+				}
+				b.WriteString(pattern[i : i+2+end+2])
+				i += 2 + end + 1
+				quantified = false
+				continue
+			default:
+				b.WriteString(pattern[i : i+2])
+			}
+			i++
+			quantified = false
+
+		case c == '[':
+			end := classEnd(pattern, i)
+			b.WriteString(pattern[i:end])
+			i = end - 1
+			quantified = false
+
+		case c == '(':
+			replacement, skip, note, err := translateGroup(rest)
+			if err != nil {
+				return "", nil, err
+			}
+			if note != "" {
+				notes = append(notes, note)
+			}
+			b.WriteString(replacement)
+			i += skip - 1
+			quantified = false
+
+		case c == '*' || c == '+' || c == '?':
+			switch {
+			case quantified && c == '+':
+				notes = append(notes, "possessive quantifiers are matched as greedy quantifiers")
+				quantified = false
+			case quantified && c == '?':
+				b.WriteByte(c)
+				quantified = false
+			default:
+				b.WriteByte(c)
+				quantified = true
+			}
+
+		case c == '{' && isRepetition(rest):
+			end := strings.IndexByte(rest, '}')
+			b.WriteString(rest[:end+1])
+			i += end
+			quantified = true
+
+		default:
+			b.WriteByte(c)
+			quantified = false
+		}
+	}
+
+	translated := b.String()
+	if _, err := syntax.Parse(translated, syntax.Perl); err != nil {
+		return "", nil, fmt.Errorf("not a valid RE2 regular expression: %w", err)
+	}
+	slices.Sort(notes)
+	return translated, slices.Compact(notes), nil
+}
+
+// translateGroup translates the group opening at the start of s. It returns the RE2
+// replacement, the number of bytes of s it replaces and a note on approximations.
+func translateGroup(s string) (string, int, string, error) {
+	switch {
+	case strings.HasPrefix(s, "(?="), strings.HasPrefix(s, "(?!"):
+		return "", 0, "", fmt.Errorf("lookahead %s is not supported by RE2", s[:3])
+	case strings.HasPrefix(s, "(?<="), strings.HasPrefix(s, "(?<!"):
+		return "", 0, "", fmt.Errorf("lookbehind %s is not supported by RE2", s[:4])
+	case strings.HasPrefix(s, "(?>"):
+		return "(?:", 3, "atomic groups are matched as non-capturing groups", nil
+	case strings.HasPrefix(s, "(?P="), strings.HasPrefix(s, "(?P>"), strings.HasPrefix(s, "(?&"),
+		strings.HasPrefix(s, "(?R)"), strings.HasPrefix(s, "(?+"), strings.HasPrefix(s, "(?-") && len(s) > 3 && isDigit(s[3]),
+		len(s) > 2 && strings.HasPrefix(s, "(?") && isDigit(s[2]):
+		return "", 0, "", fmt.Errorf("recursion or backreference %s is not supported by RE2", groupToken(s))
+	case strings.HasPrefix(s, "(?("):
+		return "", 0, "", fmt.Errorf("conditional group is not supported by RE2")
+	case strings.HasPrefix(s, "(?|"):
+		return "", 0, "", fmt.Errorf("branch reset group (?| is not supported by RE2")
+	case strings.HasPrefix(s, "(*"):
+		return "", 0, "", fmt.Errorf("PCRE verb %s is not supported by RE2", groupToken(s))
+	case strings.HasPrefix(s, "(?#"):
+		end := strings.IndexByte(s, ')')
+		if end < 0 {
+			return "", 0, "", fmt.Errorf("unterminated comment group")
+		}
+		return "", end + 1, "", nil
+	case strings.HasPrefix(s, "(?<"), strings.HasPrefix(s, "(?'"):
+		closer := byte('>')
+		if s[2] == '\'' {
+			closer = '\''
+		}
+		end := strings.IndexByte(s[3:], closer)
+		if end < 0 {
+			return "", 0, "", fmt.Errorf("unterminated group name in %s", groupToken(s))
+		}
+		return "(?P<" + s[3:3+end] + ">", 3 + end + 1, "", nil
+	}
+	return "(", 1, "", nil
+}
+
+// classEnd returns the index just past the character class opening at start. A ]
+// right after [ or [^ is a literal, as are POSIX classes such as [:alpha:].
+func classEnd(pattern string, start int) int {
+	i := start + 1
+	if i < len(pattern) && pattern[i] == '^' {
+		i++
+	}
+	if i < len(pattern) && pattern[i] == ']' {
+		i++
+	}
+	for i < len(pattern) {
+		switch {
+		case pattern[i] == '\\':
+			i += 2
+			continue
+		case pattern[i] == '[' && i+1 < len(pattern) && pattern[i+1] == ':':
+			if end := strings.Index(pattern[i:], ":]"); end >= 0 {
+				i += end + 2
+				continue
+			}
+		case pattern[i] == ']':
+			return i + 1
+		}
+		i++
+	}
+	return len(pattern)
+}
+
+// isRepetition reports whether s starts with a {n}, {n,} or {n,m} quantifier.
+func isRepetition(s string) bool {
+	end := strings.IndexByte(s, '}')
+	if end < 2 {
+		return false
+	}
+	low, high, comma := strings.Cut(s[1:end], ",")
+	if low == "" || strings.Trim(low, "0123456789") != "" {
+		return false
+	}
+	return !comma || strings.Trim(high, "0123456789") == ""
+}
+
+// backslashToken returns the escape sequence at the start of s for error messages.
+func backslashToken(s string) string {
+	if len(s) > 2 && (s[1] == 'k' || s[1] == 'g') {
+		if end := strings.IndexAny(s[2:], ">}'"); end >= 0 {
+			return s[:2+end+1]
+		}
+	}
+	return s[:2]
+}
+
+// groupToken returns the group opening at the start of s for error messages.
+func groupToken(s string) string {
+	if end := strings.IndexByte(s, ')'); end >= 0 {
+		return s[:end+1]
+	}
+	return s
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// translateRegexPaths rewrites the paths of a regex host into RE2. Paths that cannot be
+// translated are dropped, so that Envoy Gateway does not reject the whole HTTPRoute,
+// and a warning explains why. Each path is lengthened by its regexPrecedencePadding.
+func translateRegexPaths(
+	host string,
+	paths []networkingv1.HTTPIngressPath,
+	padding map[string]int,
+) ([]networkingv1.HTTPIngressPath, []string) {
+	var warnings []string
+	translated := make([]networkingv1.HTTPIngressPath, 0, len(paths))
+	for _, path := range paths {
+		if path.Path == "" {
+			translated = append(translated, path)
+			continue
+		}
+		value, notes, err := translatePCRE(path.Path)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf(
+				"path %q for host %q is dropped: %v", path.Path, host, err))
+			continue
+		}
+		for _, note := range notes {
+			warnings = append(warnings, fmt.Sprintf("path %q for host %q: %s", path.Path, host, note))
+		}
+		path.Path = value + strings.Repeat(noopGroup, padding[path.Path])
+		translated = append(translated, path)
+	}
+	return translated, warnings
+}
@@ -0,0 +1,164 @@
+package converter
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+
+	"github.com/werdnum/ingress-gateway-api/internal/annotations"
+	"github.com/werdnum/ingress-gateway-api/internal/config"
+)
+
+func TestTranslatePCRE(t *testing.T) {
+	tests := []struct {
+		name      string
+		pattern   string
+		want      string
+		wantNotes int
+		wantError string
+	}{
+		{
+			name:    "RE2 compatible",
+			pattern: "/api/v[0-9]+/(users|groups)/.*",
+			want:    "/api/v[0-9]+/(users|groups)/.*",
+		},
+		{
+			name:      "possessive quantifiers",
+			pattern:   "/files/[a-z]++/\\d*+x{2,3}+",
+			want:      "/files/[a-z]+/\\d*x{2,3}",
+			wantNotes: 1,
+		},
+		{
+			name:    "lazy quantifier is kept",
+			pattern: "/a+?/b*?",
+			want:    "/a+?/b*?",
+		},
+		{
+			name:      "atomic group",
+			pattern:   "/(?>foo|foobar)/",
+			want:      "/(?:foo|foobar)/",
+			wantNotes: 1,
+		},
+		{
+			name:    "named groups",
+			pattern: "/(?<version>v[0-9]+)/(?'rest'.*)",
+			want:    "/(?P<version>v[0-9]+)/(?P<rest>.*)",
+		},
+		{
+			name:    "end of subject",
+			pattern: "/exact\\Z",
+			want:    "/exact\\z",
+		},
+		{
+			name:    "comment",
+			pattern: "/api(?# versioned API)/v1",
+			want:    "/api/v1",
+		},
+		{
+			name:      "quantifier characters in classes and quotes",
+			pattern:   "/[+*?]++/\\Q(?=)\\E",
+			want:      "/[+*?]+/\\Q(?=)\\E",
+			wantNotes: 1,
+		},
+		{
+			name:    "flags are kept",
+			pattern: "(?i)/api(?-i:/Case)",
+			want:    "(?i)/api(?-i:/Case)",
+		},
+		{
+			name:      "lookahead",
+			pattern:   "/api/(?!internal).*",
+			wantError: "lookahead (?! is not supported",
+		},
+		{
+			name:      "lookbehind",
+			pattern:   "/(?<=v)[0-9]+",
+			wantError: "lookbehind (?<= is not supported",
+		},
+		{
+			name:      "backreference",
+			pattern:   "/(a)/\\1",
+			wantError: "backreference \\1 is not supported",
+		},
+		{
+			name:      "named backreference",
+			pattern:   "/(?<x>a)/\\k<x>",
+			wantError: "backreference \\k<x> is not supported",
+		},
+		{
+			name:      "recursion",
+			pattern:   "/(a(?1)?b)",
+			wantError: "recursion or backreference (?1) is not supported",
+		},
+		{
+			name:      "invalid expression",
+			pattern:   "/api(",
+			wantError: "not a valid RE2 regular expression",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, notes, err := translatePCRE(tt.pattern)
+			if tt.wantError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("translatePCRE(%q) = %q, want %q", tt.pattern, got, tt.want)
+			}
+			if len(notes) != tt.wantNotes {
+				t.Errorf("expected %d notes, got %v", tt.wantNotes, notes)
+			}
+		})
+	}
+}
+
+func TestConvertIngressFull_DropsUntranslatableRegexPaths(t *testing.T) {
+	c := New(&config.Config{
+		GatewayName:      "eg-gateway",
+		GatewayNamespace: "envoy-gateway",
+	})
+	ingress := tlsTestIngress([]string{"example.com"}, nil)
+	ingress.Annotations = map[string]string{annotations.UseRegex: "true"}
+	backend := ingress.Spec.Rules[0].HTTP.Paths[0].Backend
+	ingress.Spec.Rules[0].HTTP.Paths = []networkingv1.HTTPIngressPath{
+		{Path: "/api/(?!internal).*", PathType: ptr(networkingv1.PathTypeImplementationSpecific), Backend: backend},
+		{Path: "/files/[a-z]++", PathType: ptr(networkingv1.PathTypeImplementationSpecific), Backend: backend},
+	}
+
+	result := c.ConvertIngressFull(context.Background(), ingress)
+
+	if len(result.HTTPRoutes) != 1 {
+		t.Fatalf("expected 1 HTTPRoute, got %d", len(result.HTTPRoutes))
+	}
+	rules := result.HTTPRoutes[0].Spec.Rules
+	if len(rules) != 1 {
+		t.Fatalf("expected only the translatable rule, got %d rules", len(rules))
+	}
+	if got := *rules[0].Matches[0].Path.Value; got != "(?i)/files/[a-z]+.*" {
+		t.Errorf("expected translated regex, got %s", got)
+	}
+
+	var dropped, approximated bool
+	for _, warning := range result.Warnings {
+		dropped = dropped || strings.Contains(warning, `path "/api/(?!internal).*" for host "example.com" is dropped: lookahead`)
+		approximated = approximated || strings.Contains(warning, "possessive quantifiers")
+	}
+	if !dropped || !approximated {
+		t.Errorf("expected warnings for the dropped and approximated paths, got %v", result.Warnings)
+	}
+
+	// A host without any usable path gets no HTTPRoute
+	ingress.Spec.Rules[0].HTTP.Paths = ingress.Spec.Rules[0].HTTP.Paths[:1]
+	if result := c.ConvertIngressFull(context.Background(), ingress); len(result.HTTPRoutes) != 0 {
+		t.Errorf("expected no HTTPRoute, got %d", len(result.HTTPRoutes))
+	}
+}
@@ -15,13 +15,13 @@ import (
 )
 
 // generateBackendTrafficPolicy creates a BackendTrafficPolicy for the given HTTPRoute
-// based on timeout, load balancer, and body size annotations.
+// based on timeout, load balancer, session affinity, body size, and rate limit annotations.
 func (c *Converter) generateBackendTrafficPolicy(
 	ingress *networkingv1.Ingress,
 	httpRoute *gatewayv1.HTTPRoute,
 	annots annotations.AnnotationSet,
 ) *egv1alpha1.BackendTrafficPolicy {
-	if !annots.HasBackendTrafficPolicyAnnotations() {
+	if !annots.HasBackendTrafficPolicyAnnotations() && !c.usesCookieHash(annots) {
 		return nil
 	}
 
@@ -53,7 +53,7 @@ func (c *Converter) generateBackendTrafficPolicy(
 	}
 
 	// Add load balancer configuration
-	if annots.HasLoadBalancer() {
+	if annots.HasLoadBalancer() || c.usesCookieHash(annots) {
 		policy.Spec.ClusterSettings.LoadBalancer = c.buildLoadBalancer(annots)
 	}
 
@@ -65,6 +65,16 @@ func (c *Converter) generateBackendTrafficPolicy(
 		policy.Spec.ClusterSettings.Connection.BufferLimit = bodySize
 	}
 
+	// Add rate limits from limit-rps, limit-rpm and global-rate-limit
+	if annots.HasRateLimit() || annots.HasGlobalRateLimit() {
+		policy.Spec.RateLimit, _ = c.buildRateLimit(annots)
+	}
+
+	// Add a circuit breaker from limit-connections
+	if annots.HasConnectionLimit() {
+		policy.Spec.ClusterSettings.CircuitBreaker, _ = buildConnectionLimit(annots)
+	}
+
 	return policy
 }
 
@@ -98,6 +108,14 @@ func (c *Converter) buildTimeout(annots annotations.AnnotationSet) *egv1alpha1.T
 
 // buildLoadBalancer creates a LoadBalancer configuration from annotations.
 func (c *Converter) buildLoadBalancer(annots annotations.AnnotationSet) *egv1alpha1.LoadBalancer {
+	// Cookie affinity takes precedence over upstream-hash-by, as in ingress-nginx
+	if annots.HasCookieAffinity() {
+		if c.usesSessionPersistence(annots) {
+			return nil
+		}
+		return buildCookieHash(annots)
+	}
+
 	hashBy, ok := annots.GetString(annotations.UpstreamHashBy)
 	if !ok {
 		return nil
@@ -388,15 +406,17 @@ func SetBackendTLSPolicyOwnerReference(policy *gatewayv1.BackendTLSPolicy, ingre
 
 // generateBackendTLSPolicies creates BackendTLSPolicy resources for backend services
 // when the backend-protocol: HTTPS annotation is present.
-// One BackendTLSPolicy is created per unique backend service.
+// One BackendTLSPolicy is created per unique backend service. Backends that skip
+// verification (proxy-ssl-verify: off) are handled by generateInsecureBackends instead.
 func (c *Converter) generateBackendTLSPolicies(
 	ingress *networkingv1.Ingress,
 	httpRoutes []*gatewayv1.HTTPRoute,
 	annots annotations.AnnotationSet,
 ) []*gatewayv1.BackendTLSPolicy {
-	if !annots.HasBackendTLSPolicy() {
+	if !annots.HasBackendTLSPolicy() || !annots.VerifiesUpstreamTLS() {
 		return nil
 	}
+	settings, _ := c.upstreamTLSSettings(ingress, annots)
 
 	// Collect unique backend services from all HTTPRoutes
 	type serviceKey struct {
@@ -426,34 +446,7 @@ func (c *Converter) generateBackendTLSPolicies(
 				}
 				seen[key] = true
 
-				// Create BackendTLSPolicy for this service
-				policy := &gatewayv1.BackendTLSPolicy{
-					ObjectMeta: metav1.ObjectMeta{
-						Name:      fmt.Sprintf("%s-tls", backendRef.Name),
-						Namespace: ns,
-						Labels:    copyLabels(ingress.Labels),
-						Annotations: map[string]string{
-							"ingress-gateway-api.io/source": fmt.Sprintf("%s/%s", ingress.Namespace, ingress.Name),
-						},
-					},
-					Spec: gatewayv1.BackendTLSPolicySpec{
-						TargetRefs: []gatewayv1.LocalPolicyTargetReferenceWithSectionName{
-							{
-								LocalPolicyTargetReference: gatewayv1.LocalPolicyTargetReference{
-									Group: gatewayv1.Group(""),
-									Kind:  gatewayv1.Kind("Service"),
-									Name:  backendRef.Name,
-								},
-							},
-						},
-						Validation: gatewayv1.BackendTLSPolicyValidation{
-							Hostname:                gatewayv1.PreciseHostname(backendRef.Name),
-							WellKnownCACertificates: ptr(gatewayv1.WellKnownCACertificatesSystem),
-						},
-					},
-				}
-
-				policies = append(policies, policy)
+				policies = append(policies, c.createBackendTLSPolicy(ingress, ns, string(backendRef.Name), settings))
 			}
 		}
 	}
@@ -463,34 +456,49 @@ func (c *Converter) generateBackendTLSPolicies(
 		svc := ingress.Spec.DefaultBackend.Service
 		key := serviceKey{namespace: ingress.Namespace, name: svc.Name}
 		if !seen[key] {
-			policy := &gatewayv1.BackendTLSPolicy{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      fmt.Sprintf("%s-tls", svc.Name),
-					Namespace: ingress.Namespace,
-					Labels:    copyLabels(ingress.Labels),
-					Annotations: map[string]string{
-						"ingress-gateway-api.io/source": fmt.Sprintf("%s/%s", ingress.Namespace, ingress.Name),
-					},
-				},
-				Spec: gatewayv1.BackendTLSPolicySpec{
-					TargetRefs: []gatewayv1.LocalPolicyTargetReferenceWithSectionName{
-						{
-							LocalPolicyTargetReference: gatewayv1.LocalPolicyTargetReference{
-								Group: gatewayv1.Group(""),
-								Kind:  gatewayv1.Kind("Service"),
-								Name:  gatewayv1.ObjectName(svc.Name),
-							},
-						},
-					},
-					Validation: gatewayv1.BackendTLSPolicyValidation{
-						Hostname:                gatewayv1.PreciseHostname(svc.Name),
-						WellKnownCACertificates: ptr(gatewayv1.WellKnownCACertificatesSystem),
-					},
-				},
-			}
-			policies = append(policies, policy)
+			policies = append(policies, c.createBackendTLSPolicy(ingress, ingress.Namespace, svc.Name, settings))
 		}
 	}
 
 	return policies
 }
+
+// createBackendTLSPolicy creates the BackendTLSPolicy for one backend service. Backends are
+// validated against the proxy-ssl CA bundles, or the system CAs when there are none.
+func (c *Converter) createBackendTLSPolicy(
+	ingress *networkingv1.Ingress,
+	namespace, service string,
+	settings upstreamTLS,
+) *gatewayv1.BackendTLSPolicy {
+	validation := gatewayv1.BackendTLSPolicyValidation{
+		Hostname: c.backendTLSHostname(settings, service, namespace),
+	}
+	if len(settings.caCertificateRefs) > 0 {
+		validation.CACertificateRefs = append([]gatewayv1.LocalObjectReference(nil), settings.caCertificateRefs...)
+	} else {
+		validation.WellKnownCACertificates = ptr(gatewayv1.WellKnownCACertificatesSystem)
+	}
+
+	return &gatewayv1.BackendTLSPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-tls", service),
+			Namespace: namespace,
+			Labels:    copyLabels(ingress.Labels),
+			Annotations: map[string]string{
+				"ingress-gateway-api.io/source": fmt.Sprintf("%s/%s", ingress.Namespace, ingress.Name),
+			},
+		},
+		Spec: gatewayv1.BackendTLSPolicySpec{
+			TargetRefs: []gatewayv1.LocalPolicyTargetReferenceWithSectionName{
+				{
+					LocalPolicyTargetReference: gatewayv1.LocalPolicyTargetReference{
+						Group: gatewayv1.Group(""),
+						Kind:  gatewayv1.Kind("Service"),
+						Name:  gatewayv1.ObjectName(service),
+					},
+				},
+			},
+			Validation: validation,
+		},
+	}
+}
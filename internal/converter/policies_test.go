@@ -26,6 +26,8 @@ func TestGenerateBackendTrafficPolicy(t *testing.T) {
 		wantTimeout   bool
 		wantLB        bool
 		wantBufferLim bool
+		wantRateLimit bool
+		wantBreaker   bool
 	}{
 		{
 			name:        "no annotations",
@@ -56,6 +58,16 @@ func TestGenerateBackendTrafficPolicy(t *testing.T) {
 			wantPolicy:    true,
 			wantBufferLim: true,
 		},
+		{
+			name: "rate limit annotations",
+			annotations: map[string]string{
+				"nginx.ingress.kubernetes.io/limit-rps":         "10",
+				"nginx.ingress.kubernetes.io/limit-connections": "5",
+			},
+			wantPolicy:    true,
+			wantRateLimit: true,
+			wantBreaker:   true,
+		},
 		{
 			name: "multiple annotations",
 			annotations: map[string]string{
@@ -110,6 +122,12 @@ func TestGenerateBackendTrafficPolicy(t *testing.T) {
 			if tt.wantBufferLim && policy.Spec.ClusterSettings.Connection == nil {
 				t.Error("expected connection config, got nil")
 			}
+			if tt.wantRateLimit && policy.Spec.RateLimit == nil {
+				t.Error("expected rate limit config, got nil")
+			}
+			if tt.wantBreaker && policy.Spec.ClusterSettings.CircuitBreaker == nil {
+				t.Error("expected circuit breaker config, got nil")
+			}
 		})
 	}
 }
@@ -0,0 +1,215 @@
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// noopGroup is an empty non-capturing group, used to lengthen a regex without changing
+// what it matches.
+const noopGroup = "(?:)"
+
+// hostPaths returns the paths of the rules for the host in the Ingresses.
+func hostPaths(ingresses []networkingv1.Ingress, host string) []networkingv1.HTTPIngressPath {
+	var paths []networkingv1.HTTPIngressPath
+	for _, ingress := range ingresses {
+		for _, rule := range ingress.Spec.Rules {
+			if rule.Host == host && rule.HTTP != nil {
+				paths = append(paths, rule.HTTP.Paths...)
+			}
+		}
+	}
+	return paths
+}
+
+// nginxLocationOrder compares two paths in the order ingress-nginx writes the locations
+// of a server, which nginx tries regexes in: longest first, then reverse lexicographic.
+func nginxLocationOrder(a, b string) int {
+	if len(a) != len(b) {
+		return len(b) - len(a)
+	}
+	return strings.Compare(b, a)
+}
+
+// regexPrecedencePadding computes how many noopGroups each path of a regex host needs.
+// nginx uses the first regex location that matches, in nginxLocationOrder, while Envoy
+// Gateway tries longer regexes first. Translation to RE2 changes lengths and equal
+// lengths have no defined order, so regexes that can match the same paths are padded
+// until Envoy Gateway tries them in the nginx order. The other Ingresses of the host
+// compute the same padding from the same paths. Paths are keyed by their Ingress value.
+func regexPrecedencePadding(paths, siblingPaths []networkingv1.HTTPIngressPath) map[string]int {
+	type regexLocation struct {
+		path   string
+		length int
+		prefix string
+	}
+
+	var locations []regexLocation
+	for _, path := range slices.Concat(paths, siblingPaths) {
+		if path.Path == "" || slices.ContainsFunc(locations, func(l regexLocation) bool { return l.path == path.Path }) {
+			continue
+		}
+		translated, _, err := translatePCRE(path.Path)
+		if err != nil {
+			continue
+		}
+		re, err := regexp.Compile(translated)
+		if err != nil {
+			continue
+		}
+		prefix, _ := re.LiteralPrefix()
+		locations = append(locations, regexLocation{
+			path:   path.Path,
+			length: len(translated),
+			prefix: strings.ToLower(prefix),
+		})
+	}
+	slices.SortFunc(locations, func(a, b regexLocation) int { return nginxLocationOrder(a.path, b.path) })
+
+	// Both regexes are anchored at the start, so they can only match the same path if
+	// one literal prefix starts with the other
+	overlaps := func(a, b regexLocation) bool {
+		return strings.HasPrefix(a.prefix, b.prefix) || strings.HasPrefix(b.prefix, a.prefix)
+	}
+
+	padding := make(map[string]int)
+	for i := len(locations) - 1; i >= 0; i-- {
+		required := locations[i].length
+		for _, later := range locations[i+1:] {
+			if later.length >= required && overlaps(locations[i], later) {
+				required = later.length + 1
+			}
+		}
+		if required > locations[i].length {
+			groups := (required - locations[i].length + len(noopGroup) - 1) / len(noopGroup)
+			padding[locations[i].path] = groups
+			locations[i].length += groups * len(noopGroup)
+		}
+	}
+	return padding
+}
+
+// fixPrefixPrecedence corrects the paths of a host without regexes where nginx and
+// Gateway API pick different paths. A prefix with a trailing slash, such as /foo/, also
+// matches /foo in Gateway API, and wins over the shorter prefixes nginx uses for /foo.
+// An Exact match for /foo is added to the path nginx uses, which wins in both models.
+// When that path belongs to another Ingress, its own conversion adds the match.
+// Requests nginx does not route to any path cannot be excluded and are reported.
+func fixPrefixPrecedence(
+	host string,
+	paths, siblingPaths []networkingv1.HTTPIngressPath,
+) ([]networkingv1.HTTPIngressPath, []string) {
+	var warnings []string
+	all := slices.Concat(paths, siblingPaths)
+	ownCount := len(paths)
+	handled := make(map[string]bool)
+
+	for i, path := range all {
+		if !isPrefixPath(path) || len(path.Path) < 2 || !strings.HasSuffix(path.Path, "/") {
+			continue
+		}
+		bare := strings.TrimSuffix(path.Path, "/")
+		if handled[bare] {
+			continue
+		}
+		handled[bare] = true
+
+		exact := slices.ContainsFunc(all, func(p networkingv1.HTTPIngressPath) bool {
+			return p.PathType != nil && *p.PathType == networkingv1.PathTypeExact && p.Path == bare
+		})
+		if exact {
+			continue
+		}
+
+		winner := -1
+		for j, candidate := range all {
+			if !isPrefixPath(candidate) || candidate.Path == path.Path || !nginxPrefixMatches(candidate, bare) {
+				continue
+			}
+			if winner < 0 || len(candidate.Path) > len(all[winner].Path) {
+				winner = j
+			}
+		}
+
+		switch {
+		case winner < 0 && i < ownCount:
+			warnings = append(warnings, fmt.Sprintf(
+				"requests for %s on host %s are routed to path %s; nginx does not route them to any path",
+				bare, displayHost(host), path.Path))
+		case winner >= 0 && winner < ownCount:
+			paths = append(paths, networkingv1.HTTPIngressPath{
+				Path:     bare,
+				PathType: ptr(networkingv1.PathTypeExact),
+				Backend:  all[winner].Backend,
+			})
+		}
+	}
+
+	return paths, warnings
+}
+
+// matchCharacterPrefixes matches the ImplementationSpecific paths of a host without
+// regexes with a regular expression, so that /api also matches /apix as in nginx. The
+// rules of the HTTPRoute are those of the paths, in order. Envoy Gateway tries regular
+// expressions before every PathPrefix match, so a path keeps its PathPrefix match when a
+// longer prefix path on the host starts with the same characters, and the requests
+// nginx would still route to it are reported.
+func matchCharacterPrefixes(httpRoute *gatewayv1.HTTPRoute, host string, paths, siblingPaths []networkingv1.HTTPIngressPath) []string {
+	var warnings []string
+	all := slices.Concat(paths, siblingPaths)
+	for i, path := range paths {
+		if !isCharacterPrefixPath(path) || i >= len(httpRoute.Spec.Rules) || len(httpRoute.Spec.Rules[i].Matches) == 0 {
+			continue
+		}
+		longer := slices.IndexFunc(all, func(p networkingv1.HTTPIngressPath) bool {
+			return isPrefixPath(p) && len(p.Path) > len(path.Path) && strings.HasPrefix(p.Path, path.Path)
+		})
+		if longer >= 0 {
+			warnings = append(warnings, fmt.Sprintf(
+				"requests on host %s starting with %s but not %s/ are not routed to path %s as in nginx, "+
+					"as the longer path %s starts with the same characters",
+				displayHost(host), path.Path, path.Path, path.Path, all[longer].Path))
+			continue
+		}
+		httpRoute.Spec.Rules[i].Matches[0].Path = &gatewayv1.HTTPPathMatch{
+			Type:  ptr(gatewayv1.PathMatchRegularExpression),
+			Value: ptr(characterPrefixRegex(path.Path)),
+		}
+	}
+	return warnings
+}
+
+// isCharacterPrefixPath reports whether nginx matches a path outside regex mode
+// differently from a PathPrefix: ImplementationSpecific paths are a prefix of any
+// characters rather than of whole segments, which only makes a difference without a
+// trailing slash.
+func isCharacterPrefixPath(path networkingv1.HTTPIngressPath) bool {
+	return path.PathType != nil && *path.PathType == networkingv1.PathTypeImplementationSpecific &&
+		path.Path != "" && !strings.HasSuffix(path.Path, "/")
+}
+
+// characterPrefixRegex returns the regular expression for the request paths that start
+// with a character prefix path.
+func characterPrefixRegex(path string) string {
+	return regexp.QuoteMeta(path) + ".*"
+}
+
+// isPrefixPath reports whether a path is an nginx prefix location outside regex mode.
+func isPrefixPath(path networkingv1.HTTPIngressPath) bool {
+	return path.Path != "" && (path.PathType == nil || *path.PathType != networkingv1.PathTypeExact)
+}
+
+// nginxPrefixMatches reports whether ingress-nginx routes a request path to a prefix
+// path. Prefix paths match whole segments, ImplementationSpecific paths any characters.
+func nginxPrefixMatches(path networkingv1.HTTPIngressPath, request string) bool {
+	if path.PathType != nil && *path.PathType == networkingv1.PathTypePrefix {
+		prefix := strings.TrimSuffix(path.Path, "/")
+		return request == prefix || strings.HasPrefix(request, prefix+"/")
+	}
+	return strings.HasPrefix(request, path.Path)
+}
@@ -0,0 +1,346 @@
+package converter
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/werdnum/ingress-gateway-api/internal/config"
+)
+
+func TestRegexPrecedencePadding(t *testing.T) {
+	tests := []struct {
+		name     string
+		paths    []string
+		siblings []string
+		requests []string
+		unpadded bool
+	}{
+		{
+			name:     "equal lengths are tried in reverse lexicographic order",
+			paths:    []string{"/api/.*", "/api/v1"},
+			requests: []string{"/api/v1", "/api/v2", "/API/V1/users"},
+		},
+		{
+			name:     "named groups grow during translation",
+			paths:    []string{"/users/(?<id>[0-9]+)", "/users/[0-9]+/settings"},
+			requests: []string{"/users/42", "/users/42/settings"},
+		},
+		{
+			name:     "padding follows paths of other Ingresses",
+			paths:    []string{"/shop/(?<item>.+)"},
+			siblings: []string{"/shop/cart/checkout", "/shop/cart/(?<step>.+)"},
+			requests: []string{"/shop/cart/checkout", "/shop/cart/pay", "/shop/hats"},
+		},
+		{
+			name:     "unrelated paths are not padded",
+			paths:    []string{"/a", "/b/c"},
+			requests: []string{"/a", "/b/c"},
+			unpadded: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths := regexTestPaths(tt.paths)
+			siblingPaths := regexTestPaths(tt.siblings)
+			padding := regexPrecedencePadding(paths, siblingPaths)
+			if tt.unpadded && len(padding) != 0 {
+				t.Errorf("expected no padding, got %v", padding)
+			}
+
+			// Every Ingress of the host pads with the same values
+			all := slices.Concat(tt.paths, tt.siblings)
+			var regexes []string
+			for _, path := range all {
+				translated, _, err := translatePCRE(path)
+				if err != nil {
+					t.Fatalf("translatePCRE(%q): %v", path, err)
+				}
+				regexes = append(regexes, regexPath(translated+strings.Repeat(noopGroup, padding[path])))
+			}
+
+			for _, request := range tt.requests {
+				want := nginxRegexWinner(t, all, request)
+				got := envoyRegexWinner(t, regexes, request)
+				if want != got {
+					t.Errorf("request %s: nginx uses %s, Envoy Gateway uses %s", request, all[want], all[got])
+				}
+			}
+		})
+	}
+}
+
+func regexTestPaths(values []string) []networkingv1.HTTPIngressPath {
+	var paths []networkingv1.HTTPIngressPath
+	for _, value := range values {
+		paths = append(paths, networkingv1.HTTPIngressPath{
+			Path:     value,
+			PathType: ptr(networkingv1.PathTypeImplementationSpecific),
+		})
+	}
+	return paths
+}
+
+// nginxRegexWinner returns the index of the first path nginx matches to the request.
+func nginxRegexWinner(t *testing.T, paths []string, request string) int {
+	t.Helper()
+	order := make([]int, len(paths))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int { return nginxLocationOrder(paths[a], paths[b]) })
+	for _, i := range order {
+		translated, _, _ := translatePCRE(paths[i])
+		if regexp.MustCompile("(?i)^" + translated).MatchString(request) {
+			return i
+		}
+	}
+	t.Fatalf("no path matches %s", request)
+	return -1
+}
+
+// envoyRegexWinner returns the index of the longest regex that matches the whole
+// request, failing on ties Envoy Gateway may break either way.
+func envoyRegexWinner(t *testing.T, regexes []string, request string) int {
+	t.Helper()
+	winner := -1
+	for i, regex := range regexes {
+		if !regexp.MustCompile("^(?:" + regex + ")$").MatchString(request) {
+			continue
+		}
+		switch {
+		case winner < 0 || len(regex) > len(regexes[winner]):
+			winner = i
+		case len(regex) == len(regexes[winner]):
+			t.Errorf("request %s: %s and %s have the same length", request, regex, regexes[winner])
+		}
+	}
+	if winner < 0 {
+		t.Fatalf("no regex matches %s", request)
+	}
+	return winner
+}
+
+func TestConvertIngressFull_PrefixPrecedence(t *testing.T) {
+	backend := func(name string) networkingv1.IngressBackend {
+		return networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+			Name: name,
+			Port: networkingv1.ServiceBackendPort{Number: 80},
+		}}
+	}
+	path := func(value string, pathType networkingv1.PathType, service string) networkingv1.HTTPIngressPath {
+		return networkingv1.HTTPIngressPath{Path: value, PathType: ptr(pathType), Backend: backend(service)}
+	}
+
+	tests := []struct {
+		name        string
+		paths       []networkingv1.HTTPIngressPath
+		siblings    []networkingv1.HTTPIngressPath
+		wantExact   string // the service of an added Exact /foo match, if any
+		wantWarning bool
+	}{
+		{
+			name: "shorter prefix gets an Exact match",
+			paths: []networkingv1.HTTPIngressPath{
+				path("/", networkingv1.PathTypePrefix, "web"),
+				path("/foo/", networkingv1.PathTypePrefix, "foo"),
+			},
+			wantExact: "web",
+		},
+		{
+			name: "ImplementationSpecific prefix without a segment boundary",
+			paths: []networkingv1.HTTPIngressPath{
+				path("/f", networkingv1.PathTypeImplementationSpecific, "f"),
+				path("/foo/", networkingv1.PathTypeImplementationSpecific, "foo"),
+			},
+			wantExact: "f",
+		},
+		{
+			name: "existing Exact match",
+			paths: []networkingv1.HTTPIngressPath{
+				path("/", networkingv1.PathTypePrefix, "web"),
+				path("/foo", networkingv1.PathTypeExact, "exact"),
+				path("/foo/", networkingv1.PathTypePrefix, "foo"),
+			},
+		},
+		{
+			name:     "shorter prefix of another Ingress",
+			paths:    []networkingv1.HTTPIngressPath{path("/foo/", networkingv1.PathTypePrefix, "foo")},
+			siblings: []networkingv1.HTTPIngressPath{path("/", networkingv1.PathTypePrefix, "web")},
+		},
+		{
+			name:      "prefix of another Ingress with a trailing slash",
+			paths:     []networkingv1.HTTPIngressPath{path("/", networkingv1.PathTypePrefix, "web")},
+			siblings:  []networkingv1.HTTPIngressPath{path("/foo/", networkingv1.PathTypePrefix, "foo")},
+			wantExact: "web",
+		},
+		{
+			name:        "no shorter prefix",
+			paths:       []networkingv1.HTTPIngressPath{path("/foo/", networkingv1.PathTypePrefix, "foo")},
+			wantWarning: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var siblings []networkingv1.Ingress
+			if tt.siblings != nil {
+				sibling := tlsTestIngress([]string{"example.com"}, nil)
+				sibling.Name = "sibling"
+				sibling.Spec.Rules[0].HTTP.Paths = tt.siblings
+				siblings = append(siblings, *sibling)
+			}
+			c := NewWithResolvers(&config.Config{
+				GatewayName:      "eg-gateway",
+				GatewayNamespace: "envoy-gateway",
+			}, Resolvers{Ingresses: &staticIngressResolver{ingresses: siblings}})
+			ingress := tlsTestIngress([]string{"example.com"}, nil)
+			ingress.Spec.Rules[0].HTTP.Paths = tt.paths
+
+			result := c.ConvertIngressFull(context.Background(), ingress)
+
+			var exact string
+			for _, rule := range result.HTTPRoutes[0].Spec.Rules {
+				match := rule.Matches[0].Path
+				if *match.Type == gatewayv1.PathMatchExact && *match.Value == "/foo" {
+					exact = string(rule.BackendRefs[0].Name)
+				}
+			}
+			if tt.wantExact != "" && exact != tt.wantExact {
+				t.Errorf("expected an Exact /foo match for %s, got %q", tt.wantExact, exact)
+			}
+			if tt.wantExact == "" && exact != "" && exact != "exact" {
+				t.Errorf("expected no added Exact /foo match, got one for %s", exact)
+			}
+
+			warned := slices.ContainsFunc(result.Warnings, func(w string) bool {
+				return strings.Contains(w, "nginx does not route them to any path")
+			})
+			if warned != tt.wantWarning {
+				t.Errorf("expected warning %v, got %v", tt.wantWarning, result.Warnings)
+			}
+		})
+	}
+}
+
+func TestConvertIngressFull_CharacterPrefixPaths(t *testing.T) {
+	backend := networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+		Name: "web",
+		Port: networkingv1.ServiceBackendPort{Number: 80},
+	}}
+
+	tests := []struct {
+		name        string
+		paths       []networkingv1.HTTPIngressPath
+		wantType    gatewayv1.PathMatchType
+		wantValue   string
+		matches     []string
+		unmatched   []string
+		wantWarning bool
+	}{
+		{
+			name:      "ImplementationSpecific matches any characters",
+			paths:     []networkingv1.HTTPIngressPath{{Path: "/api", PathType: ptr(networkingv1.PathTypeImplementationSpecific)}},
+			wantType:  gatewayv1.PathMatchRegularExpression,
+			wantValue: "/api.*",
+			matches:   []string{"/api", "/apix", "/api/users"},
+			unmatched: []string{"/ap", "/API", "/v1/api"},
+		},
+		{
+			name:      "regex characters are literal",
+			paths:     []networkingv1.HTTPIngressPath{{Path: "/v1.0", PathType: ptr(networkingv1.PathTypeImplementationSpecific)}},
+			wantType:  gatewayv1.PathMatchRegularExpression,
+			wantValue: `/v1\.0.*`,
+			matches:   []string{"/v1.0", "/v1.0/items"},
+			unmatched: []string{"/v1x0"},
+		},
+		{
+			name:      "trailing slash stays a prefix",
+			paths:     []networkingv1.HTTPIngressPath{{Path: "/api/", PathType: ptr(networkingv1.PathTypeImplementationSpecific)}},
+			wantType:  gatewayv1.PathMatchPathPrefix,
+			wantValue: "/api/",
+		},
+		{
+			name:      "Prefix matches whole segments",
+			paths:     []networkingv1.HTTPIngressPath{{Path: "/api", PathType: ptr(networkingv1.PathTypePrefix)}},
+			wantType:  gatewayv1.PathMatchPathPrefix,
+			wantValue: "/api",
+		},
+		{
+			name: "longer Prefix path keeps the PathPrefix",
+			paths: []networkingv1.HTTPIngressPath{
+				{Path: "/api", PathType: ptr(networkingv1.PathTypeImplementationSpecific)},
+				{Path: "/api/v2", PathType: ptr(networkingv1.PathTypePrefix)},
+			},
+			wantType:    gatewayv1.PathMatchPathPrefix,
+			wantValue:   "/api",
+			wantWarning: true,
+		},
+		{
+			name: "longer ImplementationSpecific path keeps the PathPrefix",
+			paths: []networkingv1.HTTPIngressPath{
+				{Path: "/api", PathType: ptr(networkingv1.PathTypeImplementationSpecific)},
+				{Path: "/apix", PathType: ptr(networkingv1.PathTypeImplementationSpecific)},
+			},
+			wantType:    gatewayv1.PathMatchPathPrefix,
+			wantValue:   "/api",
+			wantWarning: true,
+		},
+		{
+			name: "shorter path does not matter",
+			paths: []networkingv1.HTTPIngressPath{
+				{Path: "/api", PathType: ptr(networkingv1.PathTypeImplementationSpecific)},
+				{Path: "/", PathType: ptr(networkingv1.PathTypePrefix)},
+			},
+			wantType:  gatewayv1.PathMatchRegularExpression,
+			wantValue: "/api.*",
+			matches:   []string{"/apix"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(&config.Config{
+				GatewayName:      "eg-gateway",
+				GatewayNamespace: "envoy-gateway",
+			})
+			ingress := tlsTestIngress([]string{"example.com"}, nil)
+			for i := range tt.paths {
+				tt.paths[i].Backend = backend
+			}
+			ingress.Spec.Rules[0].HTTP.Paths = tt.paths
+
+			result := c.ConvertIngressFull(context.Background(), ingress)
+
+			match := result.HTTPRoutes[0].Spec.Rules[0].Matches[0].Path
+			if *match.Type != tt.wantType || *match.Value != tt.wantValue {
+				t.Fatalf("expected %s %s, got %s %s", tt.wantType, tt.wantValue, *match.Type, *match.Value)
+			}
+			// Envoy matches the regex against the whole path
+			re := regexp.MustCompile("^(?:" + *match.Value + ")$")
+			for _, request := range tt.matches {
+				if !re.MatchString(request) {
+					t.Errorf("expected %s to match %s", *match.Value, request)
+				}
+			}
+			for _, request := range tt.unmatched {
+				if re.MatchString(request) {
+					t.Errorf("expected %s not to match %s", *match.Value, request)
+				}
+			}
+
+			warned := slices.ContainsFunc(result.Warnings, func(w string) bool {
+				return strings.Contains(w, "are not routed to path /api as in nginx")
+			})
+			if warned != tt.wantWarning {
+				t.Errorf("expected warning %v, got %v", tt.wantWarning, result.Warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,398 @@
+package converter
+
+import (
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strings"
+	"time"
+
+	egv1alpha1 "github.com/envoyproxy/gateway/api/v1alpha1"
+
+	"github.com/werdnum/ingress-gateway-api/internal/annotations"
+)
+
+// defaultBurstMultiplier is the ingress-nginx default for limit-burst-multiplier.
+const defaultBurstMultiplier = 5
+
+// Rule limits of Envoy Gateway rate limits.
+const (
+	maxLocalRateLimitRules  = 16
+	maxGlobalRateLimitRules = 128
+)
+
+// defaultGlobalRateLimitKey is the ingress-nginx default for global-rate-limit-key.
+const defaultGlobalRateLimitKey = "$remote_addr"
+
+// rateLimitUnits are the Envoy Gateway rate limit units a window converts to, longest first.
+var rateLimitUnits = []struct {
+	unit     egv1alpha1.RateLimitUnit
+	duration time.Duration
+}{
+	{egv1alpha1.RateLimitUnitDay, 24 * time.Hour},
+	{egv1alpha1.RateLimitUnitHour, time.Hour},
+	{egv1alpha1.RateLimitUnitMinute, time.Minute},
+	{egv1alpha1.RateLimitUnitSecond, time.Second},
+}
+
+// nginxVariablePattern matches the $name and ${name} variables of an nginx expression.
+var nginxVariablePattern = regexp.MustCompile(`\$(?:\{(\w+)\}|(\w+))`)
+
+// rateLimitWindow is a number of requests allowed per unit of time.
+type rateLimitWindow struct {
+	requests int
+	unit     egv1alpha1.RateLimitUnit
+}
+
+// buildRateLimit creates the local and global rate limits of the rate limit annotations.
+// Global rate limits are left out unless Envoy Gateway has them enabled, as Envoy Gateway
+// rejects the whole policy otherwise. When limit-whitelist leaves more client CIDRs than
+// a local rate limit has rules for, limit-rps and limit-rpm go to the global rate limit
+// where it is enabled.
+func (c *Converter) buildRateLimit(annots annotations.AnnotationSet) (*egv1alpha1.RateLimitSpec, []string) {
+	rateLimit := &egv1alpha1.RateLimitSpec{}
+	var warnings []string
+
+	if annots.HasGlobalRateLimit() {
+		if c.cfg.GlobalRateLimit {
+			var globalWarnings []string
+			rateLimit.Global, globalWarnings = buildGlobalRateLimit(annots)
+			warnings = append(warnings, globalWarnings...)
+		} else {
+			warnings = append(warnings, fmt.Sprintf(
+				"%s is ignored, global rate limiting is not enabled in Envoy Gateway", annotations.GlobalRateLimit))
+		}
+	}
+
+	if annots.HasRateLimit() {
+		var globalRules []egv1alpha1.RateLimitRule
+		if rateLimit.Global != nil {
+			globalRules = rateLimit.Global.Rules
+		}
+		windows, clients, localWarnings := rateLimitClients(annots)
+		rules := rateLimitRules(windows, clients)
+		if c.cfg.GlobalRateLimit && len(rules) > maxLocalRateLimitRules &&
+			len(globalRules)+len(rules) <= maxGlobalRateLimitRules {
+			rateLimit.Global = &egv1alpha1.GlobalRateLimit{Rules: append(globalRules, rules...)}
+			localWarnings = append(localWarnings, fmt.Sprintf(
+				"%s leaves %d client CIDRs to rate limit, more than a local rate limit holds; %s and %s are enforced "+
+					"by the global rate limit service, which counts the requests to all Envoy proxies together",
+				annotations.LimitWhitelist, len(clients), annotations.LimitRPS, annotations.LimitRPM))
+		} else {
+			rateLimit.Local, localWarnings = buildLocalRateLimit(annots)
+		}
+		warnings = append(warnings, localWarnings...)
+	}
+
+	if rateLimit.Local == nil && rateLimit.Global == nil {
+		return nil, warnings
+	}
+	return rateLimit, warnings
+}
+
+// buildLocalRateLimit creates the local rate limit for limit-rps and limit-rpm, with
+// buckets for each client IP outside limit-whitelist. Envoy Gateway applies the strictest
+// of the rules a request matches, so whitelisted clients are left out by matching the
+// remaining client CIDRs, with a rule for each in every window. When they do not all fit,
+// the longer windows are dropped, and when not even one window fits, requests are not
+// rate limited rather than limiting the whitelisted clients too.
+func buildLocalRateLimit(annots annotations.AnnotationSet) (*egv1alpha1.LocalRateLimit, []string) {
+	windows, clients, warnings := rateLimitClients(annots)
+	if len(windows) == 0 || len(clients) == 0 {
+		return nil, warnings
+	}
+
+	if len(clients) > maxLocalRateLimitRules {
+		return nil, append(warnings, fmt.Sprintf(
+			"%s leaves %d client CIDRs to rate limit, more than the %d rules of a local rate limit; "+
+				"requests are not rate limited, as whitelisted clients would be too",
+			annotations.LimitWhitelist, len(clients), maxLocalRateLimitRules))
+	}
+	if kept := maxLocalRateLimitRules / len(clients); kept < len(windows) {
+		for _, window := range windows[kept:] {
+			warnings = append(warnings, fmt.Sprintf(
+				"%s leaves %d client CIDRs to rate limit, too many for every limit; the limit of %d requests per %s is not applied",
+				annotations.LimitWhitelist, len(clients), window.requests, strings.ToLower(string(window.unit))))
+		}
+		windows = windows[:kept]
+	}
+
+	return &egv1alpha1.LocalRateLimit{Rules: rateLimitRules(windows, clients)}, warnings
+}
+
+// rateLimitClients returns the windows of limit-rps and limit-rpm and the client CIDRs
+// outside limit-whitelist. nginx lets a client burst above the rate and then drains the
+// burst at the rate, while Envoy refills a bucket all at once every unit. Each limit
+// therefore allows the burst within one unit, and the rate over the next larger unit.
+func rateLimitClients(annots annotations.AnnotationSet) ([]rateLimitWindow, []netip.Prefix, []string) {
+	rps, warnings := limitValue(annots, annotations.LimitRPS)
+	rpm, rpmWarnings := limitValue(annots, annotations.LimitRPM)
+	warnings = append(warnings, rpmWarnings...)
+
+	multiplier := defaultBurstMultiplier
+	if value, ok := annots.GetString(annotations.LimitBurstMultiplier); ok {
+		if m, ok := annots.GetInt(annotations.LimitBurstMultiplier); ok && m > 0 {
+			multiplier = m
+		} else {
+			warnings = append(warnings, fmt.Sprintf("%s %q is not a positive integer, using %d",
+				annotations.LimitBurstMultiplier, value, defaultBurstMultiplier))
+		}
+	}
+
+	var windows []rateLimitWindow
+	if rps > 0 {
+		windows = append(windows, rateLimitWindow{rps * multiplier, egv1alpha1.RateLimitUnitSecond})
+		if multiplier > 1 {
+			windows = append(windows, rateLimitWindow{rps * 60, egv1alpha1.RateLimitUnitMinute})
+		}
+	}
+	if rpm > 0 {
+		windows = append(windows, rateLimitWindow{rpm * multiplier, egv1alpha1.RateLimitUnitMinute})
+		if multiplier > 1 {
+			windows = append(windows, rateLimitWindow{rpm * 60, egv1alpha1.RateLimitUnitHour})
+		}
+	}
+	if len(windows) == 0 {
+		return nil, nil, warnings
+	}
+
+	clients, whitelistWarnings := rateLimitedClients(annots, annotations.LimitWhitelist)
+	return windows, clients, append(warnings, whitelistWarnings...)
+}
+
+// rateLimitRules creates a rule for each window with a bucket for each client IP in
+// each of the client CIDRs, in window order.
+func rateLimitRules(windows []rateLimitWindow, clients []netip.Prefix) []egv1alpha1.RateLimitRule {
+	var rules []egv1alpha1.RateLimitRule
+	for _, window := range windows {
+		for _, client := range clients {
+			rules = append(rules, egv1alpha1.RateLimitRule{
+				ClientSelectors: []egv1alpha1.RateLimitSelectCondition{{
+					SourceCIDR: &egv1alpha1.SourceMatch{
+						Type:  ptr(egv1alpha1.SourceMatchDistinct),
+						Value: client.String(),
+					},
+				}},
+				Limit: egv1alpha1.RateLimitValue{
+					Requests: uint(window.requests),
+					Unit:     window.unit,
+				},
+			})
+		}
+	}
+	return rules
+}
+
+// buildGlobalRateLimit creates the global rate limit for global-rate-limit, with a counter
+// for each value of global-rate-limit-key. Each host has its own counters, where
+// ingress-nginx counts the requests to all hosts of the Ingress together. Requests without
+// a header or query parameter of the key are not counted.
+func buildGlobalRateLimit(annots annotations.AnnotationSet) (*egv1alpha1.GlobalRateLimit, []string) {
+	limit, warnings := limitValue(annots, annotations.GlobalRateLimit)
+	if limit == 0 {
+		return nil, warnings
+	}
+
+	value, _ := annots.GetString(annotations.GlobalRateLimitWindow)
+	window, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil || window <= 0 {
+		return nil, append(warnings, fmt.Sprintf("%s %q is not a valid window; requests are not globally rate limited",
+			annotations.GlobalRateLimitWindow, value))
+	}
+	requests, unit, exact := rateLimitPerUnit(limit, window)
+	if !exact {
+		warnings = append(warnings, fmt.Sprintf("%s %s is not an Envoy Gateway rate limit unit, the limit is converted to %d requests per %s",
+			annotations.GlobalRateLimitWindow, window, requests, strings.ToLower(string(unit))))
+	}
+
+	key, ok := annots.GetString(annotations.GlobalRateLimitKey)
+	if !ok {
+		key = defaultGlobalRateLimitKey
+	}
+	selector, byClientIP, err := globalRateLimitSelector(key)
+	if err != nil {
+		return nil, append(warnings, fmt.Sprintf("%s %q %v; requests are not globally rate limited",
+			annotations.GlobalRateLimitKey, key, err))
+	}
+
+	rule := egv1alpha1.RateLimitRule{
+		Limit: egv1alpha1.RateLimitValue{Requests: uint(requests), Unit: unit},
+	}
+	if !byClientIP && !annots.HasGlobalRateLimitIgnoredCIDRs() {
+		if len(selector.Headers) > 0 || len(selector.QueryParams) > 0 {
+			rule.ClientSelectors = []egv1alpha1.RateLimitSelectCondition{selector}
+		}
+		return &egv1alpha1.GlobalRateLimit{Rules: []egv1alpha1.RateLimitRule{rule}}, warnings
+	}
+
+	// Requests from ignored CIDRs are left out by matching the remaining clients. A key
+	// without the client IP is then counted separately for each of the remaining CIDRs.
+	clients, cidrWarnings := rateLimitedClients(annots, annotations.GlobalRateLimitIgnoredCIDRs)
+	warnings = append(warnings, cidrWarnings...)
+	sourceMatch := egv1alpha1.SourceMatchDistinct
+	if !byClientIP {
+		sourceMatch = egv1alpha1.SourceMatchExact
+		if len(clients) > 1 {
+			warnings = append(warnings, fmt.Sprintf(
+				"%s leaves %d client CIDRs to rate limit, requests from each are counted separately",
+				annotations.GlobalRateLimitIgnoredCIDRs, len(clients)))
+		}
+	}
+	if len(clients) > maxGlobalRateLimitRules {
+		return nil, append(warnings, fmt.Sprintf(
+			"%s leaves %d client CIDRs to rate limit, more than Envoy Gateway supports; requests are not globally rate limited",
+			annotations.GlobalRateLimitIgnoredCIDRs, len(clients)))
+	}
+
+	var rules []egv1alpha1.RateLimitRule
+	for _, client := range clients {
+		clientSelector := *selector.DeepCopy()
+		clientSelector.SourceCIDR = &egv1alpha1.SourceMatch{Type: ptr(sourceMatch), Value: client.String()}
+		clientRule := rule
+		clientRule.ClientSelectors = []egv1alpha1.RateLimitSelectCondition{clientSelector}
+		rules = append(rules, clientRule)
+	}
+	if len(rules) == 0 {
+		return nil, warnings
+	}
+	return &egv1alpha1.GlobalRateLimit{Rules: rules}, warnings
+}
+
+// globalRateLimitSelector converts the variables of global-rate-limit-key into a selector
+// with a counter for each distinct header and query parameter value, and whether the key
+// includes the client IP. Text around the variables does not change which requests share
+// a counter.
+func globalRateLimitSelector(key string) (egv1alpha1.RateLimitSelectCondition, bool, error) {
+	var selector egv1alpha1.RateLimitSelectCondition
+	byClientIP := false
+	seen := make(map[string]bool)
+
+	for _, match := range nginxVariablePattern.FindAllStringSubmatch(key, -1) {
+		variable := match[1] + match[2]
+		if seen[variable] {
+			continue
+		}
+		seen[variable] = true
+
+		switch {
+		case variable == "remote_addr" || variable == "binary_remote_addr":
+			byClientIP = true
+		case strings.HasPrefix(variable, "http_"):
+			// nginx lowercases header names and replaces dashes with underscores
+			selector.Headers = append(selector.Headers, egv1alpha1.HeaderMatch{
+				Type: ptr(egv1alpha1.HeaderMatchDistinct),
+				Name: strings.ReplaceAll(strings.TrimPrefix(variable, "http_"), "_", "-"),
+			})
+		case strings.HasPrefix(variable, "arg_"):
+			selector.QueryParams = append(selector.QueryParams, egv1alpha1.QueryParamMatch{
+				Type: ptr(egv1alpha1.QueryParamMatchDistinct),
+				Name: strings.TrimPrefix(variable, "arg_"),
+			})
+		default:
+			return selector, false, fmt.Errorf("uses $%s, which cannot be converted", variable)
+		}
+	}
+
+	return selector, byClientIP, nil
+}
+
+// rateLimitPerUnit converts a limit over an nginx window into a limit per the longest
+// rate limit unit that fits in the window, or per second for shorter windows. It also
+// returns whether the window is exactly that unit.
+func rateLimitPerUnit(limit int, window time.Duration) (int, egv1alpha1.RateLimitUnit, bool) {
+	unit := rateLimitUnits[len(rateLimitUnits)-1]
+	for _, u := range rateLimitUnits {
+		if u.duration <= window {
+			unit = u
+			break
+		}
+	}
+	return max(int(time.Duration(limit)*unit.duration/window), 1), unit.unit, unit.duration == window
+}
+
+// buildConnectionLimit creates a circuit breaker for limit-connections. Envoy Gateway
+// cannot count the connections of each client, so the limit caps the parallel requests
+// of all clients together, as seen by each Envoy proxy.
+func buildConnectionLimit(annots annotations.AnnotationSet) (*egv1alpha1.CircuitBreaker, []string) {
+	connections, warnings := limitValue(annots, annotations.LimitConnections)
+	if connections == 0 {
+		return nil, warnings
+	}
+
+	return &egv1alpha1.CircuitBreaker{MaxParallelRequests: ptr(int64(connections))}, append(warnings, fmt.Sprintf(
+		"%s %d limits the parallel requests of all clients together, not of each client IP",
+		annotations.LimitConnections, connections))
+}
+
+// rateLimitWarnings reports the rate limit annotations that cannot be converted as is.
+func (c *Converter) rateLimitWarnings(annots annotations.AnnotationSet) []string {
+	var warnings []string
+	if annots.HasRateLimit() || annots.HasGlobalRateLimit() {
+		_, warnings = c.buildRateLimit(annots)
+	}
+	if annots.HasConnectionLimit() {
+		_, connectionWarnings := buildConnectionLimit(annots)
+		warnings = append(warnings, connectionWarnings...)
+	}
+	return warnings
+}
+
+// limitValue returns a limit annotation, where 0 or no annotation means no limit.
+func limitValue(annots annotations.AnnotationSet, key string) (int, []string) {
+	value, ok := annots.GetString(key)
+	if !ok {
+		return 0, nil
+	}
+	limit, ok := annots.GetInt(key)
+	if !ok || limit < 0 {
+		return 0, []string{fmt.Sprintf("%s %q is not a valid limit and is ignored", key, value)}
+	}
+	return limit, nil
+}
+
+// rateLimitedClients returns the CIDRs covering every client address outside the CIDRs
+// of the given annotation. Like ingress-nginx, entries may be addresses or CIDRs.
+func rateLimitedClients(annots annotations.AnnotationSet, key string) ([]netip.Prefix, []string) {
+	var whitelist []netip.Prefix
+	var warnings []string
+	entries, _ := annots.GetStringSlice(key)
+	for _, entry := range entries {
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(entry)
+			if addrErr != nil {
+				warnings = append(warnings, fmt.Sprintf(
+					"%s entry %q is not an IP address or CIDR and is ignored", key, entry))
+				continue
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		whitelist = append(whitelist, prefix.Masked())
+	}
+
+	clients := excludePrefixes(netip.MustParsePrefix("0.0.0.0/0"), whitelist)
+	return append(clients, excludePrefixes(netip.MustParsePrefix("::/0"), whitelist)...), warnings
+}
+
+// excludePrefixes returns the fewest CIDRs covering the addresses of prefix outside the
+// excluded CIDRs.
+func excludePrefixes(prefix netip.Prefix, excluded []netip.Prefix) []netip.Prefix {
+	overlaps := false
+	for _, e := range excluded {
+		if e.Bits() <= prefix.Bits() && e.Contains(prefix.Addr()) {
+			return nil
+		}
+		overlaps = overlaps || e.Overlaps(prefix)
+	}
+	if !overlaps {
+		return []netip.Prefix{prefix}
+	}
+
+	// Split into halves, one of which holds an excluded CIDR
+	upper := prefix.Addr().AsSlice()
+	upper[prefix.Bits()/8] |= 0x80 >> (prefix.Bits() % 8)
+	upperAddr, _ := netip.AddrFromSlice(upper)
+	return append(
+		excludePrefixes(netip.PrefixFrom(prefix.Addr(), prefix.Bits()+1), excluded),
+		excludePrefixes(netip.PrefixFrom(upperAddr, prefix.Bits()+1), excluded)...)
+}
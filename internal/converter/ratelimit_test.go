@@ -0,0 +1,443 @@
+package converter
+
+import (
+	"net/netip"
+	"slices"
+	"strings"
+	"testing"
+
+	egv1alpha1 "github.com/envoyproxy/gateway/api/v1alpha1"
+
+	"github.com/werdnum/ingress-gateway-api/internal/annotations"
+	"github.com/werdnum/ingress-gateway-api/internal/config"
+)
+
+func TestBuildLocalRateLimit(t *testing.T) {
+	type wantRule struct {
+		cidr     string
+		requests uint
+		unit     egv1alpha1.RateLimitUnit
+	}
+
+	tests := []struct {
+		name        string
+		annots      map[string]string
+		wantRules   []wantRule
+		wantWarning string
+	}{
+		{
+			name:   "rps with the default burst",
+			annots: map[string]string{annotations.LimitRPS: "10"},
+			wantRules: []wantRule{
+				{"0.0.0.0/0", 50, egv1alpha1.RateLimitUnitSecond},
+				{"::/0", 50, egv1alpha1.RateLimitUnitSecond},
+				{"0.0.0.0/0", 600, egv1alpha1.RateLimitUnitMinute},
+				{"::/0", 600, egv1alpha1.RateLimitUnitMinute},
+			},
+		},
+		{
+			name: "rpm without a burst",
+			annots: map[string]string{
+				annotations.LimitRPM:             "100",
+				annotations.LimitBurstMultiplier: "1",
+			},
+			wantRules: []wantRule{
+				{"0.0.0.0/0", 100, egv1alpha1.RateLimitUnitMinute},
+				{"::/0", 100, egv1alpha1.RateLimitUnitMinute},
+			},
+		},
+		{
+			name: "whitelisted CIDRs",
+			annots: map[string]string{
+				annotations.LimitRPS:             "1",
+				annotations.LimitBurstMultiplier: "1",
+				annotations.LimitWhitelist:       "128.0.0.0/1, ::/0",
+			},
+			wantRules: []wantRule{
+				{"0.0.0.0/1", 1, egv1alpha1.RateLimitUnitSecond},
+			},
+		},
+		{
+			name: "whitelist keeps every window",
+			annots: map[string]string{
+				annotations.LimitRPS:       "10",
+				annotations.LimitWhitelist: "128.0.0.0/1",
+			},
+			wantRules: []wantRule{
+				{"0.0.0.0/1", 50, egv1alpha1.RateLimitUnitSecond},
+				{"::/0", 50, egv1alpha1.RateLimitUnitSecond},
+				{"0.0.0.0/1", 600, egv1alpha1.RateLimitUnitMinute},
+				{"::/0", 600, egv1alpha1.RateLimitUnitMinute},
+			},
+		},
+		{
+			name: "whitelist leaving room for the burst window only",
+			annots: map[string]string{
+				annotations.LimitRPS:       "1",
+				annotations.LimitWhitelist: "0.0.0.0/9,::/0",
+			},
+			wantRules: []wantRule{
+				{"0.128.0.0/9", 5, egv1alpha1.RateLimitUnitSecond},
+				{"1.0.0.0/8", 5, egv1alpha1.RateLimitUnitSecond},
+				{"2.0.0.0/7", 5, egv1alpha1.RateLimitUnitSecond},
+				{"4.0.0.0/6", 5, egv1alpha1.RateLimitUnitSecond},
+				{"8.0.0.0/5", 5, egv1alpha1.RateLimitUnitSecond},
+				{"16.0.0.0/4", 5, egv1alpha1.RateLimitUnitSecond},
+				{"32.0.0.0/3", 5, egv1alpha1.RateLimitUnitSecond},
+				{"64.0.0.0/2", 5, egv1alpha1.RateLimitUnitSecond},
+				{"128.0.0.0/1", 5, egv1alpha1.RateLimitUnitSecond},
+			},
+			wantWarning: "the limit of 60 requests per minute is not applied",
+		},
+		{
+			name: "whitelist with too many CIDRs to rate limit",
+			annots: map[string]string{
+				annotations.LimitRPS:       "1",
+				annotations.LimitWhitelist: "10.0.0.1",
+			},
+			wantWarning: "requests are not rate limited, as whitelisted clients would be too",
+		},
+		{
+			name: "invalid burst multiplier",
+			annots: map[string]string{
+				annotations.LimitRPS:             "2",
+				annotations.LimitBurstMultiplier: "0",
+				annotations.LimitWhitelist:       "::/0",
+			},
+			wantRules: []wantRule{
+				{"0.0.0.0/0", 10, egv1alpha1.RateLimitUnitSecond},
+				{"0.0.0.0/0", 120, egv1alpha1.RateLimitUnitMinute},
+			},
+			wantWarning: "is not a positive integer",
+		},
+		{
+			name:        "invalid limit",
+			annots:      map[string]string{annotations.LimitRPS: "fast"},
+			wantWarning: "is not a valid limit",
+		},
+		{
+			name:   "zero disables the limit",
+			annots: map[string]string{annotations.LimitRPM: "0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rateLimit, warnings := buildLocalRateLimit(annotations.NewAnnotationSet(tt.annots))
+
+			var rules []egv1alpha1.RateLimitRule
+			if rateLimit != nil {
+				rules = rateLimit.Rules
+			}
+			if len(rules) != len(tt.wantRules) {
+				t.Fatalf("expected %d rules, got %d: %+v", len(tt.wantRules), len(rules), rules)
+			}
+			for i, want := range tt.wantRules {
+				rule := rules[i]
+				source := rule.ClientSelectors[0].SourceCIDR
+				if source.Value != want.cidr || *source.Type != egv1alpha1.SourceMatchDistinct {
+					t.Errorf("rule %d: expected distinct clients in %s, got %+v", i, want.cidr, source)
+				}
+				if rule.Limit.Requests != want.requests || rule.Limit.Unit != want.unit {
+					t.Errorf("rule %d: expected %d per %s, got %+v", i, want.requests, want.unit, rule.Limit)
+				}
+			}
+
+			warned := slices.ContainsFunc(warnings, func(w string) bool {
+				return tt.wantWarning != "" && strings.Contains(w, tt.wantWarning)
+			})
+			if warned != (tt.wantWarning != "") || (tt.wantWarning == "" && len(warnings) != 0) {
+				t.Errorf("expected warning %q, got %v", tt.wantWarning, warnings)
+			}
+		})
+	}
+}
+
+func TestBuildGlobalRateLimit(t *testing.T) {
+	type wantRule struct {
+		cidr        string // source CIDR selector, empty for none
+		cidrType    egv1alpha1.SourceMatchType
+		headers     []string
+		queryParams []string
+	}
+
+	tests := []struct {
+		name         string
+		annots       map[string]string
+		wantRequests uint
+		wantUnit     egv1alpha1.RateLimitUnit
+		wantRules    []wantRule
+		wantWarning  string
+	}{
+		{
+			name: "default key",
+			annots: map[string]string{
+				annotations.GlobalRateLimit:       "100",
+				annotations.GlobalRateLimitWindow: "1m",
+			},
+			wantRequests: 100,
+			wantUnit:     egv1alpha1.RateLimitUnitMinute,
+			wantRules: []wantRule{
+				{cidr: "0.0.0.0/0", cidrType: egv1alpha1.SourceMatchDistinct},
+				{cidr: "::/0", cidrType: egv1alpha1.SourceMatchDistinct},
+			},
+		},
+		{
+			name: "header and query parameter key",
+			annots: map[string]string{
+				annotations.GlobalRateLimit:       "10",
+				annotations.GlobalRateLimitWindow: "1s",
+				annotations.GlobalRateLimitKey:    "${http_x_api_key}:$arg_tenant",
+			},
+			wantRequests: 10,
+			wantUnit:     egv1alpha1.RateLimitUnitSecond,
+			wantRules:    []wantRule{{headers: []string{"x-api-key"}, queryParams: []string{"tenant"}}},
+		},
+		{
+			name: "constant key",
+			annots: map[string]string{
+				annotations.GlobalRateLimit:       "1000",
+				annotations.GlobalRateLimitWindow: "1h",
+				annotations.GlobalRateLimitKey:    "everyone",
+			},
+			wantRequests: 1000,
+			wantUnit:     egv1alpha1.RateLimitUnitHour,
+			wantRules:    []wantRule{{}},
+		},
+		{
+			name: "window between units",
+			annots: map[string]string{
+				annotations.GlobalRateLimit:       "100",
+				annotations.GlobalRateLimitWindow: "10s",
+			},
+			wantRequests: 10,
+			wantUnit:     egv1alpha1.RateLimitUnitSecond,
+			wantRules: []wantRule{
+				{cidr: "0.0.0.0/0", cidrType: egv1alpha1.SourceMatchDistinct},
+				{cidr: "::/0", cidrType: egv1alpha1.SourceMatchDistinct},
+			},
+			wantWarning: "is converted to 10 requests per second",
+		},
+		{
+			name: "ignored CIDRs",
+			annots: map[string]string{
+				annotations.GlobalRateLimit:             "5",
+				annotations.GlobalRateLimitWindow:       "1m",
+				annotations.GlobalRateLimitIgnoredCIDRs: "0.0.0.0/1,::/0",
+			},
+			wantRequests: 5,
+			wantUnit:     egv1alpha1.RateLimitUnitMinute,
+			wantRules:    []wantRule{{cidr: "128.0.0.0/1", cidrType: egv1alpha1.SourceMatchDistinct}},
+		},
+		{
+			name: "ignored CIDRs with a header key",
+			annots: map[string]string{
+				annotations.GlobalRateLimit:             "5",
+				annotations.GlobalRateLimitWindow:       "1m",
+				annotations.GlobalRateLimitKey:          "$http_x_api_key",
+				annotations.GlobalRateLimitIgnoredCIDRs: "::/0",
+			},
+			wantRequests: 5,
+			wantUnit:     egv1alpha1.RateLimitUnitMinute,
+			wantRules: []wantRule{
+				{cidr: "0.0.0.0/0", cidrType: egv1alpha1.SourceMatchExact, headers: []string{"x-api-key"}},
+			},
+		},
+		{
+			name: "every client ignored",
+			annots: map[string]string{
+				annotations.GlobalRateLimit:             "5",
+				annotations.GlobalRateLimitWindow:       "1m",
+				annotations.GlobalRateLimitIgnoredCIDRs: "0.0.0.0/0,::/0",
+			},
+		},
+		{
+			name: "unsupported key variable",
+			annots: map[string]string{
+				annotations.GlobalRateLimit:       "5",
+				annotations.GlobalRateLimitWindow: "1m",
+				annotations.GlobalRateLimitKey:    "$remote_addr$uri",
+			},
+			wantWarning: "uses $uri, which cannot be converted",
+		},
+		{
+			name:        "no window",
+			annots:      map[string]string{annotations.GlobalRateLimit: "5"},
+			wantWarning: "is not a valid window",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			global, warnings := buildGlobalRateLimit(annotations.NewAnnotationSet(tt.annots))
+
+			var rules []egv1alpha1.RateLimitRule
+			if global != nil {
+				rules = global.Rules
+			}
+			if len(rules) != len(tt.wantRules) {
+				t.Fatalf("expected %d rules, got %d: %+v", len(tt.wantRules), len(rules), rules)
+			}
+			for i, want := range tt.wantRules {
+				rule := rules[i]
+				if rule.Limit.Requests != tt.wantRequests || rule.Limit.Unit != tt.wantUnit {
+					t.Errorf("rule %d: expected %d per %s, got %+v", i, tt.wantRequests, tt.wantUnit, rule.Limit)
+				}
+				if want.cidr == "" && len(want.headers) == 0 && len(want.queryParams) == 0 {
+					if len(rule.ClientSelectors) != 0 {
+						t.Errorf("rule %d: expected no client selectors, got %+v", i, rule.ClientSelectors)
+					}
+					continue
+				}
+				selector := rule.ClientSelectors[0]
+				source := selector.SourceCIDR
+				if want.cidr != "" && (source == nil || source.Value != want.cidr || *source.Type != want.cidrType) {
+					t.Errorf("rule %d: expected %s clients in %s, got %+v", i, want.cidrType, want.cidr, source)
+				}
+				if want.cidr == "" && source != nil {
+					t.Errorf("rule %d: expected no source CIDR, got %+v", i, source)
+				}
+				var headers, queryParams []string
+				for _, header := range selector.Headers {
+					if *header.Type == egv1alpha1.HeaderMatchDistinct {
+						headers = append(headers, header.Name)
+					}
+				}
+				for _, param := range selector.QueryParams {
+					if *param.Type == egv1alpha1.QueryParamMatchDistinct {
+						queryParams = append(queryParams, param.Name)
+					}
+				}
+				if !slices.Equal(headers, want.headers) || !slices.Equal(queryParams, want.queryParams) {
+					t.Errorf("rule %d: expected distinct headers %v and query parameters %v, got %+v",
+						i, want.headers, want.queryParams, selector)
+				}
+			}
+
+			warned := slices.ContainsFunc(warnings, func(w string) bool {
+				return tt.wantWarning != "" && strings.Contains(w, tt.wantWarning)
+			})
+			if warned != (tt.wantWarning != "") || (tt.wantWarning == "" && len(warnings) != 0) {
+				t.Errorf("expected warning %q, got %v", tt.wantWarning, warnings)
+			}
+		})
+	}
+}
+
+func TestBuildRateLimit_GlobalRateLimitDisabled(t *testing.T) {
+	annots := annotations.NewAnnotationSet(map[string]string{
+		annotations.LimitRPS:              "10",
+		annotations.GlobalRateLimit:       "100",
+		annotations.GlobalRateLimitWindow: "1m",
+	})
+
+	for _, enabled := range []bool{true, false} {
+		c := New(&config.Config{GatewayName: "eg-gateway", GlobalRateLimit: enabled})
+		rateLimit, warnings := c.buildRateLimit(annots)
+		if rateLimit == nil || rateLimit.Local == nil || (rateLimit.Global != nil) != enabled {
+			t.Errorf("global rate limiting %v: unexpected rate limit %+v", enabled, rateLimit)
+		}
+		warned := len(warnings) == 1 && strings.Contains(warnings[0], "global rate limiting is not enabled")
+		if warned == enabled {
+			t.Errorf("global rate limiting %v: unexpected warnings %v", enabled, warnings)
+		}
+	}
+}
+
+func TestBuildRateLimit_WhitelistBeyondLocalRules(t *testing.T) {
+	annots := annotations.NewAnnotationSet(map[string]string{
+		annotations.LimitRPS:       "1",
+		annotations.LimitWhitelist: "10.0.0.1",
+	})
+
+	// The 33 client CIDRs of each window only fit in a global rate limit
+	c := New(&config.Config{GatewayName: "eg-gateway", GlobalRateLimit: true})
+	rateLimit, warnings := c.buildRateLimit(annots)
+	if rateLimit == nil || rateLimit.Local != nil || rateLimit.Global == nil || len(rateLimit.Global.Rules) != 66 {
+		t.Fatalf("expected 66 global rules, got %+v", rateLimit)
+	}
+	for _, rule := range rateLimit.Global.Rules {
+		if rule.ClientSelectors[0].SourceCIDR.Value == "10.0.0.1/32" {
+			t.Errorf("expected the whitelisted address not to be rate limited")
+		}
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "enforced by the global rate limit service") {
+		t.Errorf("unexpected warnings %v", warnings)
+	}
+
+	c = New(&config.Config{GatewayName: "eg-gateway"})
+	rateLimit, warnings = c.buildRateLimit(annots)
+	if rateLimit != nil {
+		t.Errorf("expected no rate limit, got %+v", rateLimit)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "requests are not rate limited") {
+		t.Errorf("unexpected warnings %v", warnings)
+	}
+}
+
+func TestExcludePrefixes(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefix   string
+		excluded []string
+		want     []string
+	}{
+		{
+			name:   "nothing excluded",
+			prefix: "0.0.0.0/0",
+			want:   []string{"0.0.0.0/0"},
+		},
+		{
+			name:     "everything excluded",
+			prefix:   "10.0.0.0/8",
+			excluded: []string{"0.0.0.0/0"},
+		},
+		{
+			name:     "other address family",
+			prefix:   "::/0",
+			excluded: []string{"10.0.0.0/8"},
+			want:     []string{"::/0"},
+		},
+		{
+			name:     "one address",
+			prefix:   "10.0.0.0/30",
+			excluded: []string{"10.0.0.2/32"},
+			want:     []string{"10.0.0.0/31", "10.0.0.3/32"},
+		},
+		{
+			name:     "two CIDRs",
+			prefix:   "192.168.0.0/22",
+			excluded: []string{"192.168.0.0/24", "192.168.3.0/24"},
+			want:     []string{"192.168.1.0/24", "192.168.2.0/24"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var excluded []netip.Prefix
+			for _, e := range tt.excluded {
+				excluded = append(excluded, netip.MustParsePrefix(e))
+			}
+
+			var got []string
+			for _, prefix := range excludePrefixes(netip.MustParsePrefix(tt.prefix), excluded) {
+				got = append(got, prefix.String())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBuildConnectionLimit(t *testing.T) {
+	breaker, warnings := buildConnectionLimit(annotations.NewAnnotationSet(map[string]string{
+		annotations.LimitConnections: "20",
+	}))
+	if breaker == nil || breaker.MaxParallelRequests == nil || *breaker.MaxParallelRequests != 20 {
+		t.Errorf("expected at most 20 parallel requests, got %+v", breaker)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "not of each client IP") {
+		t.Errorf("expected a warning about the limit applying to all clients, got %v", warnings)
+	}
+}
@@ -0,0 +1,274 @@
+package converter
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/werdnum/ingress-gateway-api/internal/annotations"
+)
+
+// sslRedirectStatusCode matches the ingress-nginx default http-redirect-code.
+const sslRedirectStatusCode = 308
+
+// Status codes of permanent-redirect and temporal-redirect, as in ingress-nginx.
+const (
+	permanentRedirectStatusCode = 301
+	temporalRedirectStatusCode  = 302
+)
+
+// requestURIVariable is the nginx variable holding the request path and query. It is
+// commonly appended to a redirect target to keep the path.
+const requestURIVariable = "$request_uri"
+
+// redirectStatusCodes are the status codes a Gateway API RequestRedirect filter accepts.
+var redirectStatusCodes = map[int]bool{301: true, 302: true, 303: true, 307: true, 308: true}
+
+// hostRouting returns the listener protocols the HTTPRoute for a rule host attaches to,
+// and whether plain HTTP requests for the host are redirected to HTTPS instead. Hosts
+// without TLS are served over HTTPS too when a Gateway listener already accepts them,
+// as ingress-nginx serves them with its default certificate.
+func hostRouting(ingress *networkingv1.Ingress, host string, annots annotations.AnnotationSet) ([]gatewayv1.ProtocolType, bool) {
+	if annots.RedirectsToHTTPS(hostHasTLS(ingress, host)) {
+		return []gatewayv1.ProtocolType{gatewayv1.HTTPSProtocolType}, true
+	}
+	return []gatewayv1.ProtocolType{gatewayv1.HTTPSProtocolType, gatewayv1.HTTPProtocolType}, false
+}
+
+// portParentRef creates a ParentReference to the listeners on one port of the shared Gateway.
+// It separates HTTP from HTTPS traffic when the Gateway's listeners are unknown.
+func (c *Converter) portParentRef(port gatewayv1.PortNumber) gatewayv1.ParentReference {
+	parentRef := c.createParentRef()
+	parentRef.Port = ptr(port)
+	return parentRef
+}
+
+// createSSLRedirectRoute creates a redirect-only HTTPRoute for the HTTP listener that sends
+// every request matched by the route to HTTPS.
+func (c *Converter) createSSLRedirectRoute(route *gatewayv1.HTTPRoute) *gatewayv1.HTTPRoute {
+	redirectRoute := &gatewayv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        route.Name + "-ssl-redirect",
+			Namespace:   route.Namespace,
+			Labels:      copyLabels(route.Labels),
+			Annotations: copyLabels(route.Annotations),
+		},
+		Spec: gatewayv1.HTTPRouteSpec{
+			Hostnames: route.Spec.Hostnames,
+		},
+	}
+
+	for _, rule := range route.Spec.Rules {
+		redirectRoute.Spec.Rules = append(redirectRoute.Spec.Rules, gatewayv1.HTTPRouteRule{
+			Matches: rule.Matches,
+			Filters: []gatewayv1.HTTPRouteFilter{
+				{
+					Type: gatewayv1.HTTPRouteFilterRequestRedirect,
+					RequestRedirect: &gatewayv1.HTTPRequestRedirectFilter{
+						Scheme:     ptr("https"),
+						StatusCode: ptr(sslRedirectStatusCode),
+					},
+				},
+			},
+		})
+	}
+
+	return redirectRoute
+}
+
+// annotationRedirect converts temporal-redirect or permanent-redirect into a
+// RequestRedirect filter. Like ingress-nginx, temporal-redirect takes precedence.
+// nginx returns the target URL as is, so the request path is replaced with the path of
+// the target, unless the target ends in $request_uri. Returns nil with a warning when
+// the target cannot be converted.
+func annotationRedirect(annots annotations.AnnotationSet) (*gatewayv1.HTTPRequestRedirectFilter, []string) {
+	var warnings []string
+	key, statusCode := annotations.TemporalRedirect, temporalRedirectStatusCode
+	target, ok := annots.GetString(annotations.TemporalRedirect)
+	if !ok {
+		key, statusCode = annotations.PermanentRedirect, permanentRedirectStatusCode
+		target, _ = annots.GetString(annotations.PermanentRedirect)
+		if value, ok := annots.GetString(annotations.PermanentRedirectCode); ok {
+			code, err := strconv.Atoi(value)
+			if err == nil && redirectStatusCodes[code] {
+				statusCode = code
+			} else {
+				warnings = append(warnings, fmt.Sprintf(
+					"permanent-redirect-code %q is not a supported redirect status code, using %d", value, statusCode))
+			}
+		}
+	}
+
+	keepPath := strings.HasSuffix(target, requestURIVariable)
+	target = strings.TrimSuffix(target, requestURIVariable)
+	if strings.Contains(target, "$") {
+		return nil, append(warnings, fmt.Sprintf(
+			"%s %q uses nginx variables, which are not supported; requests are not redirected", key, target))
+	}
+
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return nil, append(warnings, fmt.Sprintf(
+			"%s %q is not a valid http or https URL; requests are not redirected", key, target))
+	}
+
+	redirect := &gatewayv1.HTTPRequestRedirectFilter{
+		Scheme:     ptr(u.Scheme),
+		Hostname:   ptr(gatewayv1.PreciseHostname(u.Hostname())),
+		StatusCode: ptr(statusCode),
+	}
+	if u.Port() != "" {
+		port, err := strconv.ParseUint(u.Port(), 10, 16)
+		if err != nil || port == 0 {
+			return nil, append(warnings, fmt.Sprintf(
+				"%s %q has an invalid port; requests are not redirected", key, target))
+		}
+		redirect.Port = ptr(gatewayv1.PortNumber(port))
+	}
+
+	switch {
+	case keepPath && u.Path != "" && u.Path != "/":
+		warnings = append(warnings, fmt.Sprintf(
+			"%s cannot prefix the request path with %q, the request path is kept as is", key, u.Path))
+	case !keepPath:
+		path := u.Path
+		if path == "" {
+			path = "/"
+		}
+		redirect.Path = &gatewayv1.HTTPPathModifier{
+			Type:            gatewayv1.FullPathHTTPPathModifier,
+			ReplaceFullPath: ptr(path),
+		}
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		warnings = append(warnings, fmt.Sprintf(
+			"%s cannot set a query string or fragment, redirecting to %q without them", key, target))
+	}
+
+	return redirect, warnings
+}
+
+// annotationRedirectWarnings returns the warnings for the redirect annotations of an Ingress.
+func annotationRedirectWarnings(annots annotations.AnnotationSet) []string {
+	if !annots.HasRedirect() {
+		return nil
+	}
+	_, warnings := annotationRedirect(annots)
+	return warnings
+}
+
+// wwwCounterpart returns the host that from-to-www-redirect redirects to host: the host
+// without its www. prefix, or with one added. Wildcard hosts have no counterpart.
+func wwwCounterpart(host string) (string, bool) {
+	if host == "" || strings.HasPrefix(host, "*") {
+		return "", false
+	}
+	if counterpart, ok := strings.CutPrefix(host, "www."); ok {
+		return counterpart, counterpart != ""
+	}
+	return "www." + host, true
+}
+
+// ServedHosts is the set of rule hosts of the Ingresses the converter converts.
+type ServedHosts map[string]struct{}
+
+// ServedHosts returns the rule hosts of the given Ingresses that the converter converts
+// and that are not being deleted, so that listeners for many Ingresses can be generated
+// without looking up the Ingresses of each host.
+func (c *Converter) ServedHosts(ingresses []networkingv1.Ingress) ServedHosts {
+	served := make(ServedHosts)
+	for i := range ingresses {
+		ingress := &ingresses[i]
+		if ingress.DeletionTimestamp != nil || !c.processesIngress(ingress) {
+			continue
+		}
+		for _, host := range ruleHosts(ingress) {
+			served[host] = struct{}{}
+		}
+	}
+	return served
+}
+
+// wwwRedirectHosts returns the rule hosts of an Ingress with from-to-www-redirect, mapped
+// from their counterparts. Like ingress-nginx, counterparts that are rule hosts of this
+// or another Ingress the controller converts are served normally instead. Those are
+// taken from served when given, and looked up otherwise.
+func (c *Converter) wwwRedirectHosts(
+	ctx context.Context,
+	ingress *networkingv1.Ingress,
+	annots annotations.AnnotationSet,
+	served ServedHosts,
+) (map[string]string, []string) {
+	if !annots.HasFromToWWWRedirect() {
+		return nil, nil
+	}
+	hosts := ruleHosts(ingress)
+	counterparts := make(map[string]string)
+	for _, host := range hosts {
+		counterpart, ok := wwwCounterpart(host)
+		if !ok || slices.ContainsFunc(hosts, func(h string) bool { return strings.EqualFold(h, counterpart) }) {
+			continue
+		}
+		counterparts[counterpart] = host
+	}
+
+	var warnings []string
+	if served == nil {
+		siblingsByHost, lookupWarnings := c.siblingIngresses(ctx, ingress, slices.Sorted(maps.Keys(counterparts)))
+		served = make(ServedHosts, len(siblingsByHost))
+		for host, siblings := range siblingsByHost {
+			if len(siblings) > 0 {
+				served[host] = struct{}{}
+			}
+		}
+		warnings = lookupWarnings
+	}
+
+	redirects := make(map[string]string, len(counterparts))
+	for counterpart, host := range counterparts {
+		if _, ok := served[counterpart]; !ok {
+			redirects[counterpart] = host
+		}
+	}
+	return redirects, warnings
+}
+
+// createWWWRedirectRoute creates a redirect-only HTTPRoute that sends every request for
+// the www counterpart of a host to the host, keeping the scheme and path. It serves
+// HTTPS as well when TLS covers the counterpart.
+func (c *Converter) createWWWRedirectRoute(ingress *networkingv1.Ingress, host, counterpart string) *gatewayv1.HTTPRoute {
+	return &gatewayv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      c.generateRouteName(ingress, host) + "-www-redirect",
+			Namespace: ingress.Namespace,
+			Labels:    copyLabels(ingress.Labels),
+			Annotations: map[string]string{
+				"ingress-gateway-api.io/source": fmt.Sprintf("%s/%s", ingress.Namespace, ingress.Name),
+			},
+		},
+		Spec: gatewayv1.HTTPRouteSpec{
+			Hostnames: []gatewayv1.Hostname{gatewayv1.Hostname(counterpart)},
+			Rules: []gatewayv1.HTTPRouteRule{
+				{
+					Filters: []gatewayv1.HTTPRouteFilter{
+						{
+							Type: gatewayv1.HTTPRouteFilterRequestRedirect,
+							RequestRedirect: &gatewayv1.HTTPRequestRedirectFilter{
+								Hostname:   ptr(gatewayv1.PreciseHostname(host)),
+								StatusCode: ptr(sslRedirectStatusCode),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,386 @@
+package converter
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/werdnum/ingress-gateway-api/internal/annotations"
+	"github.com/werdnum/ingress-gateway-api/internal/config"
+)
+
+func TestConvertIngressFull_SSLRedirect(t *testing.T) {
+	gatewayListeners := []gatewayv1.Listener{
+		{
+			Name:          "http",
+			Port:          80,
+			Protocol:      gatewayv1.HTTPProtocolType,
+			AllowedRoutes: allNamespaces(),
+		},
+		{
+			Name:          "https",
+			Port:          443,
+			Protocol:      gatewayv1.HTTPSProtocolType,
+			AllowedRoutes: allNamespaces(),
+		},
+	}
+	exampleTLS := []networkingv1.IngressTLS{{Hosts: []string{"example.com"}, SecretName: "example-tls"}}
+
+	tests := []struct {
+		name         string
+		annotations  map[string]string
+		tls          []networkingv1.IngressTLS
+		wantRedirect bool
+		wantSections []gatewayv1.SectionName // sections of the main route
+	}{
+		{
+			name:         "tls redirects by default",
+			tls:          exampleTLS,
+			wantRedirect: true,
+			wantSections: []gatewayv1.SectionName{"https-example-com"},
+		},
+		{
+			name:         "ssl-redirect false serves both protocols",
+			annotations:  map[string]string{annotations.SSLRedirect: "false"},
+			tls:          exampleTLS,
+			wantSections: []gatewayv1.SectionName{"https-example-com", "http"},
+		},
+		{
+			name:         "no tls is served over http and the default https listener",
+			wantSections: []gatewayv1.SectionName{"https", "http"},
+		},
+		{
+			name:         "force-ssl-redirect without tls",
+			annotations:  map[string]string{annotations.ForceSSLRedirect: "true"},
+			wantRedirect: true,
+			wantSections: []gatewayv1.SectionName{"https"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewWithResolvers(&config.Config{
+				GatewayName:      "eg-gateway",
+				GatewayNamespace: "envoy-gateway",
+			}, Resolvers{Listeners: &staticListenerResolver{listeners: gatewayListeners}})
+
+			ingress := tlsTestIngress([]string{"example.com"}, tt.tls)
+			ingress.Annotations = tt.annotations
+			result := c.ConvertIngressFull(context.Background(), ingress)
+
+			routes := make(map[string]*gatewayv1.HTTPRoute)
+			for _, route := range result.HTTPRoutes {
+				routes[route.Name] = route
+			}
+
+			main := routes["test-ingress-example-com"]
+			if main == nil {
+				t.Fatalf("main route not generated, got %v", routes)
+			}
+			if len(main.Spec.ParentRefs) != len(tt.wantSections) {
+				t.Fatalf("expected %d parent refs, got %d", len(tt.wantSections), len(main.Spec.ParentRefs))
+			}
+			for i, parentRef := range main.Spec.ParentRefs {
+				if parentRef.SectionName == nil || *parentRef.SectionName != tt.wantSections[i] {
+					t.Errorf("expected section %s, got %v", tt.wantSections[i], parentRef.SectionName)
+				}
+			}
+			if len(main.Spec.Rules) != 1 || len(main.Spec.Rules[0].BackendRefs) != 1 {
+				t.Errorf("expected main route to keep its backend")
+			}
+
+			redirect, ok := routes["test-ingress-example-com-ssl-redirect"]
+			if ok != tt.wantRedirect {
+				t.Fatalf("expected redirect route: %v, got %v", tt.wantRedirect, ok)
+			}
+			if !ok {
+				return
+			}
+
+			if len(redirect.Spec.ParentRefs) != 1 || *redirect.Spec.ParentRefs[0].SectionName != "http" {
+				t.Errorf("expected redirect route on the http listener, got %+v", redirect.Spec.ParentRefs)
+			}
+			if len(redirect.Spec.Rules) != 1 {
+				t.Fatalf("expected 1 redirect rule, got %d", len(redirect.Spec.Rules))
+			}
+			rule := redirect.Spec.Rules[0]
+			if len(rule.BackendRefs) != 0 {
+				t.Errorf("expected no backends on redirect rule")
+			}
+			if len(rule.Matches) != 1 || *rule.Matches[0].Path.Value != "/" {
+				t.Errorf("expected redirect rule to keep the path match, got %+v", rule.Matches)
+			}
+			if len(rule.Filters) != 1 || rule.Filters[0].RequestRedirect == nil {
+				t.Fatalf("expected a RequestRedirect filter, got %+v", rule.Filters)
+			}
+			filter := rule.Filters[0].RequestRedirect
+			if filter.Scheme == nil || *filter.Scheme != "https" {
+				t.Errorf("expected scheme https, got %v", filter.Scheme)
+			}
+			if filter.StatusCode == nil || *filter.StatusCode != 308 {
+				t.Errorf("expected status code 308, got %v", filter.StatusCode)
+			}
+		})
+	}
+}
+
+func TestConvertIngressFull_SSLRedirectWithoutListeners(t *testing.T) {
+	c := New(&config.Config{
+		GatewayName:      "eg-gateway",
+		GatewayNamespace: "envoy-gateway",
+	})
+
+	result := c.ConvertIngressFull(context.Background(), tlsTestIngress([]string{"example.com"}, []networkingv1.IngressTLS{
+		{Hosts: []string{"example.com"}, SecretName: "example-tls"},
+	}))
+
+	wantPorts := map[string]gatewayv1.PortNumber{
+		"test-ingress-example-com":              443,
+		"test-ingress-example-com-ssl-redirect": 80,
+	}
+	if len(result.HTTPRoutes) != len(wantPorts) {
+		t.Fatalf("expected %d routes, got %d", len(wantPorts), len(result.HTTPRoutes))
+	}
+	for _, route := range result.HTTPRoutes {
+		parentRef := route.Spec.ParentRefs[0]
+		if parentRef.Port == nil || *parentRef.Port != wantPorts[route.Name] {
+			t.Errorf("expected %s to attach to port %d, got %v", route.Name, wantPorts[route.Name], parentRef.Port)
+		}
+	}
+}
+
+func TestConvertIngressFull_AnnotationRedirect(t *testing.T) {
+	tests := []struct {
+		name         string
+		annotations  map[string]string
+		wantRedirect *gatewayv1.HTTPRequestRedirectFilter
+		wantWarnings int
+	}{
+		{
+			name:        "permanent-redirect",
+			annotations: map[string]string{annotations.PermanentRedirect: "https://www.example.org/landing"},
+			wantRedirect: &gatewayv1.HTTPRequestRedirectFilter{
+				Scheme:   ptr("https"),
+				Hostname: ptr(gatewayv1.PreciseHostname("www.example.org")),
+				Path: &gatewayv1.HTTPPathModifier{
+					Type:            gatewayv1.FullPathHTTPPathModifier,
+					ReplaceFullPath: ptr("/landing"),
+				},
+				StatusCode: ptr(301),
+			},
+		},
+		{
+			name: "permanent-redirect-code and port",
+			annotations: map[string]string{
+				annotations.PermanentRedirect:     "http://example.org:8080",
+				annotations.PermanentRedirectCode: "308",
+			},
+			wantRedirect: &gatewayv1.HTTPRequestRedirectFilter{
+				Scheme:   ptr("http"),
+				Hostname: ptr(gatewayv1.PreciseHostname("example.org")),
+				Port:     ptr(gatewayv1.PortNumber(8080)),
+				Path: &gatewayv1.HTTPPathModifier{
+					Type:            gatewayv1.FullPathHTTPPathModifier,
+					ReplaceFullPath: ptr("/"),
+				},
+				StatusCode: ptr(308),
+			},
+		},
+		{
+			name: "unsupported permanent-redirect-code",
+			annotations: map[string]string{
+				annotations.PermanentRedirect:     "https://example.org/",
+				annotations.PermanentRedirectCode: "305",
+			},
+			wantRedirect: &gatewayv1.HTTPRequestRedirectFilter{
+				Scheme:   ptr("https"),
+				Hostname: ptr(gatewayv1.PreciseHostname("example.org")),
+				Path: &gatewayv1.HTTPPathModifier{
+					Type:            gatewayv1.FullPathHTTPPathModifier,
+					ReplaceFullPath: ptr("/"),
+				},
+				StatusCode: ptr(301),
+			},
+			wantWarnings: 1,
+		},
+		{
+			name: "temporal-redirect takes precedence and keeps $request_uri",
+			annotations: map[string]string{
+				annotations.TemporalRedirect:  "https://example.org$request_uri",
+				annotations.PermanentRedirect: "https://example.net",
+			},
+			wantRedirect: &gatewayv1.HTTPRequestRedirectFilter{
+				Scheme:     ptr("https"),
+				Hostname:   ptr(gatewayv1.PreciseHostname("example.org")),
+				StatusCode: ptr(302),
+			},
+		},
+		{
+			name:         "invalid URL",
+			annotations:  map[string]string{annotations.PermanentRedirect: "example.org/landing"},
+			wantWarnings: 1,
+		},
+		{
+			name:         "nginx variables",
+			annotations:  map[string]string{annotations.PermanentRedirect: "https://$host.example.org"},
+			wantWarnings: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(&config.Config{
+				GatewayName:      "eg-gateway",
+				GatewayNamespace: "envoy-gateway",
+			})
+			ingress := tlsTestIngress([]string{"example.com"}, nil)
+			ingress.Annotations = tt.annotations
+
+			result := c.ConvertIngressFull(context.Background(), ingress)
+
+			if len(result.Warnings) != tt.wantWarnings {
+				t.Errorf("expected %d warnings, got %v", tt.wantWarnings, result.Warnings)
+			}
+			if len(result.HTTPRoutes) != 1 {
+				t.Fatalf("expected 1 HTTPRoute, got %d", len(result.HTTPRoutes))
+			}
+			rule := result.HTTPRoutes[0].Spec.Rules[0]
+
+			if tt.wantRedirect == nil {
+				if len(rule.Filters) != 0 || len(rule.BackendRefs) != 1 {
+					t.Errorf("expected the rule to route to its backend, got filters %+v", rule.Filters)
+				}
+				return
+			}
+			if len(rule.BackendRefs) != 0 {
+				t.Errorf("expected no backendRefs on a redirect rule, got %d", len(rule.BackendRefs))
+			}
+			if len(rule.Filters) != 1 || rule.Filters[0].Type != gatewayv1.HTTPRouteFilterRequestRedirect {
+				t.Fatalf("expected a RequestRedirect filter, got %+v", rule.Filters)
+			}
+			if got := rule.Filters[0].RequestRedirect; !reflect.DeepEqual(got, tt.wantRedirect) {
+				t.Errorf("expected redirect %+v, got %+v", tt.wantRedirect, got)
+			}
+		})
+	}
+}
+
+func TestConvertIngressFull_FromToWWWRedirect(t *testing.T) {
+	gatewayListeners := []gatewayv1.Listener{
+		{
+			Name:          "http",
+			Port:          80,
+			Protocol:      gatewayv1.HTTPProtocolType,
+			AllowedRoutes: allNamespaces(),
+		},
+	}
+
+	tests := []struct {
+		name         string
+		hosts        []string
+		tls          []networkingv1.IngressTLS
+		siblingHosts []string          // rule hosts of another Ingress
+		wantRedirect map[string]string // counterpart -> host
+		wantSections []gatewayv1.SectionName
+	}{
+		{
+			name:         "apex redirects www",
+			hosts:        []string{"example.com"},
+			wantRedirect: map[string]string{"www.example.com": "example.com"},
+			wantSections: []gatewayv1.SectionName{"http"},
+		},
+		{
+			name:         "www redirects apex",
+			hosts:        []string{"www.example.com"},
+			wantRedirect: map[string]string{"example.com": "www.example.com"},
+			wantSections: []gatewayv1.SectionName{"http"},
+		},
+		{
+			name:  "TLS covering the counterpart adds HTTPS",
+			hosts: []string{"example.com"},
+			tls: []networkingv1.IngressTLS{
+				{Hosts: []string{"example.com", "www.example.com"}, SecretName: "example-tls"},
+			},
+			wantRedirect: map[string]string{"www.example.com": "example.com"},
+			wantSections: []gatewayv1.SectionName{"https-www-example-com", "http"},
+		},
+		{
+			name:  "counterpart that is a rule host",
+			hosts: []string{"example.com", "www.example.com"},
+		},
+		{
+			name:         "counterpart that is a rule host of another Ingress",
+			hosts:        []string{"example.com"},
+			siblingHosts: []string{"www.example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var siblings []networkingv1.Ingress
+			if len(tt.siblingHosts) > 0 {
+				sibling := tlsTestIngress(tt.siblingHosts, nil)
+				sibling.Name = "www"
+				siblings = append(siblings, *sibling)
+			}
+			c := NewWithResolvers(&config.Config{
+				GatewayName:      "eg-gateway",
+				GatewayNamespace: "envoy-gateway",
+			}, Resolvers{
+				Listeners: &staticListenerResolver{listeners: gatewayListeners},
+				Ingresses: &staticIngressResolver{ingresses: siblings},
+			})
+			ingress := tlsTestIngress(tt.hosts, tt.tls)
+			ingress.Annotations = map[string]string{
+				annotations.FromToWWWRedirect: "true",
+				annotations.SSLRedirect:       "false",
+			}
+
+			result := c.ConvertIngressFull(context.Background(), ingress)
+
+			if len(result.Warnings) != 0 {
+				t.Errorf("unexpected warnings: %v", result.Warnings)
+			}
+			redirects := make(map[string]*gatewayv1.HTTPRoute)
+			for _, route := range result.HTTPRoutes {
+				if len(route.Spec.Rules) == 1 && len(route.Spec.Rules[0].BackendRefs) == 0 {
+					redirects[string(route.Spec.Hostnames[0])] = route
+				}
+			}
+			if len(redirects) != len(tt.wantRedirect) {
+				t.Fatalf("expected %d redirect routes, got %d", len(tt.wantRedirect), len(redirects))
+			}
+
+			for counterpart, host := range tt.wantRedirect {
+				route, ok := redirects[counterpart]
+				if !ok {
+					t.Fatalf("expected a redirect route for %s", counterpart)
+				}
+				if want := "test-ingress-" + sanitizeHost(host) + "-www-redirect"; route.Name != want {
+					t.Errorf("expected route name %s, got %s", want, route.Name)
+				}
+				if route.Annotations["ingress-gateway-api.io/source"] != "default/test-ingress" {
+					t.Errorf("expected source annotation, got %v", route.Annotations)
+				}
+				redirect := route.Spec.Rules[0].Filters[0].RequestRedirect
+				if redirect == nil || redirect.Hostname == nil || string(*redirect.Hostname) != host ||
+					redirect.Scheme != nil || redirect.Path != nil {
+					t.Errorf("expected a redirect to %s keeping scheme and path, got %+v", host, redirect)
+				}
+
+				var sections []gatewayv1.SectionName
+				for _, ref := range route.Spec.ParentRefs {
+					if ref.SectionName != nil {
+						sections = append(sections, *ref.SectionName)
+					}
+				}
+				if !reflect.DeepEqual(sections, tt.wantSections) {
+					t.Errorf("expected sections %v, got %v", tt.wantSections, sections)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package converter
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+
+	"github.com/werdnum/ingress-gateway-api/internal/annotations"
+)
+
+// IngressClassName returns the class of an Ingress from spec.ingressClassName or the
+// deprecated kubernetes.io/ingress.class annotation.
+func IngressClassName(ingress *networkingv1.Ingress) string {
+	if ingress.Spec.IngressClassName != nil {
+		return *ingress.Spec.IngressClassName
+	}
+	return ingress.Annotations["kubernetes.io/ingress.class"]
+}
+
+// processesIngress reports whether the controller converts an Ingress of the given
+// class, and so whether the Ingress shares hosts on the Gateway.
+func (c *Converter) processesIngress(ingress *networkingv1.Ingress) bool {
+	return c.cfg.IngressClass == "" || IngressClassName(ingress) == c.cfg.IngressClass
+}
+
+// regexPath returns the Gateway API regular expression for an Ingress path in regex
+// mode. ingress-nginx uses a case-insensitive location anchored at the start of the
+// path only, ~* "^<path>"; Envoy matches the whole path, so the rest is allowed. The
+// suffix is always added, even after $, so that the length of every regex grows by the
+// same amount, which keeps the precedence computed by regexPrecedencePadding.
+func regexPath(path string) string {
+	return "(?i)" + path + ".*"
+}
+
+// siblingIngresses returns the other Ingresses the controller converts for each host of
+// the Ingress. Hosts whose Ingresses cannot be looked up have none.
+func (c *Converter) siblingIngresses(
+	ctx context.Context,
+	ingress *networkingv1.Ingress,
+	hosts []string,
+) (map[string][]networkingv1.Ingress, []string) {
+	var warnings []string
+	siblingsByHost := make(map[string][]networkingv1.Ingress, len(hosts))
+	for _, host := range hosts {
+		ingresses, err := c.ingresses.ResolveHostIngresses(ctx, host)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf(
+				"could not look up other Ingresses for host %q, only this Ingress is considered: %v", host, err))
+			continue
+		}
+		for _, sibling := range ingresses {
+			if sibling.Namespace == ingress.Namespace && sibling.Name == ingress.Name {
+				continue
+			}
+			if sibling.DeletionTimestamp != nil || !c.processesIngress(&sibling) {
+				continue
+			}
+			siblingsByHost[host] = append(siblingsByHost[host], sibling)
+		}
+	}
+	return siblingsByHost, warnings
+}
+
+// regexHosts returns the hosts of the Ingress whose paths are regular expressions. Like
+// ingress-nginx, use-regex or rewrite-target on any Ingress for a host turns every path
+// of that host into a regex, including those of other Ingresses the controller converts.
+// Warnings report which Ingresses caused or are affected by the change.
+func regexHosts(
+	annots annotations.AnnotationSet,
+	hosts []string,
+	siblingsByHost map[string][]networkingv1.Ingress,
+) (map[string]bool, []string) {
+	var warnings []string
+	regex := make(map[string]bool, len(hosts))
+	ownRegex := annots.UsesRegex()
+
+	for _, host := range hosts {
+		regex[host] = ownRegex
+
+		var activators, affected []string
+		for _, sibling := range siblingsByHost[host] {
+			ref := fmt.Sprintf("%s/%s", sibling.Namespace, sibling.Name)
+			if annotations.NewAnnotationSet(sibling.Annotations).UsesRegex() {
+				activators = append(activators, ref)
+			} else {
+				affected = append(affected, ref)
+			}
+		}
+		slices.Sort(activators)
+		slices.Sort(affected)
+
+		switch {
+		case len(activators) > 0 && !ownRegex:
+			regex[host] = true
+			warnings = append(warnings, fmt.Sprintf(
+				"paths for host %s are case-insensitive regular expressions because %s set use-regex or rewrite-target",
+				displayHost(host), strings.Join(activators, ", ")))
+		case ownRegex && len(affected) > 0:
+			warnings = append(warnings, fmt.Sprintf(
+				"use-regex or rewrite-target also makes the paths of %s for host %s case-insensitive regular expressions",
+				strings.Join(affected, ", "), displayHost(host)))
+		}
+	}
+
+	return regex, warnings
+}
+
+// displayHost returns the host for messages, with * for rules without a host.
+func displayHost(host string) string {
+	if host == "" {
+		return "*"
+	}
+	return host
+}
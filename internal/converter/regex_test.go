@@ -0,0 +1,188 @@
+package converter
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/werdnum/ingress-gateway-api/internal/annotations"
+	"github.com/werdnum/ingress-gateway-api/internal/config"
+)
+
+// staticIngressResolver returns a fixed set of Ingresses.
+type staticIngressResolver struct {
+	ingresses []networkingv1.Ingress
+}
+
+func (r *staticIngressResolver) ResolveHostIngresses(ctx context.Context, host string) ([]networkingv1.Ingress, error) {
+	var result []networkingv1.Ingress
+	for _, ingress := range r.ingresses {
+		for _, rule := range ingress.Spec.Rules {
+			if rule.Host == host {
+				result = append(result, ingress)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+func TestRegexPath(t *testing.T) {
+	tests := []struct {
+		path      string
+		want      string
+		matches   []string
+		unmatched []string
+	}{
+		{
+			path:      "/api",
+			want:      "(?i)/api.*",
+			matches:   []string{"/api", "/API/users", "/apiv2"},
+			unmatched: []string{"/", "/v1/api"},
+		},
+		{
+			path:      "/api/v[0-9]+/.*",
+			want:      "(?i)/api/v[0-9]+/.*.*",
+			matches:   []string{"/api/v1/", "/Api/V2/users"},
+			unmatched: []string{"/api/latest/"},
+		},
+		{
+			path:      "/exact$",
+			want:      "(?i)/exact$.*",
+			matches:   []string{"/exact", "/EXACT"},
+			unmatched: []string{"/exact/more"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got := regexPath(tt.path)
+			if got != tt.want {
+				t.Fatalf("regexPath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+			// Envoy matches the whole path
+			re := regexp.MustCompile("^(?:" + got + ")$")
+			for _, path := range tt.matches {
+				if !re.MatchString(path) {
+					t.Errorf("expected %s to match %s", got, path)
+				}
+			}
+			for _, path := range tt.unmatched {
+				if re.MatchString(path) {
+					t.Errorf("expected %s not to match %s", got, path)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertIngressFull_HostWideRegex(t *testing.T) {
+	sibling := func(name, class string, annots map[string]string) networkingv1.Ingress {
+		ingress := tlsTestIngress([]string{"example.com"}, nil)
+		ingress.Name = name
+		ingress.Namespace = "other"
+		ingress.Annotations = annots
+		if class != "" {
+			ingress.Spec.IngressClassName = ptr(class)
+		}
+		return *ingress
+	}
+	useRegex := map[string]string{annotations.UseRegex: "true"}
+
+	tests := []struct {
+		name         string
+		ingressClass string
+		annots       map[string]string
+		siblings     []networkingv1.Ingress
+		wantRegex    bool
+		wantWarning  string
+	}{
+		{
+			name:      "plain paths without regex Ingresses",
+			siblings:  []networkingv1.Ingress{sibling("plain", "", nil)},
+			wantRegex: false,
+		},
+		{
+			name:      "use-regex makes the own paths regexes",
+			annots:    useRegex,
+			wantRegex: true,
+		},
+		{
+			name:        "sibling use-regex makes the paths regexes",
+			siblings:    []networkingv1.Ingress{sibling("regex", "", useRegex)},
+			wantRegex:   true,
+			wantWarning: "because other/regex set use-regex or rewrite-target",
+		},
+		{
+			name: "sibling rewrite-target makes the paths regexes",
+			siblings: []networkingv1.Ingress{
+				sibling("rewrite", "", map[string]string{annotations.RewriteTarget: "/"}),
+			},
+			wantRegex:   true,
+			wantWarning: "because other/rewrite set use-regex or rewrite-target",
+		},
+		{
+			name:        "affected siblings are reported",
+			annots:      useRegex,
+			siblings:    []networkingv1.Ingress{sibling("plain", "", nil)},
+			wantRegex:   true,
+			wantWarning: "makes the paths of other/plain for host example.com case-insensitive",
+		},
+		{
+			name:         "siblings of other classes are ignored",
+			ingressClass: "nginx",
+			siblings:     []networkingv1.Ingress{sibling("regex", "internal", useRegex)},
+			wantRegex:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewWithResolvers(&config.Config{
+				GatewayName:      "eg-gateway",
+				GatewayNamespace: "envoy-gateway",
+				IngressClass:     tt.ingressClass,
+			}, Resolvers{Ingresses: &staticIngressResolver{ingresses: tt.siblings}})
+			ingress := tlsTestIngress([]string{"example.com"}, nil)
+			ingress.Annotations = tt.annots
+			if tt.ingressClass != "" {
+				ingress.Spec.IngressClassName = ptr(tt.ingressClass)
+			}
+			ingress.Spec.Rules[0].HTTP.Paths = append(ingress.Spec.Rules[0].HTTP.Paths, networkingv1.HTTPIngressPath{
+				Path:     "/Health",
+				PathType: ptr(networkingv1.PathTypeExact),
+				Backend:  ingress.Spec.Rules[0].HTTP.Paths[0].Backend,
+			})
+
+			result := c.ConvertIngressFull(context.Background(), ingress)
+
+			rules := result.HTTPRoutes[0].Spec.Rules
+			wantPrefix := &gatewayv1.HTTPPathMatch{Type: ptr(gatewayv1.PathMatchPathPrefix), Value: ptr("/")}
+			wantExact := &gatewayv1.HTTPPathMatch{Type: ptr(gatewayv1.PathMatchExact), Value: ptr("/Health")}
+			if tt.wantRegex {
+				wantPrefix = &gatewayv1.HTTPPathMatch{Type: ptr(gatewayv1.PathMatchRegularExpression), Value: ptr("(?i)/.*")}
+				wantExact = &gatewayv1.HTTPPathMatch{Type: ptr(gatewayv1.PathMatchRegularExpression), Value: ptr("(?i)/Health.*")}
+			}
+			for i, want := range []*gatewayv1.HTTPPathMatch{wantPrefix, wantExact} {
+				got := rules[i].Matches[0].Path
+				if *got.Type != *want.Type || *got.Value != *want.Value {
+					t.Errorf("rule %d: expected %s %s, got %s %s", i, *want.Type, *want.Value, *got.Type, *got.Value)
+				}
+			}
+
+			found := tt.wantWarning == ""
+			for _, warning := range result.Warnings {
+				if tt.wantWarning != "" && strings.Contains(warning, tt.wantWarning) {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("expected a warning containing %q, got %v", tt.wantWarning, result.Warnings)
+			}
+		})
+	}
+}
@@ -3,9 +3,13 @@ package converter
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
 
 // ServicePortResolver resolves named service ports to numeric ports.
@@ -66,3 +70,120 @@ func (r *NoopServicePortResolver) ResolvePort(ctx context.Context, namespace, se
 	}
 	return 0, fmt.Errorf("named port %q cannot be resolved without service lookup", portName)
 }
+
+// ListenerResolver looks up the listeners of a Gateway.
+type ListenerResolver interface {
+	// ResolveListeners returns the listeners of the named Gateway.
+	// A Gateway that does not exist has no listeners.
+	ResolveListeners(ctx context.Context, namespace, name string) ([]gatewayv1.Listener, error)
+}
+
+// ClientListenerResolver implements ListenerResolver using a Kubernetes client.
+type ClientListenerResolver struct {
+	client client.Client
+}
+
+// NewListenerResolver creates a new ListenerResolver.
+func NewListenerResolver(c client.Client) ListenerResolver {
+	return &ClientListenerResolver{client: c}
+}
+
+// ResolveListeners returns the listeners of the named Gateway.
+func (r *ClientListenerResolver) ResolveListeners(ctx context.Context, namespace, name string) ([]gatewayv1.Listener, error) {
+	gateway := &gatewayv1.Gateway{}
+	if err := r.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, gateway); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get gateway %s/%s: %w", namespace, name, err)
+	}
+	return gateway.Spec.Listeners, nil
+}
+
+// NoopListenerResolver is a resolver that knows no listeners.
+// Routes then attach to the whole Gateway.
+type NoopListenerResolver struct{}
+
+// ResolveListeners returns no listeners.
+func (r *NoopListenerResolver) ResolveListeners(ctx context.Context, namespace, name string) ([]gatewayv1.Listener, error) {
+	return nil, nil
+}
+
+// ConfigMapResolver looks up ConfigMaps referenced by Ingress annotations.
+type ConfigMapResolver interface {
+	// ResolveConfigMap returns the data of the named ConfigMap.
+	// A ConfigMap that does not exist has no data and found is false.
+	ResolveConfigMap(ctx context.Context, namespace, name string) (data map[string]string, found bool, err error)
+}
+
+// ClientConfigMapResolver implements ConfigMapResolver using a Kubernetes client.
+type ClientConfigMapResolver struct {
+	client client.Client
+}
+
+// NewConfigMapResolver creates a new ConfigMapResolver.
+func NewConfigMapResolver(c client.Client) ConfigMapResolver {
+	return &ClientConfigMapResolver{client: c}
+}
+
+// ResolveConfigMap returns the data of the named ConfigMap.
+func (r *ClientConfigMapResolver) ResolveConfigMap(ctx context.Context, namespace, name string) (map[string]string, bool, error) {
+	configMap := &corev1.ConfigMap{}
+	if err := r.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, configMap); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to get configmap %s/%s: %w", namespace, name, err)
+	}
+	return configMap.Data, true, nil
+}
+
+// NoopConfigMapResolver is a resolver that cannot look up ConfigMaps.
+// Used for testing or when no client is available.
+type NoopConfigMapResolver struct{}
+
+// ResolveConfigMap returns an error, as no ConfigMap can be looked up.
+func (r *NoopConfigMapResolver) ResolveConfigMap(ctx context.Context, namespace, name string) (map[string]string, bool, error) {
+	return nil, false, fmt.Errorf("configmap %s/%s cannot be resolved without a client", namespace, name)
+}
+
+// IngressResolver looks up the Ingresses that share a host.
+type IngressResolver interface {
+	// ResolveHostIngresses returns the Ingresses in all namespaces with a rule for the
+	// host. The empty host matches rules without a host.
+	ResolveHostIngresses(ctx context.Context, host string) ([]networkingv1.Ingress, error)
+}
+
+// ClientIngressResolver implements IngressResolver using a Kubernetes client.
+type ClientIngressResolver struct {
+	client client.Client
+}
+
+// NewIngressResolver creates a new IngressResolver.
+func NewIngressResolver(c client.Client) IngressResolver {
+	return &ClientIngressResolver{client: c}
+}
+
+// ResolveHostIngresses returns the Ingresses with a rule for the host.
+func (r *ClientIngressResolver) ResolveHostIngresses(ctx context.Context, host string) ([]networkingv1.Ingress, error) {
+	var ingresses networkingv1.IngressList
+	if err := r.client.List(ctx, &ingresses); err != nil {
+		return nil, fmt.Errorf("failed to list ingresses: %w", err)
+	}
+	var result []networkingv1.Ingress
+	for _, ingress := range ingresses.Items {
+		if slices.Contains(ruleHosts(&ingress), host) {
+			result = append(result, ingress)
+		}
+	}
+	return result, nil
+}
+
+// NoopIngressResolver is a resolver that knows no other Ingresses.
+// Each Ingress is then converted on its own.
+type NoopIngressResolver struct{}
+
+// ResolveHostIngresses returns no Ingresses.
+func (r *NoopIngressResolver) ResolveHostIngresses(ctx context.Context, host string) ([]networkingv1.Ingress, error) {
+	return nil, nil
+}
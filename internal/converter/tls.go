@@ -0,0 +1,237 @@
+package converter
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/werdnum/ingress-gateway-api/internal/annotations"
+)
+
+// HTTPSListenerPort is the port used for HTTPS listeners generated from Ingress TLS.
+const HTTPSListenerPort gatewayv1.PortNumber = 443
+
+// HTTPListenerPort is the port plain HTTP is expected on when the Gateway's listeners are unknown.
+const HTTPListenerPort gatewayv1.PortNumber = 80
+
+// namespaceNameLabel is the well-known label holding a Namespace's name.
+const namespaceNameLabel = "kubernetes.io/metadata.name"
+
+// GenerateListeners returns the HTTPS listeners needed on the shared Gateway to
+// terminate TLS for the Ingress, given the hosts served by all converted Ingresses.
+// Conversion warnings are discarded.
+func (c *Converter) GenerateListeners(ctx context.Context, ingress *networkingv1.Ingress, served ServedHosts) []gatewayv1.Listener {
+	listeners, _ := c.generateListeners(ctx, ingress, served)
+	return listeners
+}
+
+// generateListeners creates one HTTPS listener per TLS hostname that serves at least
+// one rule host of the Ingress, or a from-to-www-redirect counterpart of one. TLS hosts
+// without a matching rule are skipped, as ingress-nginx only configures certificates for
+// hosts it has a server for. ssl-passthrough Ingresses get TLS Passthrough listeners instead.
+// Without served hosts, the Ingresses serving www counterparts are looked up.
+func (c *Converter) generateListeners(
+	ctx context.Context,
+	ingress *networkingv1.Ingress,
+	served ServedHosts,
+) ([]gatewayv1.Listener, []string) {
+	annots := annotations.NewAnnotationSet(ingress.Annotations)
+	if annots.HasSSLPassthrough() {
+		return c.generatePassthroughListeners(ingress)
+	}
+	if len(ingress.Spec.TLS) == 0 {
+		return nil, nil
+	}
+
+	var warnings []string
+	listenersByHost := make(map[string]gatewayv1.Listener)
+	matchedTLSHosts := make(map[string]struct{})
+
+	hosts := ruleHosts(ingress)
+	redirects, _ := c.wwwRedirectHosts(ctx, ingress, annots, served)
+	for _, counterpart := range slices.Sorted(maps.Keys(redirects)) {
+		hosts = append(hosts, counterpart)
+	}
+
+	for _, host := range hosts {
+		if host == "" {
+			continue
+		}
+
+		tls, listenerHost, ok := findTLSForHost(ingress.Spec.TLS, host)
+		if !ok {
+			continue
+		}
+		matchedTLSHosts[listenerHost] = struct{}{}
+		if _, exists := listenersByHost[listenerHost]; exists {
+			continue
+		}
+
+		certRef, ok := c.certificateRef(ingress, tls)
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf(
+				"TLS for host %q has no secretName and no default certificate is configured; relying on existing Gateway HTTPS listeners",
+				host))
+			continue
+		}
+
+		listenersByHost[listenerHost] = gatewayv1.Listener{
+			Name:     gatewayv1.SectionName(ListenerName(gatewayv1.HTTPSProtocolType, listenerHost)),
+			Hostname: ptr(gatewayv1.Hostname(listenerHost)),
+			Port:     HTTPSListenerPort,
+			Protocol: gatewayv1.HTTPSProtocolType,
+			TLS: &gatewayv1.ListenerTLSConfig{
+				Mode:            ptr(gatewayv1.TLSModeTerminate),
+				CertificateRefs: []gatewayv1.SecretObjectReference{certRef},
+			},
+			AllowedRoutes: allowedRoutesFromNamespaces(ingress.Namespace),
+		}
+	}
+
+	// Report TLS hosts that no rule uses
+	for _, tls := range ingress.Spec.TLS {
+		for _, tlsHost := range tls.Hosts {
+			if _, ok := matchedTLSHosts[tlsHost]; ok {
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf("TLS host %q has no matching rule, no listener created", tlsHost))
+		}
+	}
+
+	listeners := make([]gatewayv1.Listener, 0, len(listenersByHost))
+	for _, listener := range listenersByHost {
+		listeners = append(listeners, listener)
+	}
+	sort.Slice(listeners, func(i, j int) bool {
+		return listeners[i].Name < listeners[j].Name
+	})
+
+	return listeners, warnings
+}
+
+// findTLSForHost finds the TLS entry that serves a rule host, mirroring ingress-nginx:
+// an exact TLS host match wins, then a wildcard TLS host covering the rule host, then
+// an entry that lists no hosts at all. It also returns the hostname the listener
+// should use.
+func findTLSForHost(tlsEntries []networkingv1.IngressTLS, host string) (networkingv1.IngressTLS, string, bool) {
+	for _, tls := range tlsEntries {
+		for _, tlsHost := range tls.Hosts {
+			if strings.EqualFold(tlsHost, host) {
+				return tls, tlsHost, true
+			}
+		}
+	}
+
+	for _, tls := range tlsEntries {
+		for _, tlsHost := range tls.Hosts {
+			if strings.HasPrefix(tlsHost, "*.") && HostnameMatches(tlsHost, host) {
+				return tls, tlsHost, true
+			}
+		}
+	}
+
+	for _, tls := range tlsEntries {
+		if len(tls.Hosts) == 0 {
+			return tls, host, true
+		}
+	}
+
+	return networkingv1.IngressTLS{}, "", false
+}
+
+// hostHasTLS reports whether the Ingress configures TLS for a rule host.
+func hostHasTLS(ingress *networkingv1.Ingress, host string) bool {
+	_, _, ok := findTLSForHost(ingress.Spec.TLS, host)
+	return ok
+}
+
+// certificateRef returns the Secret reference a listener should use for a TLS entry.
+// Entries without a secretName use the configured default certificate.
+func (c *Converter) certificateRef(ingress *networkingv1.Ingress, tls networkingv1.IngressTLS) (gatewayv1.SecretObjectReference, bool) {
+	namespace, name := ingress.Namespace, tls.SecretName
+	if name == "" {
+		if c.cfg.DefaultTLSSecret == "" {
+			return gatewayv1.SecretObjectReference{}, false
+		}
+		namespace, name = splitNamespacedName(c.cfg.DefaultTLSSecret, c.cfg.GatewayNamespace)
+	}
+
+	return gatewayv1.SecretObjectReference{
+		Group:     ptr(gatewayv1.Group("")),
+		Kind:      ptr(gatewayv1.Kind("Secret")),
+		Name:      gatewayv1.ObjectName(name),
+		Namespace: ptr(gatewayv1.Namespace(namespace)),
+	}, true
+}
+
+// allowedRoutesFromNamespaces restricts a listener to routes from the given namespaces,
+// so a certificate is only used by routes from the namespaces that provided it.
+func allowedRoutesFromNamespaces(namespaces ...string) *gatewayv1.AllowedRoutes {
+	return &gatewayv1.AllowedRoutes{
+		Namespaces: &gatewayv1.RouteNamespaces{
+			From: ptr(gatewayv1.NamespacesFromSelector),
+			Selector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{
+						Key:      namespaceNameLabel,
+						Operator: metav1.LabelSelectorOpIn,
+						Values:   namespaces,
+					},
+				},
+			},
+		},
+	}
+}
+
+// ListenerName generates the name of a generated listener for a protocol and hostname.
+func ListenerName(protocol gatewayv1.ProtocolType, host string) string {
+	return fmt.Sprintf("%s-%s", strings.ToLower(string(protocol)), sanitizeHost(host))
+}
+
+// HostnameMatches reports whether a listener hostname accepts a route hostname.
+// An empty listener hostname matches everything, and a wildcard matches one or more
+// leading labels, as defined by Gateway API.
+func HostnameMatches(listenerHost, host string) bool {
+	if listenerHost == "" {
+		return true
+	}
+	if strings.EqualFold(listenerHost, host) {
+		return true
+	}
+	if !strings.HasPrefix(listenerHost, "*.") {
+		return false
+	}
+	suffix := strings.ToLower(listenerHost[1:])
+	host = strings.ToLower(strings.TrimPrefix(host, "*"))
+	return strings.HasSuffix(host, suffix) && len(host) > len(suffix)
+}
+
+// ruleHosts returns the distinct rule hosts of the Ingress in declaration order.
+func ruleHosts(ingress *networkingv1.Ingress) []string {
+	seen := make(map[string]struct{})
+	var hosts []string
+	for _, rule := range ingress.Spec.Rules {
+		if _, ok := seen[rule.Host]; ok {
+			continue
+		}
+		seen[rule.Host] = struct{}{}
+		hosts = append(hosts, rule.Host)
+	}
+	return hosts
+}
+
+// splitNamespacedName splits a "namespace/name" reference, using defaultNamespace
+// when no namespace is given.
+func splitNamespacedName(ref, defaultNamespace string) (string, string) {
+	if ns, name, ok := strings.Cut(ref, "/"); ok {
+		return ns, name
+	}
+	return defaultNamespace, ref
+}
@@ -0,0 +1,283 @@
+package converter
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/werdnum/ingress-gateway-api/internal/annotations"
+	"github.com/werdnum/ingress-gateway-api/internal/config"
+)
+
+func tlsTestIngress(hosts []string, tls []networkingv1.IngressTLS) *networkingv1.Ingress {
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ingress",
+			Namespace: "default",
+		},
+		Spec: networkingv1.IngressSpec{
+			TLS: tls,
+		},
+	}
+	for _, host := range hosts {
+		ingress.Spec.Rules = append(ingress.Spec.Rules, networkingv1.IngressRule{
+			Host: host,
+			IngressRuleValue: networkingv1.IngressRuleValue{
+				HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{
+						{
+							Path:     "/",
+							PathType: ptr(networkingv1.PathTypePrefix),
+							Backend: networkingv1.IngressBackend{
+								Service: &networkingv1.IngressServiceBackend{
+									Name: "web",
+									Port: networkingv1.ServiceBackendPort{Number: 80},
+								},
+							},
+						},
+					},
+				},
+			},
+		})
+	}
+	return ingress
+}
+
+func TestGenerateListeners(t *testing.T) {
+	tests := []struct {
+		name             string
+		defaultTLSSecret string
+		hosts            []string
+		tls              []networkingv1.IngressTLS
+		wantListeners    map[string]string // listener hostname -> secret namespace/name
+		wantWarnings     int
+	}{
+		{
+			name:          "no tls",
+			hosts:         []string{"example.com"},
+			wantListeners: map[string]string{},
+		},
+		{
+			name:  "one secret for two hosts",
+			hosts: []string{"example.com", "api.example.com"},
+			tls: []networkingv1.IngressTLS{
+				{Hosts: []string{"example.com", "api.example.com"}, SecretName: "example-tls"},
+			},
+			wantListeners: map[string]string{
+				"example.com":     "default/example-tls",
+				"api.example.com": "default/example-tls",
+			},
+		},
+		{
+			name:  "wildcard tls host covers rule hosts",
+			hosts: []string{"a.example.com", "b.example.com"},
+			tls: []networkingv1.IngressTLS{
+				{Hosts: []string{"*.example.com"}, SecretName: "wildcard-tls"},
+			},
+			wantListeners: map[string]string{
+				"*.example.com": "default/wildcard-tls",
+			},
+		},
+		{
+			name:  "exact tls host wins over wildcard",
+			hosts: []string{"a.example.com"},
+			tls: []networkingv1.IngressTLS{
+				{Hosts: []string{"*.example.com"}, SecretName: "wildcard-tls"},
+				{Hosts: []string{"a.example.com"}, SecretName: "a-tls"},
+			},
+			wantListeners: map[string]string{
+				"a.example.com": "default/a-tls",
+			},
+			wantWarnings: 1,
+		},
+		{
+			name:  "tls host without matching rule",
+			hosts: []string{"example.com"},
+			tls: []networkingv1.IngressTLS{
+				{Hosts: []string{"example.com", "other.example.com"}, SecretName: "example-tls"},
+			},
+			wantListeners: map[string]string{
+				"example.com": "default/example-tls",
+			},
+			wantWarnings: 1,
+		},
+		{
+			name:  "tls entry without hosts applies to all rule hosts",
+			hosts: []string{"example.com"},
+			tls: []networkingv1.IngressTLS{
+				{SecretName: "example-tls"},
+			},
+			wantListeners: map[string]string{
+				"example.com": "default/example-tls",
+			},
+		},
+		{
+			name:             "missing secretName uses default certificate",
+			defaultTLSSecret: "envoy-gateway/default-cert",
+			hosts:            []string{"example.com"},
+			tls: []networkingv1.IngressTLS{
+				{Hosts: []string{"example.com"}},
+			},
+			wantListeners: map[string]string{
+				"example.com": "envoy-gateway/default-cert",
+			},
+		},
+		{
+			name:  "missing secretName without default certificate",
+			hosts: []string{"example.com"},
+			tls: []networkingv1.IngressTLS{
+				{Hosts: []string{"example.com"}},
+			},
+			wantListeners: map[string]string{},
+			wantWarnings:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(&config.Config{
+				GatewayName:      "eg-gateway",
+				GatewayNamespace: "envoy-gateway",
+				DefaultTLSSecret: tt.defaultTLSSecret,
+			})
+
+			listeners, warnings := c.generateListeners(context.Background(), tlsTestIngress(tt.hosts, tt.tls), nil)
+
+			if len(warnings) != tt.wantWarnings {
+				t.Errorf("expected %d warnings, got %d: %v", tt.wantWarnings, len(warnings), warnings)
+			}
+			if len(listeners) != len(tt.wantListeners) {
+				t.Fatalf("expected %d listeners, got %d", len(tt.wantListeners), len(listeners))
+			}
+
+			for _, listener := range listeners {
+				if listener.Hostname == nil {
+					t.Fatalf("listener %s has no hostname", listener.Name)
+				}
+				wantSecret, ok := tt.wantListeners[string(*listener.Hostname)]
+				if !ok {
+					t.Errorf("unexpected listener for hostname %s", *listener.Hostname)
+					continue
+				}
+				if listener.Protocol != gatewayv1.HTTPSProtocolType || listener.Port != HTTPSListenerPort {
+					t.Errorf("expected HTTPS listener on port 443, got %s/%d", listener.Protocol, listener.Port)
+				}
+				if listener.TLS == nil || len(listener.TLS.CertificateRefs) != 1 {
+					t.Fatalf("expected one certificate ref on listener %s", listener.Name)
+				}
+				ref := listener.TLS.CertificateRefs[0]
+				gotSecret := string(*ref.Namespace) + "/" + string(ref.Name)
+				if gotSecret != wantSecret {
+					t.Errorf("expected secret %s, got %s", wantSecret, gotSecret)
+				}
+				if !strings.HasPrefix(string(listener.Name), "https-") {
+					t.Errorf("expected listener name to start with https-, got %s", listener.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateListeners_ServedHosts(t *testing.T) {
+	class := "nginx"
+	c := New(&config.Config{
+		GatewayName:      "eg-gateway",
+		GatewayNamespace: "envoy-gateway",
+		IngressClass:     class,
+	})
+
+	ingress := tlsTestIngress([]string{"example.com"}, []networkingv1.IngressTLS{
+		{Hosts: []string{"example.com", "www.example.com"}, SecretName: "example-tls"},
+	})
+	ingress.Spec.IngressClassName = &class
+	ingress.Annotations = map[string]string{annotations.FromToWWWRedirect: "true"}
+
+	www := tlsTestIngress([]string{"www.example.com"}, nil)
+	www.Name = "www"
+	www.Spec.IngressClassName = &class
+	deleted := www.DeepCopy()
+	deleted.DeletionTimestamp = &metav1.Time{}
+	otherClass := www.DeepCopy()
+	otherClass.Spec.IngressClassName = ptr("other")
+
+	tests := []struct {
+		name          string
+		ingresses     []networkingv1.Ingress
+		wantListeners []gatewayv1.SectionName
+	}{
+		{
+			name:          "unserved counterpart gets a listener for its redirect",
+			ingresses:     []networkingv1.Ingress{*ingress},
+			wantListeners: []gatewayv1.SectionName{"https-example-com", "https-www-example-com"},
+		},
+		{
+			name:          "counterpart served by another Ingress is left to it",
+			ingresses:     []networkingv1.Ingress{*ingress, *www},
+			wantListeners: []gatewayv1.SectionName{"https-example-com"},
+		},
+		{
+			name:          "deleted Ingresses and other classes do not serve hosts",
+			ingresses:     []networkingv1.Ingress{*ingress, *deleted, *otherClass},
+			wantListeners: []gatewayv1.SectionName{"https-example-com", "https-www-example-com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var names []gatewayv1.SectionName
+			for _, listener := range c.GenerateListeners(context.Background(), ingress, c.ServedHosts(tt.ingresses)) {
+				names = append(names, listener.Name)
+			}
+			if !slices.Equal(names, tt.wantListeners) {
+				t.Errorf("expected listeners %v, got %v", tt.wantListeners, names)
+			}
+		})
+	}
+}
+
+func TestHostnameMatches(t *testing.T) {
+	tests := []struct {
+		listenerHost string
+		host         string
+		want         bool
+	}{
+		{listenerHost: "", host: "example.com", want: true},
+		{listenerHost: "example.com", host: "example.com", want: true},
+		{listenerHost: "example.com", host: "EXAMPLE.com", want: true},
+		{listenerHost: "example.com", host: "api.example.com", want: false},
+		{listenerHost: "*.example.com", host: "api.example.com", want: true},
+		{listenerHost: "*.example.com", host: "a.b.example.com", want: true},
+		{listenerHost: "*.example.com", host: "example.com", want: false},
+		{listenerHost: "*.example.com", host: "*.example.com", want: true},
+		{listenerHost: "*.example.com", host: "api.example.org", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.listenerHost+"_"+tt.host, func(t *testing.T) {
+			if got := HostnameMatches(tt.listenerHost, tt.host); got != tt.want {
+				t.Errorf("HostnameMatches(%q, %q) = %v, want %v", tt.listenerHost, tt.host, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListenerName(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{host: "example.com", want: "https-example-com"},
+		{host: "*.example.com", want: "https-wildcard-example-com"},
+	}
+
+	for _, tt := range tests {
+		if got := ListenerName(gatewayv1.HTTPSProtocolType, tt.host); got != tt.want {
+			t.Errorf("ListenerName(%q) = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+}
@@ -2,7 +2,9 @@ package converter
 
 import (
 	egv1alpha1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 )
 
 // ConversionResult contains all resources generated from an Ingress.
@@ -10,6 +12,10 @@ type ConversionResult struct {
 	// HTTPRoutes are the generated HTTPRoute resources.
 	HTTPRoutes []*gatewayv1.HTTPRoute
 
+	// TLSRoutes are the generated TLSRoute resources for ssl-passthrough hosts.
+	// One per host is created instead of an HTTPRoute.
+	TLSRoutes []*gatewayv1alpha2.TLSRoute
+
 	// BackendTrafficPolicy is the generated BackendTrafficPolicy (if any).
 	// One per HTTPRoute is created when timeout, load balancer, or body size annotations are present.
 	BackendTrafficPolicies []*egv1alpha1.BackendTrafficPolicy
@@ -25,4 +31,29 @@ type ConversionResult struct {
 	// BackendTLSPolicies are the generated BackendTLSPolicy resources (if any).
 	// One per unique backend service is created when backend-protocol: HTTPS annotation is present.
 	BackendTLSPolicies []*gatewayv1.BackendTLSPolicy
+
+	// Backends are Envoy Gateway Backends that replace HTTPS Service backends when
+	// proxy-ssl-verify is off. HTTPRoutes reference them instead of the Services.
+	Backends []*egv1alpha1.Backend
+
+	// HTTPRouteFilters are Envoy Gateway HTTPRouteFilters that HTTPRoute rules reference
+	// through ExtensionRef, for regex rewrite-target with capture groups. One per rule.
+	HTTPRouteFilters []*egv1alpha1.HTTPRouteFilter
+
+	// Certificates are cert-manager Certificates for the TLS Secrets of an Ingress with
+	// an issuer annotation. They are unstructured, as cert-manager may not be installed.
+	Certificates []*unstructured.Unstructured
+
+	// Listeners are the HTTPS listeners the shared Gateway needs for spec.tls.
+	// The controller merges these across all Ingresses before updating the Gateway, or
+	// across the Ingresses of each namespace into its XListenerSet in ListenerSet mode.
+	Listeners []gatewayv1.Listener
+
+	// ListenerPolicies are ClientTrafficPolicies in the Gateway namespace that target the
+	// generated Listeners by sectionName, e.g. for client certificate authentication.
+	// Like Listeners, the controller merges these across all Ingresses.
+	ListenerPolicies []*egv1alpha1.ClientTrafficPolicy
+
+	// Warnings describe parts of the Ingress that could not be converted faithfully.
+	Warnings []string
 }
@@ -0,0 +1,181 @@
+package converter
+
+import (
+	"fmt"
+
+	egv1alpha1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/werdnum/ingress-gateway-api/internal/annotations"
+	"github.com/werdnum/ingress-gateway-api/internal/config"
+)
+
+// upstreamTLS is the backend TLS configuration from the proxy-ssl-* annotations.
+type upstreamTLS struct {
+	// hostname is the SNI and validation hostname. Empty means the service FQDN.
+	hostname string
+
+	// caCertificateRefs are CA bundles in the Ingress namespace. Empty means the system CAs.
+	caCertificateRefs []gatewayv1.LocalObjectReference
+
+	// verify is false when backend certificates are not verified.
+	verify bool
+}
+
+// upstreamTLSSettings reads the proxy-ssl-* annotations. Only the ca.crt key of
+// proxy-ssl-secret is used: the client certificate in it is not presented to backends.
+// The Secret must be in the Ingress namespace, as BackendTLSPolicy can only reference
+// objects in its own namespace.
+func (c *Converter) upstreamTLSSettings(ingress *networkingv1.Ingress, annots annotations.AnnotationSet) (upstreamTLS, []string) {
+	var warnings []string
+	settings := upstreamTLS{verify: annots.VerifiesUpstreamTLS()}
+	settings.hostname, _ = annots.GetString(annotations.ProxySSLName)
+
+	if secret, ok := annots.GetString(annotations.ProxySSLSecret); ok {
+		namespace, name := splitNamespacedName(secret, ingress.Namespace)
+		if namespace != ingress.Namespace || name == "" {
+			warnings = append(warnings, fmt.Sprintf(
+				"proxy-ssl-secret %q must name a Secret in namespace %s; backends are verified against the system CAs",
+				secret, ingress.Namespace))
+		} else {
+			settings.caCertificateRefs = append(settings.caCertificateRefs, gatewayv1.LocalObjectReference{
+				Group: gatewayv1.Group(""),
+				Kind:  gatewayv1.Kind("Secret"),
+				Name:  gatewayv1.ObjectName(name),
+			})
+		}
+	}
+
+	if configMap, ok := annots.GetString(annotations.ProxySSLCAConfigMap); ok {
+		settings.caCertificateRefs = append(settings.caCertificateRefs, gatewayv1.LocalObjectReference{
+			Group: gatewayv1.Group(""),
+			Kind:  gatewayv1.Kind("ConfigMap"),
+			Name:  gatewayv1.ObjectName(configMap),
+		})
+	}
+
+	// Envoy always sends the validation hostname as SNI
+	if serverName, ok := annots.GetString(annotations.ProxySSLServerName); ok && serverName == "off" {
+		warnings = append(warnings, "proxy-ssl-server-name off is not supported, SNI is always sent to HTTPS backends")
+	}
+
+	if !settings.verify && len(settings.caCertificateRefs) > 0 {
+		warnings = append(warnings, "proxy-ssl-verify is off, the CA certificates from the proxy-ssl annotations are not used")
+	}
+
+	return settings, warnings
+}
+
+// upstreamTLSWarnings returns the warnings for the proxy-ssl-* annotations of an Ingress.
+func (c *Converter) upstreamTLSWarnings(ingress *networkingv1.Ingress, annots annotations.AnnotationSet) []string {
+	if !annots.HasUpstreamTLSAnnotations() {
+		return nil
+	}
+	if !annots.HasBackendTLSPolicy() {
+		return []string{"proxy-ssl annotations have no effect without backend-protocol HTTPS"}
+	}
+	_, warnings := c.upstreamTLSSettings(ingress, annots)
+	return warnings
+}
+
+// serviceFQDN returns the cluster DNS name of a Service.
+func (c *Converter) serviceFQDN(name, namespace string) string {
+	domain := c.cfg.ClusterDomain
+	if domain == "" {
+		domain = config.DefaultClusterDomain
+	}
+	return fmt.Sprintf("%s.%s.svc.%s", name, namespace, domain)
+}
+
+// backendTLSHostname returns the hostname a backend certificate is validated against.
+func (c *Converter) backendTLSHostname(settings upstreamTLS, service, namespace string) gatewayv1.PreciseHostname {
+	if settings.hostname != "" {
+		return gatewayv1.PreciseHostname(settings.hostname)
+	}
+	return gatewayv1.PreciseHostname(c.serviceFQDN(service, namespace))
+}
+
+// generateInsecureBackends handles proxy-ssl-verify: off, which BackendTLSPolicy cannot
+// express. Each HTTPS Service backend is replaced with an Envoy Gateway Backend that
+// reaches the Service by its FQDN and skips certificate verification. Backends are named
+// after the Ingress, so Ingresses sharing a Service each own theirs. This requires the
+// Backend API to be enabled in Envoy Gateway. Routes are rewritten in place.
+func (c *Converter) generateInsecureBackends(
+	ingress *networkingv1.Ingress,
+	httpRoutes []*gatewayv1.HTTPRoute,
+	annots annotations.AnnotationSet,
+) ([]*egv1alpha1.Backend, []string) {
+	if !annots.HasBackendTLSPolicy() || annots.VerifiesUpstreamTLS() {
+		return nil, nil
+	}
+
+	settings, _ := c.upstreamTLSSettings(ingress, annots)
+	var warnings []string
+	var backends []*egv1alpha1.Backend
+	seen := make(map[string]bool)
+
+	for _, httpRoute := range httpRoutes {
+		for i := range httpRoute.Spec.Rules {
+			for j := range httpRoute.Spec.Rules[i].BackendRefs {
+				ref := &httpRoute.Spec.Rules[i].BackendRefs[j].BackendObjectReference
+				if ref.Kind != nil && *ref.Kind != "Service" {
+					continue
+				}
+				if ref.Port == nil {
+					warnings = append(warnings, fmt.Sprintf(
+						"cannot skip certificate verification for service %s without a resolved port", ref.Name))
+					continue
+				}
+
+				name := fmt.Sprintf("%s-%s-%d-tls", ingress.Name, ref.Name, *ref.Port)
+				if !seen[name] {
+					seen[name] = true
+					backends = append(backends, c.createInsecureBackend(ingress, name, string(ref.Name), int32(*ref.Port), settings))
+				}
+
+				ref.Group = ptr(gatewayv1.Group(egv1alpha1.GroupName))
+				ref.Kind = ptr(gatewayv1.Kind(egv1alpha1.KindBackend))
+				ref.Name = gatewayv1.ObjectName(name)
+				ref.Namespace = nil
+			}
+		}
+	}
+
+	return backends, warnings
+}
+
+// createInsecureBackend creates a Backend for a Service port that connects with TLS
+// without verifying the certificate.
+func (c *Converter) createInsecureBackend(
+	ingress *networkingv1.Ingress,
+	name, service string,
+	port int32,
+	settings upstreamTLS,
+) *egv1alpha1.Backend {
+	return &egv1alpha1.Backend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ingress.Namespace,
+			Labels:    copyLabels(ingress.Labels),
+			Annotations: map[string]string{
+				"ingress-gateway-api.io/source": fmt.Sprintf("%s/%s", ingress.Namespace, ingress.Name),
+			},
+		},
+		Spec: egv1alpha1.BackendSpec{
+			Endpoints: []egv1alpha1.BackendEndpoint{
+				{
+					FQDN: &egv1alpha1.FQDNEndpoint{
+						Hostname: c.serviceFQDN(service, ingress.Namespace),
+						Port:     port,
+					},
+				},
+			},
+			TLS: &egv1alpha1.BackendTLSSettings{
+				InsecureSkipVerify: ptr(true),
+				SNI:                ptr(c.backendTLSHostname(settings, service, ingress.Namespace)),
+			},
+		},
+	}
+}
@@ -0,0 +1,240 @@
+package converter
+
+import (
+	"context"
+	"testing"
+
+	egv1alpha1 "github.com/envoyproxy/gateway/api/v1alpha1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/werdnum/ingress-gateway-api/internal/config"
+)
+
+func upstreamTLSIngress(annots map[string]string) *networkingv1.Ingress {
+	if annots == nil {
+		annots = map[string]string{}
+	}
+	annots["nginx.ingress.kubernetes.io/backend-protocol"] = "HTTPS"
+	return &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-ingress",
+			Namespace:   "default",
+			Annotations: annots,
+		},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{
+				{
+					Host: "example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{
+								{
+									Path:     "/",
+									PathType: ptr(networkingv1.PathTypePrefix),
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{
+											Name: "api",
+											Port: networkingv1.ServiceBackendPort{Number: 8443},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestConvertIngressFull_BackendTLSValidation(t *testing.T) {
+	tests := []struct {
+		name         string
+		annotations  map[string]string
+		wantHostname string
+		wantCARefs   []gatewayv1.LocalObjectReference
+		wantWarnings int
+	}{
+		{
+			name:         "defaults to the service FQDN and system CAs",
+			wantHostname: "api.default.svc.cluster.local",
+		},
+		{
+			name: "proxy-ssl-name and proxy-ssl-secret",
+			annotations: map[string]string{
+				"nginx.ingress.kubernetes.io/proxy-ssl-name":   "api.internal",
+				"nginx.ingress.kubernetes.io/proxy-ssl-secret": "default/internal-ca",
+			},
+			wantHostname: "api.internal",
+			wantCARefs: []gatewayv1.LocalObjectReference{
+				{Group: "", Kind: "Secret", Name: "internal-ca"},
+			},
+		},
+		{
+			name: "CA bundle from a ConfigMap",
+			annotations: map[string]string{
+				"ingress-gateway-api.io/proxy-ssl-ca-configmap": "internal-ca-bundle",
+			},
+			wantHostname: "api.default.svc.cluster.local",
+			wantCARefs: []gatewayv1.LocalObjectReference{
+				{Group: "", Kind: "ConfigMap", Name: "internal-ca-bundle"},
+			},
+		},
+		{
+			name: "proxy-ssl-secret in another namespace",
+			annotations: map[string]string{
+				"nginx.ingress.kubernetes.io/proxy-ssl-secret": "other/internal-ca",
+			},
+			wantHostname: "api.default.svc.cluster.local",
+			wantWarnings: 1,
+		},
+		{
+			name: "SNI cannot be turned off",
+			annotations: map[string]string{
+				"nginx.ingress.kubernetes.io/proxy-ssl-server-name": "off",
+			},
+			wantHostname: "api.default.svc.cluster.local",
+			wantWarnings: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(&config.Config{
+				GatewayName:      "eg-gateway",
+				GatewayNamespace: "envoy-gateway",
+			})
+
+			result := c.ConvertIngressFull(context.Background(), upstreamTLSIngress(tt.annotations))
+
+			if len(result.Warnings) != tt.wantWarnings {
+				t.Errorf("expected %d warnings, got %d: %v", tt.wantWarnings, len(result.Warnings), result.Warnings)
+			}
+			if len(result.Backends) != 0 {
+				t.Errorf("expected no Backends, got %d", len(result.Backends))
+			}
+			if len(result.BackendTLSPolicies) != 1 {
+				t.Fatalf("expected 1 BackendTLSPolicy, got %d", len(result.BackendTLSPolicies))
+			}
+
+			validation := result.BackendTLSPolicies[0].Spec.Validation
+			if string(validation.Hostname) != tt.wantHostname {
+				t.Errorf("expected hostname %s, got %s", tt.wantHostname, validation.Hostname)
+			}
+			if tt.wantCARefs == nil {
+				if validation.WellKnownCACertificates == nil || *validation.WellKnownCACertificates != gatewayv1.WellKnownCACertificatesSystem {
+					t.Errorf("expected system CAs, got %v", validation.WellKnownCACertificates)
+				}
+				if len(validation.CACertificateRefs) != 0 {
+					t.Errorf("expected no CA refs, got %+v", validation.CACertificateRefs)
+				}
+				return
+			}
+			if validation.WellKnownCACertificates != nil {
+				t.Errorf("expected no well-known CAs with CA refs, got %s", *validation.WellKnownCACertificates)
+			}
+			if len(validation.CACertificateRefs) != len(tt.wantCARefs) {
+				t.Fatalf("expected %d CA refs, got %+v", len(tt.wantCARefs), validation.CACertificateRefs)
+			}
+			for i, want := range tt.wantCARefs {
+				if validation.CACertificateRefs[i] != want {
+					t.Errorf("expected CA ref %+v, got %+v", want, validation.CACertificateRefs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestConvertIngressFull_BackendTLSClusterDomain(t *testing.T) {
+	c := New(&config.Config{
+		GatewayName:      "eg-gateway",
+		GatewayNamespace: "envoy-gateway",
+		ClusterDomain:    "corp.internal",
+	})
+
+	result := c.ConvertIngressFull(context.Background(), upstreamTLSIngress(nil))
+
+	if len(result.BackendTLSPolicies) != 1 {
+		t.Fatalf("expected 1 BackendTLSPolicy, got %d", len(result.BackendTLSPolicies))
+	}
+	if got := result.BackendTLSPolicies[0].Spec.Validation.Hostname; got != "api.default.svc.corp.internal" {
+		t.Errorf("expected hostname api.default.svc.corp.internal, got %s", got)
+	}
+}
+
+func TestConvertIngressFull_ProxySSLVerifyOff(t *testing.T) {
+	c := New(&config.Config{
+		GatewayName:      "eg-gateway",
+		GatewayNamespace: "envoy-gateway",
+	})
+
+	result := c.ConvertIngressFull(context.Background(), upstreamTLSIngress(map[string]string{
+		"nginx.ingress.kubernetes.io/proxy-ssl-verify": "off",
+		"nginx.ingress.kubernetes.io/proxy-ssl-name":   "api.internal",
+	}))
+
+	if len(result.Warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", result.Warnings)
+	}
+	if len(result.BackendTLSPolicies) != 0 {
+		t.Errorf("expected no BackendTLSPolicies, got %d", len(result.BackendTLSPolicies))
+	}
+	if len(result.Backends) != 1 {
+		t.Fatalf("expected 1 Backend, got %d", len(result.Backends))
+	}
+
+	backend := result.Backends[0]
+	if backend.Name != "test-ingress-api-8443-tls" || backend.Namespace != "default" {
+		t.Errorf("unexpected Backend %s/%s", backend.Namespace, backend.Name)
+	}
+	if len(backend.Spec.Endpoints) != 1 || backend.Spec.Endpoints[0].FQDN == nil {
+		t.Fatalf("expected one FQDN endpoint, got %+v", backend.Spec.Endpoints)
+	}
+	if fqdn := backend.Spec.Endpoints[0].FQDN; fqdn.Hostname != "api.default.svc.cluster.local" || fqdn.Port != 8443 {
+		t.Errorf("unexpected endpoint %s:%d", fqdn.Hostname, fqdn.Port)
+	}
+	if tls := backend.Spec.TLS; tls == nil || tls.InsecureSkipVerify == nil || !*tls.InsecureSkipVerify {
+		t.Errorf("expected insecureSkipVerify, got %+v", tls)
+	} else if tls.SNI == nil || *tls.SNI != "api.internal" {
+		t.Errorf("expected SNI api.internal, got %v", tls.SNI)
+	}
+
+	ref := result.HTTPRoutes[0].Spec.Rules[0].BackendRefs[0]
+	if ref.Group == nil || *ref.Group != egv1alpha1.GroupName || ref.Kind == nil || *ref.Kind != egv1alpha1.KindBackend {
+		t.Errorf("expected a Backend reference, got %+v", ref.BackendObjectReference)
+	}
+	if ref.Name != "test-ingress-api-8443-tls" {
+		t.Errorf("expected backendRef test-ingress-api-8443-tls, got %s", ref.Name)
+	}
+
+	// Another Ingress using the same Service gets its own Backend
+	other := upstreamTLSIngress(map[string]string{"nginx.ingress.kubernetes.io/proxy-ssl-verify": "off"})
+	other.Name = "other-ingress"
+	otherResult := c.ConvertIngressFull(context.Background(), other)
+	if len(otherResult.Backends) != 1 || otherResult.Backends[0].Name != "other-ingress-api-8443-tls" {
+		t.Errorf("expected Backend other-ingress-api-8443-tls, got %+v", otherResult.Backends)
+	}
+}
+
+func TestConvertIngressFull_ProxySSLWithoutHTTPSBackend(t *testing.T) {
+	c := New(&config.Config{
+		GatewayName:      "eg-gateway",
+		GatewayNamespace: "envoy-gateway",
+	})
+	ingress := upstreamTLSIngress(map[string]string{
+		"nginx.ingress.kubernetes.io/proxy-ssl-verify": "off",
+	})
+	delete(ingress.Annotations, "nginx.ingress.kubernetes.io/backend-protocol")
+
+	result := c.ConvertIngressFull(context.Background(), ingress)
+
+	if len(result.Warnings) != 1 {
+		t.Errorf("expected 1 warning, got %v", result.Warnings)
+	}
+	if len(result.Backends) != 0 || len(result.BackendTLSPolicies) != 0 {
+		t.Errorf("expected no upstream TLS resources, got %d Backends and %d BackendTLSPolicies",
+			len(result.Backends), len(result.BackendTLSPolicies))
+	}
+}